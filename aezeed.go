@@ -0,0 +1,63 @@
+package pool_party
+
+import (
+	"github.com/Pantani/errors"
+	"github.com/Pantani/pool-party/aezeed"
+	"github.com/Pantani/pool-party/bip44"
+)
+
+const (
+	// aezeedTemplate is the BIP84 receive path of the LND wallets
+	aezeedTemplate bip44.PathTemplate = "m/84'/{coin}'/{account}'/0/{index}"
+
+	// aezeedNestedTemplate is the BIP49 receive path of the LND wallets
+	aezeedNestedTemplate bip44.PathTemplate = "m/49'/{coin}'/{account}'/0/{index}"
+)
+
+// NewPoolWithAezeed creates the pool with a LND aezeed cipher seed and its passphrase (or empty
+// for the default passphrase), for the wallets migrated from LND. The pool generates the P2WPKH
+// addresses of the BIP84 path m/84'/cointype'/0'/0/i from the seed entropy, with the legacy key
+// derivation of the LND wallet (see bip32.NewLegacyChildKey).
+// It returns the pool and an error if occurs
+func NewPoolWithAezeed(coin bip44.Coin, mnemonic, passphrase string) (*Pool, error) {
+	pool, err := newPoolWithAezeed(coin, mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	pool.template = aezeedTemplate
+	pool.segwit = true
+	return pool, nil
+}
+
+// NewPoolWithAezeedNested is like NewPoolWithAezeed, but the pool generates the nested segwit
+// (P2SH-P2WPKH) addresses of the BIP49 path m/49'/cointype'/0'/0/i, the other receive
+// addresses of the LND wallets.
+// It returns the pool and an error if occurs
+func NewPoolWithAezeedNested(coin bip44.Coin, mnemonic, passphrase string) (*Pool, error) {
+	pool, err := newPoolWithAezeed(coin, mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	pool.template = aezeedNestedTemplate
+	pool.nested = true
+	return pool, nil
+}
+
+// newPoolWithAezeed deciphers the aezeed cipher seed and creates the legacy pool of its entropy
+// It returns the pool and an error if occurs
+func newPoolWithAezeed(coin bip44.Coin, mnemonic, passphrase string) (*Pool, error) {
+	altcoin, ok := bip44.CoinList[coin]
+	if !ok || !altcoin.SupportsSegwit() {
+		return nil, errors.E("aezeed seeds are only supported for segwit coins", errors.Params{"coin": coin})
+	}
+	cipherSeed, err := aezeed.Decipher(mnemonic, []byte(passphrase))
+	if err != nil {
+		return nil, errors.E(err, "invalid aezeed seed")
+	}
+	defer cipherSeed.Wipe()
+	return &Pool{
+		coin:      coin,
+		seedBytes: append([]byte{}, cipherSeed.Entropy[:]...),
+		legacy:    true,
+	}, nil
+}
@@ -0,0 +1,231 @@
+// Package aezeed implements the cipher seed of the LND wallets (aezeed), a 24 words mnemonic of
+// the BIP39 english word list with the wallet entropy, birthday and version enciphered by AEZ
+// with a passphrase. The 16 bytes of entropy are the BIP32 seed of the wallet.
+// The AEZ cipher is the implementation used by LND, github.com/Yawning/aez.
+//
+// The aezeed spec can be found at
+// https://github.com/lightningnetwork/lnd/tree/master/aezeed
+package aezeed
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Yawning/aez"
+	"github.com/tyler-smith/go-bip39/wordlists"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// CipherSeedVersion is the external version of the cipher seeds, with the scrypt parameters
+	CipherSeedVersion = uint8(0)
+
+	// EntropySize is the size of the wallet entropy, the BIP32 seed
+	EntropySize = 16
+
+	// NumMnemonicWords is the number of words of the mnemonic
+	NumMnemonicWords = 24
+
+	// DefaultPassphrase is the passphrase of the seeds created without passphrase
+	DefaultPassphrase = "aezeed"
+
+	// sizes of the enciphered seed, version || ciphertext || salt || checksum
+	saltSize            = 5
+	checksumSize        = 4
+	cipherTextExpansion = 4
+	decipheredSize      = 1 + 2 + EntropySize
+	encipheredSize      = 1 + decipheredSize + cipherTextExpansion + saltSize + checksumSize
+
+	// the scrypt parameters of the version 0, with scryptN
+	scryptR = 8
+	scryptP = 1
+	keyLen  = 32
+
+	bitsPerWord = 11
+)
+
+var (
+	// scryptN is the scrypt cost of the version 0, a variable so the tests can use the
+	// cost of the lnd test vectors
+	scryptN = 32768
+
+	// BitcoinGenesisDate is the birthday 0 of the seeds, the wallet birthday is the
+	// number of days since it
+	BitcoinGenesisDate = time.Unix(1231006505, 0)
+
+	// ErrIncorrectVersion is returned when the seed has an unknown external version
+	ErrIncorrectVersion = errors.New("unknown cipher seed version")
+
+	// ErrInvalidMnemonic is returned when the mnemonic has invalid words or checksum
+	ErrInvalidMnemonic = errors.New("invalid aezeed mnemonic")
+
+	// ErrInvalidPassphrase is returned when the seed can't be deciphered with the passphrase
+	ErrInvalidPassphrase = errors.New("invalid aezeed passphrase")
+
+	// crcTable is the CRC-32C table of the checksum
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// CipherSeed is the deciphered content of the aezeed mnemonic
+type CipherSeed struct {
+	InternalVersion uint8
+	Birthday        uint16 // days since BitcoinGenesisDate
+	Entropy         [EntropySize]byte
+	salt            [saltSize]byte
+}
+
+// New creates the cipher seed with the entropy and salt read from the reader, like crypto/rand.Reader,
+// and the birthday of the time
+// It returns the cipher seed and an error if occurs
+func New(random io.Reader, internalVersion uint8, now time.Time) (*CipherSeed, error) {
+	seed := &CipherSeed{
+		InternalVersion: internalVersion,
+		Birthday:        uint16(now.Sub(BitcoinGenesisDate) / (24 * time.Hour)),
+	}
+	if _, err := io.ReadFull(random, seed.Entropy[:]); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(random, seed.salt[:]); err != nil {
+		seed.Wipe()
+		return nil, err
+	}
+	return seed, nil
+}
+
+// Decipher decodes the mnemonic and deciphers the seed with the passphrase,
+// DefaultPassphrase if it's empty
+// It returns the cipher seed and an error if occurs
+func Decipher(mnemonic string, passphrase []byte) (*CipherSeed, error) {
+	enciphered, err := mnemonicToBytes(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	if enciphered[0] != CipherSeedVersion {
+		return nil, fmt.Errorf("%w: %d", ErrIncorrectVersion, enciphered[0])
+	}
+	checksum := binary.BigEndian.Uint32(enciphered[encipheredSize-checksumSize:])
+	if crc32.Checksum(enciphered[:encipheredSize-checksumSize], crcTable) != checksum {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidMnemonic)
+	}
+
+	seed := &CipherSeed{}
+	copy(seed.salt[:], enciphered[encipheredSize-checksumSize-saltSize:])
+	key, err := deriveKey(passphrase, seed.salt)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(key)
+	plaintext, ok := aez.Decrypt(key, nil, [][]byte{seed.ad(enciphered[0])}, cipherTextExpansion, enciphered[1:encipheredSize-checksumSize-saltSize], nil)
+	if !ok || len(plaintext) != decipheredSize {
+		return nil, ErrInvalidPassphrase
+	}
+	seed.InternalVersion = plaintext[0]
+	seed.Birthday = binary.BigEndian.Uint16(plaintext[1:3])
+	copy(seed.Entropy[:], plaintext[3:])
+	wipe(plaintext)
+	return seed, nil
+}
+
+// Mnemonic enciphers the seed with the passphrase, DefaultPassphrase if it's empty
+// It returns the 24 words mnemonic and an error if occurs
+func (c *CipherSeed) Mnemonic(passphrase []byte) (string, error) {
+	key, err := deriveKey(passphrase, c.salt)
+	if err != nil {
+		return "", err
+	}
+	defer wipe(key)
+	plaintext := make([]byte, 0, decipheredSize)
+	plaintext = append(plaintext, c.InternalVersion, byte(c.Birthday>>8), byte(c.Birthday))
+	plaintext = append(plaintext, c.Entropy[:]...)
+	defer wipe(plaintext)
+	ciphertext := aez.Encrypt(key, nil, [][]byte{c.ad(CipherSeedVersion)}, cipherTextExpansion, plaintext, nil)
+
+	enciphered := make([]byte, 0, encipheredSize)
+	enciphered = append(enciphered, CipherSeedVersion)
+	enciphered = append(enciphered, ciphertext...)
+	enciphered = append(enciphered, c.salt[:]...)
+	var checksum [checksumSize]byte
+	binary.BigEndian.PutUint32(checksum[:], crc32.Checksum(enciphered, crcTable))
+	enciphered = append(enciphered, checksum[:]...)
+
+	words := make([]string, NumMnemonicWords)
+	for i := range words {
+		words[i] = wordlists.English[readBits(enciphered, i*bitsPerWord, bitsPerWord)]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// BirthdayTime returns the wallet birthday, the date to rescan the chain from
+func (c *CipherSeed) BirthdayTime() time.Time {
+	return BitcoinGenesisDate.Add(time.Duration(c.Birthday) * 24 * time.Hour)
+}
+
+// Wipe zeroes the seed entropy
+func (c *CipherSeed) Wipe() {
+	wipe(c.Entropy[:])
+}
+
+// ad returns the associated data of the cipher, the external version and the salt
+func (c *CipherSeed) ad(version uint8) []byte {
+	return append([]byte{version}, c.salt[:]...)
+}
+
+// deriveKey derives the AEZ key of the passphrase and salt by scrypt
+func deriveKey(passphrase []byte, salt [saltSize]byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		passphrase = []byte(DefaultPassphrase)
+	}
+	return scrypt.Key(passphrase, salt[:], scryptN, scryptR, scryptP, keyLen)
+}
+
+// wipe zeroes the buffer
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// mnemonicToBytes decodes the 24 words of 11 bits to the 33 bytes of the enciphered seed
+func mnemonicToBytes(mnemonic string) ([]byte, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	if len(words) != NumMnemonicWords {
+		return nil, fmt.Errorf("%w: %d words", ErrInvalidMnemonic, len(words))
+	}
+	enciphered := make([]byte, encipheredSize)
+	for i, word := range words {
+		index, ok := wordIndex[word]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown word %q", ErrInvalidMnemonic, word)
+		}
+		for bit := 0; bit < bitsPerWord; bit++ {
+			if index&(1<<uint(bitsPerWord-1-bit)) != 0 {
+				offset := i*bitsPerWord + bit
+				enciphered[offset/8] |= 1 << uint(7-offset%8)
+			}
+		}
+	}
+	return enciphered, nil
+}
+
+// readBits reads count bits from the bit offset of the data, most significant first
+func readBits(data []byte, offset, count int) int {
+	value := 0
+	for bit := offset; bit < offset+count; bit++ {
+		value = value<<1 | int(data[bit/8]>>uint(7-bit%8)&1)
+	}
+	return value
+}
+
+// wordIndex maps the english words to their index
+var wordIndex = func() map[string]int {
+	index := make(map[string]int, len(wordlists.English))
+	for i, word := range wordlists.English {
+		index[word] = i
+	}
+	return index
+}()
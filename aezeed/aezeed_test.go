@@ -0,0 +1,135 @@
+package aezeed
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	// the lnd test vectors are enciphered with the scrypt cost 16
+	scryptN = 16
+}
+
+// the entropy, salt and mnemonics of the lnd aezeed package tests
+var (
+	testEntropy = []byte{
+		0x81, 0xb6, 0x37, 0xd8, 0x63, 0x59, 0xe6, 0x96,
+		0x0d, 0xe7, 0x95, 0xe4, 0x1e, 0x0b, 0x4c, 0xfd,
+	}
+	testSalt = []byte("salt1")
+
+	testVectors = []struct {
+		time       time.Time
+		passphrase []byte
+		mnemonic   string
+		birthday   uint16
+	}{
+		{
+			time:       BitcoinGenesisDate,
+			passphrase: []byte{},
+			mnemonic:   "ability liquid travel stem barely drastic pact cupboard apple thrive morning oak feature tissue couch old math inform success suggest drink motion know royal",
+			birthday:   0,
+		},
+		{
+			time:       time.Unix(1521799345, 0),
+			passphrase: []byte("!very_safe_55345_password*"),
+			mnemonic:   "able tree stool crush transfer cloud cross three profit outside hen citizen plate ride require leg siren drum success suggest drink require fiscal upgrade",
+			birthday:   3365,
+		},
+	}
+)
+
+func newTestSeed(t *testing.T, now time.Time) *CipherSeed {
+	seed, err := New(bytes.NewReader(append(append([]byte{}, testEntropy...), testSalt...)), 0, now)
+	assert.NoError(t, err)
+	return seed
+}
+
+func TestCipherSeed_Mnemonic(t *testing.T) {
+	for _, tt := range testVectors {
+		seed := newTestSeed(t, tt.time)
+		assert.Equal(t, tt.birthday, seed.Birthday)
+		mnemonic, err := seed.Mnemonic(tt.passphrase)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.mnemonic, mnemonic)
+
+		got, err := Decipher(tt.mnemonic, tt.passphrase)
+		assert.NoError(t, err)
+		assert.Equal(t, seed, got)
+		assert.Equal(t, testEntropy, got.Entropy[:])
+	}
+}
+
+func TestDecipher_ScryptN(t *testing.T) {
+	// enciphered by lnd with the scrypt cost of the version 0 and a random salt
+	tests := []struct {
+		passphrase []byte
+		mnemonic   string
+	}{
+		{
+			passphrase: []byte("!very_safe_55345_password*"),
+			mnemonic:   "ability party rookie shell session glare excite grief patient volume unaware ozone arrow vital exercise inhale upper knife foster main wash rebel kit maid",
+		},
+		{
+			passphrase: nil,
+			mnemonic:   "abstract group option account across two mistake shadow tone half blast owner now junior mobile outdoor impact brick foster main wash siren lunch orbit",
+		},
+	}
+	defer func(n int) { scryptN = n }(scryptN)
+	scryptN = 32768
+	for _, tt := range tests {
+		seed, err := Decipher(tt.mnemonic, tt.passphrase)
+		assert.NoError(t, err)
+		assert.Equal(t, uint8(0), seed.InternalVersion)
+		assert.Equal(t, uint16(3365), seed.Birthday)
+		assert.Equal(t, testEntropy, seed.Entropy[:])
+
+		// enciphered again with the same salt
+		mnemonic, err := seed.Mnemonic(tt.passphrase)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.mnemonic, mnemonic)
+	}
+}
+
+func TestMnemonicToBytes(t *testing.T) {
+	for _, tt := range testVectors {
+		enciphered, err := mnemonicToBytes(tt.mnemonic)
+		assert.NoError(t, err)
+		assert.Equal(t, CipherSeedVersion, enciphered[0])
+		assert.Equal(t, testSalt, enciphered[encipheredSize-checksumSize-saltSize:encipheredSize-checksumSize])
+		checksum := crc32.Checksum(enciphered[:encipheredSize-checksumSize], crcTable)
+		assert.Equal(t, checksum, binary.BigEndian.Uint32(enciphered[encipheredSize-checksumSize:]))
+	}
+}
+
+func TestCipherSeed_BirthdayTime(t *testing.T) {
+	assert.Equal(t, BitcoinGenesisDate.Add(3365*24*time.Hour), (&CipherSeed{Birthday: 3365}).BirthdayTime())
+}
+
+func TestDecipher_Invalid(t *testing.T) {
+	mnemonic, err := newTestSeed(t, testVectors[1].time).Mnemonic(testVectors[1].passphrase)
+	assert.NoError(t, err)
+	_, err = Decipher(mnemonic, []byte("wrong"))
+	assert.ErrorIs(t, err, ErrInvalidPassphrase)
+
+	// the checksum doesn't match
+	words := strings.Fields(testVectors[0].mnemonic)
+	words[5] = "ability"
+	_, err = Decipher(strings.Join(words, " "), nil)
+	assert.ErrorIs(t, err, ErrInvalidMnemonic)
+
+	_, err = Decipher(strings.Join(words[:12], " "), nil)
+	assert.ErrorIs(t, err, ErrInvalidMnemonic)
+	_, err = Decipher(strings.Replace(testVectors[0].mnemonic, "ability", "bitcoin", 1), nil)
+	assert.ErrorIs(t, err, ErrInvalidMnemonic)
+
+	// the first word sets the external version
+	_, err = Decipher(strings.Replace(testVectors[0].mnemonic, "ability", "zoo", 1), nil)
+	assert.ErrorIs(t, err, ErrIncorrectVersion)
+}
@@ -0,0 +1,130 @@
+package pool_party
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/Pantani/pool-party/descriptor"
+	"github.com/stretchr/testify/assert"
+)
+
+// the aezeed seed enciphered by lnd, its entropy has keys with leading zero bytes in the
+// BIP84 and BIP49 paths, and the receive addresses of the lnd wallet (btcwallet derives
+// the keys by hdkeychain DeriveNonStandard)
+const (
+	testAezeedMnemonic   = "about rib slow auto stand grow light uniform vendor quality burger tragic sunny episode admit behave churn differ exchange always fancy promote horse regular"
+	testAezeedPassphrase = "!very_safe_55345_password*"
+	testAezeedEntropy    = "706f6f6c2d70617274792d6c6e641764"
+)
+
+func TestNewPoolWithAezeed(t *testing.T) {
+	pool, err := NewPoolWithAezeed(bip44.Bitcoin, testAezeedMnemonic, testAezeedPassphrase)
+	assert.NoError(t, err)
+	addresses, err := pool.GenerateAddressPool(0, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"bc1q0h8p8r53hjna0gfngpgx7jsuelmjhrvzxkzjkf",
+		"bc1qqhjunkk2jq23xjfruk4q0dp23a3na8jxqp48cq",
+		"bc1qr959c4a9cesrhrnrjp6epwzrqngj2lwhfa4tay",
+	}, addressList(addresses))
+
+	// the bip32 derivation of the entropy differs
+	entropy, err := hex.DecodeString(testAezeedEntropy)
+	assert.NoError(t, err)
+	seedPool, err := NewPoolWithSeed(bip44.Bitcoin, entropy)
+	assert.NoError(t, err)
+	standard, err := seedPool.GenerateAddressPoolWithPath(string(aezeedTemplate), 0, 0, 1)
+	assert.NoError(t, err)
+	standard, err = standard.Segwit(bip44.Bitcoin)
+	assert.NoError(t, err)
+	assert.Equal(t, "bc1qnuxaqj7805g6ms5grtvz3n02s88ydtgcg9ry5g", standard[0].Address)
+	hasLegacy, err := pool.HasLegacyAddresses(0, 3)
+	assert.NoError(t, err)
+	assert.False(t, hasLegacy)
+
+	_, err = NewPoolWithAezeed(bip44.Bitcoin, testAezeedMnemonic, "wrong")
+	assert.Error(t, err)
+	_, err = NewPoolWithAezeed(bip44.Dogecoin, testAezeedMnemonic, testAezeedPassphrase)
+	assert.Error(t, err)
+	_, err = NewPoolWithAezeed(bip44.Bitcoin, abandonMnemonic, "")
+	assert.Error(t, err)
+}
+
+func TestNewPoolWithAezeedNested(t *testing.T) {
+	pool, err := NewPoolWithAezeedNested(bip44.Bitcoin, testAezeedMnemonic, testAezeedPassphrase)
+	assert.NoError(t, err)
+	addresses, err := pool.GenerateAddressPool(0, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"3BSTAK97Nu6BZy5RKx1HsfsvocKfrwKB4b",
+		"3L8Cnhnkxa9E1p6DxE3ZFToQY3tPNn171P",
+		"3Hjk9BriQmnfr85QoQoBRzUVsss7xNU7p3",
+	}, addressList(addresses))
+
+	// the signatures of the nested segwit addresses are verified
+	signature, err := pool.SignMessage(1, []byte("pool party"))
+	assert.NoError(t, err)
+	ok, err := pool.VerifyMessage(addresses[1].Address, []byte("pool party"), signature)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = pool.VerifyMessage(addresses[0].Address, []byte("pool party"), signature)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = pool.Descriptor(descriptor.PKH, 0, false)
+	assert.Error(t, err)
+	_, err = NewPoolWithAezeedNested(bip44.Bitcoin, testAezeedMnemonic, "wrong")
+	assert.Error(t, err)
+}
+
+func TestPoolAezeedKeystoreAndDescriptor(t *testing.T) {
+	for _, newPool := range []func(bip44.Coin, string, string) (*Pool, error){NewPoolWithAezeed, NewPoolWithAezeedNested} {
+		pool, err := newPool(bip44.Bitcoin, testAezeedMnemonic, testAezeedPassphrase)
+		assert.NoError(t, err)
+		want, err := pool.GenerateAddressPool(0, 3)
+		assert.NoError(t, err)
+
+		data, err := pool.Encrypt(testPassword, LightScryptN, LightScryptP)
+		assert.NoError(t, err)
+		decrypted, err := DecryptPool(data, testPassword)
+		assert.NoError(t, err)
+		got, err := decrypted.GenerateAddressPool(0, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	// the account key of the descriptor is derived like the lnd wallet
+	pool, err := NewPoolWithAezeed(bip44.Bitcoin, testAezeedMnemonic, testAezeedPassphrase)
+	assert.NoError(t, err)
+	want, err := pool.GenerateAddressPool(0, 3)
+	assert.NoError(t, err)
+	desc, err := pool.Descriptor(descriptor.WPKH, 0, false)
+	assert.NoError(t, err)
+	watchOnly, err := NewWatchOnlyPool(bip44.Bitcoin, desc)
+	assert.NoError(t, err)
+	got, err := watchOnly.GenerateAddressPool(0, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, addressList(want), addressList(got))
+}
+
+func TestPool_GenerateMultiCoinAddressPoolAezeed(t *testing.T) {
+	pool, err := NewPoolWithAezeedNested(bip44.Bitcoin, testAezeedMnemonic, testAezeedPassphrase)
+	assert.NoError(t, err)
+	want, err := pool.GenerateAddressPool(0, 2)
+	assert.NoError(t, err)
+	result, err := pool.GenerateMultiCoinAddressPool(nil, 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, want, result[bip44.Bitcoin])
+	_, err = pool.GenerateMultiCoinAddressPool([]bip44.Coin{bip44.Dogecoin}, 0, 2)
+	assert.Error(t, err)
+}
+
+// addressList returns the addresses strings
+func addressList(addresses bip44.Addresses) []string {
+	result := make([]string, 0, len(addresses))
+	for _, addr := range addresses {
+		result = append(result, addr.Address)
+	}
+	return result
+}
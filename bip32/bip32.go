@@ -24,6 +24,12 @@ var (
 	// PublicWalletVersion is the version flag for serialized private keys
 	PublicWalletVersion, _ = hex.DecodeString("0488B21E")
 
+	// TestnetPrivateWalletVersion is the version flag for serialized testnet private keys
+	TestnetPrivateWalletVersion, _ = hex.DecodeString("04358394")
+
+	// TestnetPublicWalletVersion is the version flag for serialized testnet public keys
+	TestnetPublicWalletVersion, _ = hex.DecodeString("043587CF")
+
 	// ErrSerializedKeyWrongSize is returned when trying to deserialize a key that
 	// has an incorrect length
 	ErrSerializedKeyWrongSize = errors.New("serialized keys should by exactly 82 bytes")
@@ -41,6 +47,21 @@ var (
 
 	// ErrInvalidPublicKey is returned when a derived public key is invalid
 	ErrInvalidPublicKey = errors.New("invalid public key")
+
+	// ErrUnknownVersion is returned when deserializing a key with unknown version bytes
+	ErrUnknownVersion = errors.New("unknown extended key version")
+
+	// ErrVersionKeyMismatch is returned when deserializing a private key with a public
+	// version or a public key with a private version
+	ErrVersionKeyMismatch = errors.New("extended key version doesn't match the key type")
+
+	// ErrZeroDepthParentFingerprint is returned when deserializing a master key (zero
+	// depth) with a non-zero parent fingerprint
+	ErrZeroDepthParentFingerprint = errors.New("zero depth key with non-zero parent fingerprint")
+
+	// ErrZeroDepthChildNumber is returned when deserializing a master key (zero depth)
+	// with a non-zero child number
+	ErrZeroDepthChildNumber = errors.New("zero depth key with non-zero child number")
 )
 
 // Key represents a bip32 extended key
@@ -90,12 +111,38 @@ func NewMasterKey(seed []byte) (*Key, error) {
 
 // NewChildKey derives a child key from a given parent as outlined by bip32
 func (key *Key) NewChildKey(childIdx uint32) (*Key, error) {
+	return key.newChildKey(childIdx, false)
+}
+
+// NewLegacyChildKey derives a child key like hdkeychain of btcutil v1.0.2, which
+// drops the leading zero bytes of a derived private key (depth > 0) when deriving its
+// hardened children. The keys are not bip32 compliant, it's only meant to recover
+// the addresses of wallets derived with it.
+func (key *Key) NewLegacyChildKey(childIdx uint32) (*Key, error) {
+	return key.newChildKey(childIdx, true)
+}
+
+func (key *Key) newChildKey(childIdx uint32, legacy bool) (*Key, error) {
 	// Fail early if trying to create hardned child from public key
 	if !key.IsPrivate && childIdx >= FirstHardenedChild {
 		return nil, ErrHardnedChildPublicKey
 	}
 
-	intermediary, err := key.getIntermediary(childIdx)
+	// the public key of a private parent, for the fingerprint and the non hardened children
+	var publicKey []byte
+	if key.IsPrivate {
+		publicKey = publicKeyForPrivateKey(key.Key)
+	}
+
+	intermediary, err := key.getIntermediary(childIdx, legacy, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	// the left half is the tweak of the child key, the right half is its chain code
+	defer zero(intermediary[:32])
+
+	// The child key is invalid if the left 32 bytes of the intermediary are not a valid scalar
+	err = validatePrivateKey(intermediary[:32])
 	if err != nil {
 		return nil, err
 	}
@@ -111,7 +158,7 @@ func (key *Key) NewChildKey(childIdx uint32) (*Key, error) {
 	// Bip32 CKDpriv
 	if key.IsPrivate {
 		childKey.Version = PrivateWalletVersion
-		fingerprint, err := hash160(publicKeyForPrivateKey(key.Key))
+		fingerprint, err := hash160(publicKey)
 		if err != nil {
 			return nil, err
 		}
@@ -125,27 +172,28 @@ func (key *Key) NewChildKey(childIdx uint32) (*Key, error) {
 		}
 		// Bip32 CKDpub
 	} else {
-		keyBytes := publicKeyForPrivateKey(intermediary[:32])
+		// the intermediary of a public parent can be computed from the extended public
+		// key, so its multiplication doesn't need to be constant time
+		keyBytes := publicKeyForPublicScalar(intermediary[:32])
 
-		// Validate key
-		err := validateChildPublicKey(keyBytes)
+		childKey.Version = PublicWalletVersion
+		fingerprint, err := hash160(key.Key)
 		if err != nil {
 			return nil, err
 		}
+		childKey.FingerPrint = fingerprint[:4]
 
-		childKey.Version = PublicWalletVersion
-		fingerprint, err := hash160(key.Key)
+		// Validate key, the sum is invalid if it's the point at infinity
+		childKey.Key, err = addPublicKeys(keyBytes, key.Key)
 		if err != nil {
 			return nil, err
 		}
-		childKey.FingerPrint = fingerprint[:4]
-		childKey.Key = addPublicKeys(keyBytes, key.Key)
 	}
 
 	return childKey, nil
 }
 
-func (key *Key) getIntermediary(childIdx uint32) ([]byte, error) {
+func (key *Key) getIntermediary(childIdx uint32, legacy bool, publicKey []byte) ([]byte, error) {
 	// Get intermediary to create key and chaincode from
 	// Hardened children are based on the private key
 	// NonHardened children are based on the public key
@@ -154,14 +202,25 @@ func (key *Key) getIntermediary(childIdx uint32) ([]byte, error) {
 	var data []byte
 	if childIdx >= FirstHardenedChild {
 		data = append([]byte{0x0}, key.Key...)
+		if legacy && key.Depth > 0 {
+			// hdkeychain copied the key without its leading zeros at the start
+			// of the 32 bytes, leaving the zero padding at the end
+			trimmed := bytes.TrimLeft(key.Key, "\x00")
+			copy(data[1:], trimmed)
+			for i := 1 + len(trimmed); i < len(data); i++ {
+				data[i] = 0
+			}
+		}
 	} else {
 		if key.IsPrivate {
-			data = publicKeyForPrivateKey(key.Key)
+			data = append([]byte{}, publicKey...)
 		} else {
-			data = key.Key
+			data = append([]byte{}, key.Key...)
 		}
 	}
 	data = append(data, childIndexBytes...)
+	// the hardened data has a copy of the private key
+	defer zero(data)
 
 	h := hmac.New(sha512.New, key.ChainCode)
 	_, err := h.Write(data)
@@ -174,10 +233,11 @@ func (key *Key) getIntermediary(childIdx uint32) ([]byte, error) {
 // PublicKey returns the public version of key or return a copy
 // The 'Neuter' function from the bip32 spec
 func (key *Key) PublicKey() *Key {
-	keyBytes := key.Key
-
+	var keyBytes []byte
 	if key.IsPrivate {
-		keyBytes = publicKeyForPrivateKey(keyBytes)
+		keyBytes = publicKeyForPrivateKey(key.Key)
+	} else {
+		keyBytes = append([]byte{}, key.Key...)
 	}
 
 	return &Key{
@@ -186,11 +246,34 @@ func (key *Key) PublicKey() *Key {
 		Depth:       key.Depth,
 		ChildNumber: key.ChildNumber,
 		FingerPrint: key.FingerPrint,
-		ChainCode:   key.ChainCode,
+		ChainCode:   append([]byte{}, key.ChainCode...),
 		IsPrivate:   false,
 	}
 }
 
+// Copy returns a copy of the key that doesn't share its buffers, so both can be wiped
+func (key *Key) Copy() *Key {
+	return &Key{
+		Version:     append([]byte{}, key.Version...),
+		Key:         append([]byte{}, key.Key...),
+		Depth:       key.Depth,
+		ChildNumber: append([]byte{}, key.ChildNumber...),
+		FingerPrint: append([]byte{}, key.FingerPrint...),
+		ChainCode:   append([]byte{}, key.ChainCode...),
+		IsPrivate:   key.IsPrivate,
+	}
+}
+
+// Wipe zeroes the key and chain code buffers, the key can't be used after
+func (key *Key) Wipe() {
+	for i := range key.Key {
+		key.Key[i] = 0
+	}
+	for i := range key.ChainCode {
+		key.ChainCode[i] = 0
+	}
+}
+
 // Serialize a Key to a 78 byte byte slice
 func (key *Key) Serialize() ([]byte, error) {
 	// Private keys should be prepended with a single null byte
@@ -232,25 +315,12 @@ func (key *Key) String() string {
 	return key.B58Serialize()
 }
 
-// Deserialize a byte slice into a Key
+// Deserialize a byte slice into a Key, validating the checksum, the version and
+// the key as defined in the bip32 spec
 func Deserialize(data []byte) (*Key, error) {
 	if len(data) != 82 {
 		return nil, ErrSerializedKeyWrongSize
 	}
-	var key = &Key{}
-	key.Version = data[0:4]
-	key.Depth = data[4]
-	key.FingerPrint = data[5:9]
-	key.ChildNumber = data[9:13]
-	key.ChainCode = data[13:45]
-
-	if data[45] == byte(0) {
-		key.IsPrivate = true
-		key.Key = data[46:78]
-	} else {
-		key.IsPrivate = false
-		key.Key = data[45:78]
-	}
 
 	// validate checksum
 	cs1, err := checksum(data[0 : len(data)-4])
@@ -264,6 +334,49 @@ func Deserialize(data []byte) (*Key, error) {
 			return nil, ErrInvalidChecksum
 		}
 	}
+
+	var key = &Key{}
+	key.Version = data[0:4]
+	key.Depth = data[4]
+	key.FingerPrint = data[5:9]
+	key.ChildNumber = data[9:13]
+	key.ChainCode = data[13:45]
+
+	// the master key has no parent
+	if key.Depth == 0 {
+		if !bytes.Equal(key.FingerPrint, []byte{0x00, 0x00, 0x00, 0x00}) {
+			return nil, ErrZeroDepthParentFingerprint
+		}
+		if !bytes.Equal(key.ChildNumber, []byte{0x00, 0x00, 0x00, 0x00}) {
+			return nil, ErrZeroDepthChildNumber
+		}
+	}
+
+	switch {
+	case bytes.Equal(key.Version, PrivateWalletVersion), bytes.Equal(key.Version, TestnetPrivateWalletVersion):
+		key.IsPrivate = true
+	case bytes.Equal(key.Version, PublicWalletVersion), bytes.Equal(key.Version, TestnetPublicWalletVersion):
+		key.IsPrivate = false
+	default:
+		return nil, ErrUnknownVersion
+	}
+
+	// private keys are prepended with a null byte, public keys have the 0x02 or 0x03 prefix
+	prefix := data[45]
+	switch {
+	case prefix == 0x00 && !key.IsPrivate, (prefix == 0x02 || prefix == 0x03) && key.IsPrivate:
+		return nil, ErrVersionKeyMismatch
+	case key.IsPrivate:
+		key.Key = data[46:78]
+		if prefix != 0x00 || validatePrivateKey(key.Key) != nil {
+			return nil, ErrInvalidPrivateKey
+		}
+	default:
+		key.Key = data[45:78]
+		if err := validatePublicKey(key.Key); err != nil {
+			return nil, err
+		}
+	}
 	return key, nil
 }
 
@@ -2,8 +2,11 @@ package bip32
 
 import (
 	"encoding/hex"
+	"math/rand"
 	"testing"
 
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/hdkeychain"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -217,6 +220,46 @@ func TestDeserializingInvalidStrings(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+// TestBip32TestVector5 checks the invalid extended keys of the bip32 test vector 5
+func TestBip32TestVector5(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		base58 string
+	}{
+		{"pubkey version / prvkey mismatch", ErrVersionKeyMismatch, "xpub661MyMwAqRbcEYS8w7XLSVeEsBXy79zSzH1J8vCdxAZningWLdN3zgtU6LBpB85b3D2yc8sfvZU521AAwdZafEz7mnzBBsz4wKY5fTtTQBm"},
+		{"prvkey version / pubkey mismatch", ErrVersionKeyMismatch, "xprv9s21ZrQH143K24Mfq5zL5MhWK9hUhhGbd45hLXo2Pq2oqzMMo63oStZzFGTQQD3dC4H2D5GBj7vWvSQaaBv5cxi9gafk7NF3pnBju6dwKvH"},
+		{"invalid pubkey prefix 04", ErrInvalidPublicKey, "xpub661MyMwAqRbcEYS8w7XLSVeEsBXy79zSzH1J8vCdxAZningWLdN3zgtU6Txnt3siSujt9RCVYsx4qHZGc62TG4McvMGcAUjeuwZdduYEvFn"},
+		{"invalid prvkey prefix 04", ErrInvalidPrivateKey, "xprv9s21ZrQH143K24Mfq5zL5MhWK9hUhhGbd45hLXo2Pq2oqzMMo63oStZzFGpWnsj83BHtEy5Zt8CcDr1UiRXuWCmTQLxEK9vbz5gPstX92JQ"},
+		{"invalid pubkey prefix 01", ErrInvalidPublicKey, "xpub661MyMwAqRbcEYS8w7XLSVeEsBXy79zSzH1J8vCdxAZningWLdN3zgtU6N8ZMMXctdiCjxTNq964yKkwrkBJJwpzZS4HS2fxvyYUA4q2Xe4"},
+		{"invalid prvkey prefix 01", ErrInvalidPrivateKey, "xprv9s21ZrQH143K24Mfq5zL5MhWK9hUhhGbd45hLXo2Pq2oqzMMo63oStZzFAzHGBP2UuGCqWLTAPLcMtD9y5gkZ6Eq3Rjuahrv17fEQ3Qen6J"},
+		{"zero depth with non-zero parent fingerprint", ErrZeroDepthParentFingerprint, "xprv9s2SPatNQ9Vc6GTbVMFPFo7jsaZySyzk7L8n2uqKXJen3KUmvQNTuLh3fhZMBoG3G4ZW1N2kZuHEPY53qmbZzCHshoQnNf4GvELZfqTUrcv"},
+		{"zero depth with non-zero parent fingerprint", ErrZeroDepthParentFingerprint, "xpub661no6RGEX3uJkY4bNnPcw4URcQTrSibUZ4NqJEw5eBkv7ovTwgiT91XX27VbEXGENhYRCf7hyEbWrR3FewATdCEebj6znwMfQkhRYHRLpJ"},
+		{"zero depth with non-zero index", ErrZeroDepthChildNumber, "xprv9s21ZrQH4r4TsiLvyLXqM9P7k1K3EYhA1kkD6xuquB5i39AU8KF42acDyL3qsDbU9NmZn6MsGSUYZEsuoePmjzsB3eFKSUEh3Gu1N3cqVUN"},
+		{"zero depth with non-zero index", ErrZeroDepthChildNumber, "xpub661MyMwAuDcm6CRQ5N4qiHKrJ39Xe1R1NyfouMKTTWcguwVcfrZJaNvhpebzGerh7gucBvzEQWRugZDuDXjNDRmXzSZe4c7mnTK97pTvGS8"},
+		{"unknown extended key version", ErrUnknownVersion, "DMwo58pR1QLEFihHiXPVykYB6fJmsTeHvyTp7hRThAtCX8CvYzgPcn8XnmdfHGMQzT7ayAmfo4z3gY5KfbrZWZ6St24UVf2Qgo6oujFktLHdHY4"},
+		{"unknown extended key version", ErrUnknownVersion, "DMwo58pR1QLEFihHiXPVykYB6fJmsTeHvyTp7hRThAtCX8CvYzgPcn8XnmdfHPmHJiEDXkTiJTVV9rHEBUem2mwVbbNfvT2MTcAqj3nesx8uBf9"},
+		{"private key 0 not in 1..n-1", ErrInvalidPrivateKey, "xprv9s21ZrQH143K24Mfq5zL5MhWK9hUhhGbd45hLXo2Pq2oqzMMo63oStZzF93Y5wvzdUayhgkkFoicQZcP3y52uPPxFnfoLZB21Teqt1VvEHx"},
+		{"private key n not in 1..n-1", ErrInvalidPrivateKey, "xprv9s21ZrQH143K24Mfq5zL5MhWK9hUhhGbd45hLXo2Pq2oqzMMo63oStZzFAzHGBP2UuGCqWLTAPLcMtD5SDKr24z3aiUvKr9bJpdrcLg1y3G"},
+		{"invalid pubkey 020000000000000000000000000000000000000000000000000000000000000007", ErrInvalidPublicKey, "xpub661MyMwAqRbcEYS8w7XLSVeEsBXy79zSzH1J8vCdxAZningWLdN3zgtU6Q5JXayek4PRsn35jii4veMimro1xefsM58PgBMrvdYre8QyULY"},
+		{"invalid checksum", ErrInvalidChecksum, "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHL"},
+	}
+
+	for _, test := range tests {
+		_, err := B58Deserialize(test.base58)
+		assert.Equal(t, test.err, err, test.name)
+	}
+
+	// testnet keys are accepted
+	key, err := NewMasterKey([]byte{1})
+	assert.NoError(t, err)
+	key.Version = TestnetPrivateWalletVersion
+	testnet, err := B58Deserialize(key.String())
+	assert.NoError(t, err)
+	assert.True(t, testnet.IsPrivate)
+	assert.Equal(t, "tprv", testnet.String()[:4])
+}
+
 func TestCantCreateHardenedPublicChild(t *testing.T) {
 	key, err := NewMasterKey([]byte{})
 	assert.NoError(t, err)
@@ -240,6 +283,105 @@ func TestCantCreateHardenedPublicChild(t *testing.T) {
 	assert.Equal(t, ErrHardnedChildPublicKey, err)
 }
 
+// TestCrossImplementationRandomPaths derives random paths from random seeds with
+// this package and with btcutil/hdkeychain, and asserts both produce the same keys.
+func TestCrossImplementationRandomPaths(t *testing.T) {
+	r := rand.New(rand.NewSource(44))
+	diverged := 0
+	for i := 0; i < 64; i++ {
+		seed := make([]byte, 16+r.Intn(49))
+		r.Read(seed)
+
+		key, err := NewMasterKey(seed)
+		assert.NoError(t, err)
+		legacy := key.Copy()
+		ext, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+		assert.NoError(t, err)
+		assert.Equal(t, ext.String(), key.String())
+
+		// hdkeychain v1.0.2 doesn't left pad derived private keys with leading zeros
+		// when deriving hardened children, so it diverges from the spec from this
+		// point on and only matches the legacy derivation.
+		divergedAt := -1
+		depth := 1 + r.Intn(8)
+		for d := 0; d < depth; d++ {
+			index := r.Uint32()
+			if r.Intn(2) == 0 {
+				index %= FirstHardenedChild
+			}
+			if divergedAt < 0 && index >= FirstHardenedChild && key.Depth > 0 && key.Key[0] == 0 {
+				divergedAt = d
+				diverged++
+			}
+
+			pubKey := key.PublicKey()
+			key, err = key.NewChildKey(index)
+			assert.NoError(t, err)
+			legacy, err = legacy.NewLegacyChildKey(index)
+			assert.NoError(t, err)
+			ext, err = ext.Child(index)
+			assert.NoError(t, err)
+			assert.Equal(t, ext.String(), legacy.String(), "seed %x depth %d index %d", seed, d, index)
+			if divergedAt < 0 {
+				assert.Equal(t, ext.String(), key.String(), "seed %x depth %d index %d", seed, d, index)
+			} else if divergedAt == d {
+				assert.NotEqual(t, ext.String(), key.String(), "seed %x depth %d index %d", seed, d, index)
+			}
+
+			// public parent to public child derivation
+			if index < FirstHardenedChild {
+				pubChild, err := pubKey.NewChildKey(index)
+				assert.NoError(t, err)
+				assert.Equal(t, key.PublicKey(), pubChild)
+				if divergedAt < 0 {
+					extPub, err := ext.Neuter()
+					assert.NoError(t, err)
+					assert.Equal(t, extPub.String(), pubChild.String())
+				}
+			}
+		}
+	}
+	t.Logf("%d paths diverged at the hdkeychain leading zero derivation", diverged)
+}
+
+func TestLegacyChildKeyDivergence(t *testing.T) {
+	// the m/44' private key of the seed has a leading zero byte
+	seed, err := hex.DecodeString("f57e5cb1f4532c008183057ecc942838")
+	assert.NoError(t, err)
+	path := DerivationPath{44 + FirstHardenedChild, FirstHardenedChild, FirstHardenedChild, 0, 0}
+
+	master, err := NewMasterKey(seed)
+	assert.NoError(t, err)
+	purpose, err := master.NewChildKey(path[0])
+	assert.NoError(t, err)
+	assert.Equal(t, byte(0), purpose.Key[0])
+
+	ext, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	assert.NoError(t, err)
+	for _, index := range path {
+		ext, err = ext.Child(index)
+		assert.NoError(t, err)
+	}
+
+	key, err := master.DerivePath(path)
+	assert.NoError(t, err)
+	assert.NotEqual(t, ext.String(), key.String())
+
+	legacy, err := master.DeriveLegacyPath(path)
+	assert.NoError(t, err)
+	assert.Equal(t, ext.String(), legacy.String())
+
+	// the legacy derivation only differs for the hardened children of derived keys
+	legacyPurpose, err := master.NewLegacyChildKey(path[0])
+	assert.NoError(t, err)
+	assert.Equal(t, purpose, legacyPurpose)
+	child, err := purpose.NewChildKey(0)
+	assert.NoError(t, err)
+	legacyChild, err := purpose.NewLegacyChildKey(0)
+	assert.NoError(t, err)
+	assert.Equal(t, child, legacyChild)
+}
+
 func assertKeySerialization(t *testing.T, key *Key, knownBase58 string) {
 	serializedBase58 := key.B58Serialize()
 	assert.Equal(t, knownBase58, serializedBase58)
@@ -248,3 +390,72 @@ func assertKeySerialization(t *testing.T, key *Key, knownBase58 string) {
 	assert.NoError(t, err)
 	assert.Equal(t, key, unserializedBase58)
 }
+
+func benchmarkNewChildKey(b *testing.B, key *Key, index uint32) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := key.NewChildKey(index); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewChildKeyPrivate(b *testing.B) {
+	key, err := NewMasterKey([]byte("000102030405060708090a0b0c0d0e0f"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkNewChildKey(b, key, 0)
+}
+
+func BenchmarkNewChildKeyHardened(b *testing.B) {
+	key, err := NewMasterKey([]byte("000102030405060708090a0b0c0d0e0f"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkNewChildKey(b, key, FirstHardenedChild)
+}
+
+func BenchmarkNewChildKeyPublic(b *testing.B) {
+	key, err := NewMasterKey([]byte("000102030405060708090a0b0c0d0e0f"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkNewChildKey(b, key.PublicKey(), 0)
+}
+
+func TestKey_Wipe(t *testing.T) {
+	key, err := NewMasterKey([]byte("000102030405060708090a0b0c0d0e0f"))
+	assert.NoError(t, err)
+	public := key.PublicKey()
+	publicKey := append([]byte{}, public.Key...)
+
+	key.Wipe()
+	assert.Equal(t, make([]byte, 32), key.Key)
+	assert.Equal(t, make([]byte, 32), key.ChainCode)
+
+	// the public key doesn't share the buffers of the private key
+	assert.Equal(t, publicKey, public.Key)
+	assert.NotEqual(t, make([]byte, 32), public.ChainCode)
+}
+
+func TestKey_DerivePathWipesIntermediateKeys(t *testing.T) {
+	key, err := NewMasterKey([]byte("000102030405060708090a0b0c0d0e0f"))
+	assert.NoError(t, err)
+	serialized := key.B58Serialize()
+
+	path := DerivationPath{FirstHardenedChild + 44, FirstHardenedChild, FirstHardenedChild, 0, 7}
+	got, err := key.DerivePath(path)
+	assert.NoError(t, err)
+
+	// the caller key is not wiped
+	assert.Equal(t, serialized, key.B58Serialize())
+
+	want := key
+	for _, index := range path {
+		want, err = want.NewChildKey(index)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, want.B58Serialize(), got.B58Serialize())
+}
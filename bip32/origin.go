@@ -0,0 +1,99 @@
+package bip32
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidKeyOrigin is returned when parsing a malformed key origin
+var ErrInvalidKeyOrigin = errors.New("invalid key origin")
+
+// KeyOrigin represents the origin of a derived key, the fingerprint of the master key and
+// the derivation path from it, as used by PSBTs (BIP174) and output descriptors
+type KeyOrigin struct {
+	Fingerprint []byte // 4 bytes
+	Path        DerivationPath
+}
+
+// Identifier returns the key identifier, the hash160 of the serialized public key
+func (key *Key) Identifier() []byte {
+	keyBytes := key.Key
+	if key.IsPrivate {
+		keyBytes = publicKeyForPrivateKey(keyBytes)
+	}
+	identifier, err := hash160(keyBytes)
+	if err != nil {
+		return nil
+	}
+	return identifier
+}
+
+// Fingerprint returns the key fingerprint, the first 32 bits of the key identifier.
+// It's the FingerPrint of the key children and, for the master key, the master
+// fingerprint of the key origins
+func (key *Key) Fingerprint() []byte {
+	identifier := key.Identifier()
+	if len(identifier) < 4 {
+		return nil
+	}
+	return identifier[:4]
+}
+
+// Origin returns the origin of the key derived from the master key by the path
+func (key *Key) Origin(path DerivationPath) KeyOrigin {
+	return KeyOrigin{
+		Fingerprint: key.Fingerprint(),
+		Path:        append(DerivationPath{}, path...),
+	}
+}
+
+// Child returns the origin of the child key with the index
+func (o KeyOrigin) Child(index uint32) KeyOrigin {
+	path := make(DerivationPath, 0, len(o.Path)+1)
+	path = append(path, o.Path...)
+	return KeyOrigin{Fingerprint: o.Fingerprint, Path: append(path, index)}
+}
+
+// Equal returns true if both origins have the same fingerprint and path
+func (o KeyOrigin) Equal(other KeyOrigin) bool {
+	if !bytes.Equal(o.Fingerprint, other.Fingerprint) || len(o.Path) != len(other.Path) {
+		return false
+	}
+	for i := range o.Path {
+		if o.Path[i] != other.Path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// String implements the stringer interface, encoding the origin in the output
+// descriptor notation without brackets, like d34db33f/44'/0'/0'
+func (o KeyOrigin) String() string {
+	return hex.EncodeToString(o.Fingerprint) + strings.TrimPrefix(o.Path.String(), "m")
+}
+
+// ParseKeyOrigin parses a key origin in the output descriptor notation, the hex
+// fingerprint followed by the derivation path, like d34db33f/44'/0'/0'
+func ParseKeyOrigin(origin string) (KeyOrigin, error) {
+	components := strings.SplitN(strings.TrimSpace(origin), "/", 2)
+	fingerprint, err := hex.DecodeString(components[0])
+	if err != nil || len(fingerprint) != 4 {
+		return KeyOrigin{}, fmt.Errorf("%w: fingerprint %q must be 8 hex characters", ErrInvalidKeyOrigin, components[0])
+	}
+	result := KeyOrigin{Fingerprint: fingerprint, Path: DerivationPath{}}
+	if len(components) == 1 {
+		return result, nil
+	}
+	if components[1] == "" {
+		return KeyOrigin{}, fmt.Errorf("%w: empty path", ErrInvalidKeyOrigin)
+	}
+	result.Path, err = ParseDerivationPath(components[1])
+	if err != nil {
+		return KeyOrigin{}, fmt.Errorf("%w: %v", ErrInvalidKeyOrigin, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,59 @@
+package bip32
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKey_Identifier(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(seed)
+	assert.NoError(t, err)
+
+	// BIP32 test vector 1 master key identifier
+	assert.Equal(t, "3442193e1bb70916e914552172cd4e2dbc9df811", hex.EncodeToString(master.Identifier()))
+	assert.Equal(t, "3442193e", hex.EncodeToString(master.Fingerprint()))
+	assert.Equal(t, master.Identifier(), master.PublicKey().Identifier())
+
+	child, err := master.NewChildKey(FirstHardenedChild)
+	assert.NoError(t, err)
+	assert.Equal(t, master.Fingerprint(), child.FingerPrint)
+	assert.Equal(t, "5c1bd648", hex.EncodeToString(child.Fingerprint()))
+
+	pubChild, err := child.PublicKey().NewChildKey(1)
+	assert.NoError(t, err)
+	assert.Equal(t, child.Fingerprint(), pubChild.FingerPrint)
+}
+
+func TestKeyOrigin(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(seed)
+	assert.NoError(t, err)
+
+	path, err := ParseDerivationPath("m/84'/0'/0'")
+	assert.NoError(t, err)
+	origin := master.Origin(path)
+	assert.Equal(t, "3442193e/84'/0'/0'", origin.String())
+
+	child := origin.Child(5)
+	assert.Equal(t, "3442193e/84'/0'/0'/5", child.String())
+	assert.Equal(t, "3442193e/84'/0'/0'", origin.String())
+	assert.False(t, origin.Equal(child))
+
+	parsed, err := ParseKeyOrigin("3442193e/84h/0h/0h/5")
+	assert.NoError(t, err)
+	assert.True(t, child.Equal(parsed))
+
+	parsed, err = ParseKeyOrigin("3442193e")
+	assert.NoError(t, err)
+	assert.Equal(t, "3442193e", parsed.String())
+	assert.True(t, master.Origin(nil).Equal(parsed))
+
+	for _, invalid := range []string{"", "3442193", "3442193e00", "zz42193e", "3442193e/", "3442193e/a"} {
+		_, err := ParseKeyOrigin(invalid)
+		assert.True(t, errors.Is(err, ErrInvalidKeyOrigin), "origin %q", invalid)
+	}
+}
@@ -0,0 +1,132 @@
+package bip32
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidDerivationPath is returned when parsing a malformed derivation path
+	ErrInvalidDerivationPath = errors.New("invalid derivation path")
+
+	// ErrDeriveBeyondMaxDepth is returned when the derived key depth would exceed
+	// the maximum depth of 255 that can be serialized
+	ErrDeriveBeyondMaxDepth = errors.New("cannot derive a key with more than 255 indices in its path")
+)
+
+// DerivationPath represents the computer friendly version of a hierarchical
+// deterministic wallet account derivation path.
+//
+// The BIP-32 spec https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki
+// defines derivation paths to be of the form:
+//
+//	m / purpose' / coin_type' / account' / change / address_index
+//
+// The hardened indexes can be written with the ', h or H suffix.
+type DerivationPath []uint32
+
+// ParseDerivationPath converts a user specified derivation path string to the
+// internal binary representation. The leading "m" of the master key is optional,
+// so absolute (m/44'/60'/0'/0/5) and relative (0/5) paths are both accepted.
+func ParseDerivationPath(path string) (DerivationPath, error) {
+	path = strings.TrimSpace(path)
+	if path == "" || path == "m" {
+		return DerivationPath{}, nil
+	}
+	components := strings.Split(path, "/")
+	if strings.TrimSpace(components[0]) == "m" {
+		components = components[1:]
+	}
+
+	result := make(DerivationPath, 0, len(components))
+	for _, component := range components {
+		index, err := ParseChildNumber(component)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, index)
+	}
+	return result, nil
+}
+
+// ParseChildNumber converts a single path component (5, 44', 44h or 44H) to the
+// child number, adding FirstHardenedChild to hardened indexes
+func ParseChildNumber(component string) (uint32, error) {
+	component = strings.TrimSpace(component)
+	offset := uint32(0)
+	if strings.HasSuffix(component, "'") || strings.HasSuffix(component, "h") || strings.HasSuffix(component, "H") {
+		offset = FirstHardenedChild
+		component = strings.TrimSpace(component[:len(component)-1])
+	}
+	if component == "" {
+		return 0, fmt.Errorf("%w: empty component", ErrInvalidDerivationPath)
+	}
+	index, err := strconv.ParseUint(component, 10, 32)
+	if err != nil || uint32(index) >= FirstHardenedChild {
+		return 0, fmt.Errorf("%w: component %q out of allowed range [0, %d]", ErrInvalidDerivationPath, component, FirstHardenedChild-1)
+	}
+	return uint32(index) + offset, nil
+}
+
+// String implements the stringer interface, converting a binary derivation path
+// to its canonical representation, using the ' suffix for hardened indexes.
+func (path DerivationPath) String() string {
+	var result strings.Builder
+	result.WriteString("m")
+	for _, component := range path {
+		result.WriteString("/")
+		if component >= FirstHardenedChild {
+			result.WriteString(strconv.FormatUint(uint64(component-FirstHardenedChild), 10))
+			result.WriteString("'")
+			continue
+		}
+		result.WriteString(strconv.FormatUint(uint64(component), 10))
+	}
+	return result.String()
+}
+
+// IsHardened returns true if any index of the path is hardened, meaning the path
+// can't be derived from a public key
+func (path DerivationPath) IsHardened() bool {
+	for _, component := range path {
+		if component >= FirstHardenedChild {
+			return true
+		}
+	}
+	return false
+}
+
+// DerivePath derives the descendant key for each index of the path, or a copy
+// of the key for an empty path
+func (key *Key) DerivePath(path DerivationPath) (*Key, error) {
+	return key.derivePath(path, false)
+}
+
+// DeriveLegacyPath is like DerivePath, but derives each child with NewLegacyChildKey
+func (key *Key) DeriveLegacyPath(path DerivationPath) (*Key, error) {
+	return key.derivePath(path, true)
+}
+
+func (key *Key) derivePath(path DerivationPath, legacy bool) (*Key, error) {
+	if int(key.Depth)+len(path) > 255 {
+		return nil, ErrDeriveBeyondMaxDepth
+	}
+	if len(path) == 0 {
+		return key.Copy(), nil
+	}
+	// the intermediate keys are wiped, but not the caller key
+	current := key
+	for _, index := range path {
+		child, err := current.newChildKey(index, legacy)
+		if current != key {
+			current.Wipe()
+		}
+		if err != nil {
+			return nil, err
+		}
+		current = child
+	}
+	return current, nil
+}
@@ -0,0 +1,97 @@
+package bip32
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDerivationPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want DerivationPath
+	}{
+		{path: "m", want: DerivationPath{}},
+		{path: "", want: DerivationPath{}},
+		{path: "m/44'/60'/0'/0/5", want: DerivationPath{44 + FirstHardenedChild, 60 + FirstHardenedChild, FirstHardenedChild, 0, 5}},
+		{path: "m/44h/60H/0'/0/5", want: DerivationPath{44 + FirstHardenedChild, 60 + FirstHardenedChild, FirstHardenedChild, 0, 5}},
+		{path: "0/5", want: DerivationPath{0, 5}},
+		{path: " m / 2147483647' / 2147483647 ", want: DerivationPath{0xffffffff, 0x7fffffff}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := ParseDerivationPath(tt.path)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseDerivationPathInvalid(t *testing.T) {
+	for _, path := range []string{
+		"m/",
+		"m//0",
+		"m/44''",
+		"m/-1",
+		"m/+1",
+		"m/2147483648",
+		"m/2147483648'",
+		"m/0x10",
+		"m/a",
+		"/0",
+		"m/0/m",
+	} {
+		t.Run(path, func(t *testing.T) {
+			_, err := ParseDerivationPath(path)
+			assert.True(t, errors.Is(err, ErrInvalidDerivationPath), "error %v", err)
+		})
+	}
+}
+
+func TestDerivationPath_String(t *testing.T) {
+	for _, path := range []string{"m", "m/44'/60'/0'/0/5", "m/0/2147483647'/1"} {
+		parsed, err := ParseDerivationPath(path)
+		assert.NoError(t, err)
+		assert.Equal(t, path, parsed.String())
+	}
+
+	parsed, err := ParseDerivationPath("m/84h/0H/0'")
+	assert.NoError(t, err)
+	assert.Equal(t, "m/84'/0'/0'", parsed.String())
+	assert.True(t, parsed.IsHardened())
+	assert.False(t, DerivationPath{0, 1}.IsHardened())
+}
+
+func TestKey_DerivePath(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(seed)
+	assert.NoError(t, err)
+
+	path, err := ParseDerivationPath("m/0'/1/2'/2/1000000000")
+	assert.NoError(t, err)
+	key, err := master.DerivePath(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "xprvA41z7zogVVwxVSgdKUHDy1SKmdb533PjDz7J6N6mV6uS3ze1ai8FHa8kmHScGpWmj4WggLyQjgPie1rFSruoUihUZREPSL39UNdE3BBDu76", key.String())
+
+	// public derivation of the non hardened tail
+	parent, err := master.DerivePath(path[:3])
+	assert.NoError(t, err)
+	pubKey, err := parent.PublicKey().DerivePath(path[3:])
+	assert.NoError(t, err)
+	assert.Equal(t, "xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy", pubKey.String())
+
+	_, err = master.PublicKey().DerivePath(path)
+	assert.Equal(t, ErrHardnedChildPublicKey, err)
+
+	_, err = master.DerivePath(make(DerivationPath, 256))
+	assert.Equal(t, ErrDeriveBeyondMaxDepth, err)
+
+	// an empty path returns a copy that can be wiped independently
+	copied, err := master.DerivePath(DerivationPath{})
+	assert.NoError(t, err)
+	assert.Equal(t, master, copied)
+	copied.Wipe()
+	assert.NotEqual(t, make([]byte, 32), master.Key)
+}
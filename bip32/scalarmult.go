@@ -0,0 +1,177 @@
+package bip32
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"sync"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// The base point multiplication of the private keys, in constant time: the scalar is read
+// in windows of 4 bits, each one selecting the multiple of its position from a precomputed
+// table with a constant time lookup, and the multiples are summed with the complete addition
+// formula, without branches on the scalar bits.
+
+const (
+	windowBits = 4
+	windowSize = 1 << windowBits
+	windows    = 256 / windowBits
+)
+
+// projectivePoint is a secp256k1 point in homogeneous projective coordinates (X:Y:Z),
+// the point at infinity is (0:1:0)
+type projectivePoint struct {
+	x, y, z secp256k1.FieldVal
+}
+
+// encodedPoint is the normalized big endian encoding of the projective coordinates,
+// in 64 bits words
+type encodedPoint [12]uint64
+
+var (
+	// baseTable has the multiples j*16^(windows-1-i)*G of the base point, for each window i
+	// of the big endian scalar and window value j
+	baseTable     *[windows][windowSize]encodedPoint
+	baseTableOnce sync.Once
+)
+
+// computeBaseTable computes the multiples of the base point of each window
+func computeBaseTable() {
+	table := new([windows][windowSize]encodedPoint)
+	var base, point projectivePoint
+	params := secp256k1.Params()
+	base.x.SetByteSlice(params.Gx.Bytes())
+	base.y.SetByteSlice(params.Gy.Bytes())
+	base.z.SetInt(1)
+	for i := windows - 1; i >= 0; i-- {
+		point = projectivePoint{}
+		point.y.SetInt(1)
+		for j := range table[i] {
+			table[i][j] = point.encode()
+			point.add(&point, &base)
+		}
+		// the base of the next window is 16 times the base
+		base = point
+	}
+	baseTable = table
+}
+
+// scalarBaseMult returns the affine coordinates of k*G, in constant time
+func scalarBaseMult(k *secp256k1.ModNScalar) (x, y secp256k1.FieldVal) {
+	baseTableOnce.Do(computeBaseTable)
+	scalar := k.Bytes()
+	defer zero(scalar[:])
+	var result, multiple projectivePoint
+	var selected encodedPoint
+	result.y.SetInt(1)
+	for i, b := range scalar {
+		for j, window := range [2]byte{b >> windowBits, b & (windowSize - 1)} {
+			selectMultiple(&selected, &baseTable[2*i+j], window)
+			multiple.decode(&selected)
+			result.add(&result, &multiple)
+		}
+	}
+	multiple.decode(&encodedPoint{})
+	selected = encodedPoint{}
+
+	var zInv secp256k1.FieldVal
+	zInv.Set(&result.z).Inverse()
+	x.Mul2(&result.x, &zInv).Normalize()
+	y.Mul2(&result.y, &zInv).Normalize()
+	return x, y
+}
+
+// selectMultiple copies the entry of the window value, reading every entry of the
+// window with a constant time mask
+func selectMultiple(selected *encodedPoint, multiples *[windowSize]encodedPoint, window byte) {
+	*selected = encodedPoint{}
+	for i := range multiples {
+		mask := -uint64(subtle.ConstantTimeByteEq(byte(i), window))
+		for w := range selected {
+			selected[w] |= multiples[i][w] & mask
+		}
+	}
+}
+
+// add sets the point to p + q with the complete addition formula of Renes, Costello
+// and Batina (https://eprint.iacr.org/2015/1060, algorithm 7 for a = 0), which is valid
+// for any input, including doubling and the point at infinity
+func (r *projectivePoint) add(p, q *projectivePoint) {
+	var t0, t1, t2, t3, t4, x3, y3, z3 secp256k1.FieldVal
+	t0.Mul2(&p.x, &q.x)
+	t1.Mul2(&p.y, &q.y)
+	t2.Mul2(&p.z, &q.z)
+	t3.Add2(&p.x, &p.y).Normalize()
+	t4.Add2(&q.x, &q.y).Normalize()
+	t3.Mul(&t4)
+	t4.Add2(&t0, &t1).Normalize()
+	fieldSub(&t3, &t4)
+	t4.Add2(&p.y, &p.z).Normalize()
+	x3.Add2(&q.y, &q.z).Normalize()
+	t4.Mul(&x3)
+	x3.Add2(&t1, &t2).Normalize()
+	fieldSub(&t4, &x3)
+	x3.Add2(&p.x, &p.z).Normalize()
+	y3.Add2(&q.x, &q.z).Normalize()
+	x3.Mul(&y3)
+	y3.Add2(&t0, &t2).Normalize()
+	y3.NegateVal(&y3, 1).Add(&x3).Normalize()
+	x3.Add2(&t0, &t0).Normalize()
+	t0.Add(&x3).Normalize()
+	t2.MulInt(21).Normalize() // 3b
+	z3.Add2(&t1, &t2).Normalize()
+	fieldSub(&t1, &t2)
+	y3.MulInt(21).Normalize()
+	x3.Mul2(&t4, &y3)
+	t2.Mul2(&t3, &t1)
+	x3.NegateVal(&x3, 1).Add(&t2).Normalize()
+	y3.Mul(&t0)
+	t1.Mul(&z3)
+	y3.Add(&t1).Normalize()
+	t0.Mul(&t3)
+	z3.Mul(&t4)
+	z3.Add(&t0).Normalize()
+	r.x.Set(&x3)
+	r.y.Set(&y3)
+	r.z.Set(&z3)
+}
+
+// encode returns the normalized coordinates of the point
+func (r *projectivePoint) encode() encodedPoint {
+	var encoded encodedPoint
+	for c, coordinate := range []*secp256k1.FieldVal{&r.x, &r.y, &r.z} {
+		var b [32]byte
+		coordinate.Normalize().PutBytes(&b)
+		for w := 0; w < 4; w++ {
+			encoded[4*c+w] = binary.BigEndian.Uint64(b[8*w:])
+		}
+	}
+	return encoded
+}
+
+// decode sets the point to the encoded coordinates
+func (r *projectivePoint) decode(encoded *encodedPoint) {
+	var b [32]byte
+	for c, coordinate := range []*secp256k1.FieldVal{&r.x, &r.y, &r.z} {
+		for w := 0; w < 4; w++ {
+			binary.BigEndian.PutUint64(b[8*w:], encoded[4*c+w])
+		}
+		coordinate.SetBytes(&b)
+	}
+	zero(b[:])
+}
+
+// fieldSub sets a to a - b, the values must have magnitude 1
+func fieldSub(a, b *secp256k1.FieldVal) {
+	var negated secp256k1.FieldVal
+	negated.NegateVal(b, 1)
+	a.Add(&negated).Normalize()
+}
+
+// zero zeroes the buffer
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
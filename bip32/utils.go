@@ -1,22 +1,16 @@
 package bip32
 
 import (
-	"bytes"
 	"crypto/sha256"
 	"encoding/binary"
-	"fmt"
 	"io"
-	"math/big"
 
 	"github.com/FactomProject/basen"
-	"github.com/FactomProject/btcutilecc"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"golang.org/x/crypto/ripemd160"
 )
 
 var (
-	curve       = btcutil.Secp256k1()
-	curveParams = curve.Params()
-
 	// BitcoinBase58Encoding is the encoding used for bitcoin addresses
 	BitcoinBase58Encoding = basen.NewEncoding("123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz")
 )
@@ -100,92 +94,90 @@ func base58Decode(data string) ([]byte, error) {
 }
 
 // Keys
-func publicKeyForPrivateKey(key []byte) []byte {
-	return compressPublicKey(curve.ScalarBaseMult(key))
-}
 
-func addPublicKeys(key1 []byte, key2 []byte) []byte {
-	x1, y1 := expandPublicKey(key1)
-	x2, y2 := expandPublicKey(key2)
-	return compressPublicKey(curve.Add(x1, y1, x2, y2))
-}
-
-func addPrivateKeys(key1 []byte, key2 []byte) []byte {
-	var key1Int big.Int
-	var key2Int big.Int
-	key1Int.SetBytes(key1)
-	key2Int.SetBytes(key2)
-
-	key1Int.Add(&key1Int, &key2Int)
-	key1Int.Mod(&key1Int, curve.Params().N)
-
-	b := key1Int.Bytes()
-	if len(b) < 32 {
-		extra := make([]byte, 32-len(b))
-		b = append(extra, b...)
+// publicKeyForPrivateKey returns the compressed public key of the private key,
+// multiplying the base point in constant time
+func publicKeyForPrivateKey(key []byte) []byte {
+	var scalar secp256k1.ModNScalar
+	scalar.SetByteSlice(key)
+	defer scalar.Zero()
+	x, y := scalarBaseMult(&scalar)
+	return secp256k1.NewPublicKey(&x, &y).SerializeCompressed()
+}
+
+// publicKeyForPublicScalar returns the compressed public key of a scalar computed from
+// public data, like the intermediary of the public child keys, in variable time
+func publicKeyForPublicScalar(key []byte) []byte {
+	var scalar secp256k1.ModNScalar
+	scalar.SetByteSlice(key)
+	var point secp256k1.JacobianPoint
+	secp256k1.ScalarBaseMultNonConst(&scalar, &point)
+	point.ToAffine()
+	return secp256k1.NewPublicKey(&point.X, &point.Y).SerializeCompressed()
+}
+
+// addPublicKeys adds the points of the compressed public keys
+// It returns the compressed sum and an error if a key is invalid or the sum is the point at infinity
+func addPublicKeys(key1 []byte, key2 []byte) ([]byte, error) {
+	pubKey1, err := secp256k1.ParsePubKey(key1)
+	if err != nil {
+		return nil, ErrInvalidPublicKey
 	}
-	return b
-}
-
-func compressPublicKey(x *big.Int, y *big.Int) []byte {
-	var key bytes.Buffer
-
-	// Write header; 0x2 for even y value; 0x3 for odd
-	key.WriteByte(byte(0x2) + byte(y.Bit(0)))
-
-	// Write X coord; Pad the key so x is aligned with the LSB. Pad size is key length - header size (1) - xBytes size
-	xBytes := x.Bytes()
-	for i := 0; i < (PublicKeyCompressedLength - 1 - len(xBytes)); i++ {
-		key.WriteByte(0x0)
+	pubKey2, err := secp256k1.ParsePubKey(key2)
+	if err != nil {
+		return nil, ErrInvalidPublicKey
 	}
-	key.Write(xBytes)
-
-	return key.Bytes()
+	var point1, point2, sum secp256k1.JacobianPoint
+	pubKey1.AsJacobian(&point1)
+	pubKey2.AsJacobian(&point2)
+	secp256k1.AddNonConst(&point1, &point2, &sum)
+	if (sum.X.IsZero() && sum.Y.IsZero()) || sum.Z.IsZero() {
+		return nil, ErrInvalidPublicKey
+	}
+	sum.ToAffine()
+	return secp256k1.NewPublicKey(&sum.X, &sum.Y).SerializeCompressed(), nil
 }
 
-// As described at https://crypto.stackexchange.com/a/8916
-func expandPublicKey(key []byte) (*big.Int, *big.Int) {
-	Y := big.NewInt(0)
-	X := big.NewInt(0)
-	X.SetBytes(key[1:])
-
-	// y^2 = x^3 + ax^2 + b
-	// a = 0
-	// => y^2 = x^3 + b
-	ySquared := big.NewInt(0)
-	ySquared.Exp(X, big.NewInt(3), nil)
-	ySquared.Add(ySquared, curveParams.B)
-
-	Y.ModSqrt(ySquared, curveParams.P)
-
-	Ymod2 := big.NewInt(0)
-	Ymod2.Mod(Y, big.NewInt(2))
-
-	signY := uint64(key[0]) - 2
-	if signY != Ymod2.Uint64() {
-		Y.Sub(curveParams.P, Y)
+// addPrivateKeys adds the private keys modulo the curve order in constant time
+func addPrivateKeys(key1 []byte, key2 []byte) []byte {
+	var a, b secp256k1.ModNScalar
+	a.SetByteSlice(key1)
+	b.SetByteSlice(key2)
+	a.Add(&b)
+	result := make([]byte, 32)
+	a.PutBytesUnchecked(result)
+	a.Zero()
+	b.Zero()
+	return result
+}
+
+// isValidScalar returns true if the 32 bytes key is in the range [1, n-1], in constant time
+func isValidScalar(key []byte) bool {
+	if len(key) != 32 {
+		return false
 	}
-
-	return X, Y
+	var scalar secp256k1.ModNScalar
+	overflow := scalar.SetByteSlice(key)
+	defer scalar.Zero()
+	return !overflow && !scalar.IsZero()
 }
 
 func validatePrivateKey(key []byte) error {
-	if fmt.Sprintf("%x", key) == "0000000000000000000000000000000000000000000000000000000000000000" || //if the key is zero
-		bytes.Compare(key, curveParams.N.Bytes()) >= 0 || //or is outside of the curve
-		len(key) != 32 { //or is too short
+	if !isValidScalar(key) {
 		return ErrInvalidPrivateKey
 	}
-
 	return nil
 }
 
-func validateChildPublicKey(key []byte) error {
-	x, y := expandPublicKey(key)
-
-	if x.Sign() == 0 || y.Sign() == 0 {
+// validatePublicKey checks the compressed public key prefix and that the x coordinate
+// is a point of the curve
+func validatePublicKey(key []byte) error {
+	if len(key) != PublicKeyCompressedLength || (key[0] != 0x2 && key[0] != 0x3) {
+		return ErrInvalidPublicKey
+	}
+	if _, err := secp256k1.ParsePubKey(key); err != nil {
 		return ErrInvalidPublicKey
 	}
-
 	return nil
 }
 
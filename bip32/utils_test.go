@@ -0,0 +1,102 @@
+package bip32
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddPrivateKeys(t *testing.T) {
+	n := secp256k1.Params().N
+	nMinus1 := new(big.Int).Sub(n, big.NewInt(1))
+	values := []*big.Int{
+		big.NewInt(1),
+		big.NewInt(2),
+		nMinus1,
+		new(big.Int).Sub(n, big.NewInt(2)),
+		new(big.Int).Rsh(n, 1),
+	}
+	r := rand.New(rand.NewSource(38))
+	for i := 0; i < 32; i++ {
+		values = append(values, new(big.Int).Rand(r, n))
+	}
+
+	for _, a := range values {
+		for _, b := range values {
+			want := new(big.Int).Add(a, b)
+			want.Mod(want, n)
+			got := addPrivateKeys(paddedScalar(a), paddedScalar(b))
+			assert.Len(t, got, 32)
+			assert.Equal(t, 0, want.Cmp(new(big.Int).SetBytes(got)), "%x + %x", a, b)
+		}
+	}
+}
+
+func TestValidatePrivateKey(t *testing.T) {
+	n := secp256k1.Params().N
+	maxScalar := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	tests := []struct {
+		key   *big.Int
+		valid bool
+	}{
+		{big.NewInt(0), false},
+		{big.NewInt(1), true},
+		{new(big.Int).Sub(n, big.NewInt(1)), true},
+		{n, false},
+		{new(big.Int).Add(n, big.NewInt(1)), false},
+		{maxScalar, false},
+		{new(big.Int).Lsh(big.NewInt(1), 128), true},
+	}
+	for _, tt := range tests {
+		err := validatePrivateKey(paddedScalar(tt.key))
+		if tt.valid {
+			assert.NoError(t, err, "%x", tt.key)
+		} else {
+			assert.Equal(t, ErrInvalidPrivateKey, err, "%x", tt.key)
+		}
+	}
+	assert.Equal(t, ErrInvalidPrivateKey, validatePrivateKey([]byte{1}))
+}
+
+func TestAddPublicKeysInfinity(t *testing.T) {
+	key := paddedScalar(big.NewInt(7))
+	negated := paddedScalar(new(big.Int).Sub(secp256k1.Params().N, big.NewInt(7)))
+	_, err := addPublicKeys(publicKeyForPrivateKey(key), publicKeyForPrivateKey(negated))
+	assert.Equal(t, ErrInvalidPublicKey, err)
+
+	sum, err := addPublicKeys(publicKeyForPrivateKey(key), publicKeyForPrivateKey(key))
+	assert.NoError(t, err)
+	assert.Equal(t, publicKeyForPrivateKey(paddedScalar(big.NewInt(14))), sum)
+}
+
+func paddedScalar(n *big.Int) []byte {
+	b := n.Bytes()
+	return append(make([]byte, 32-len(b)), b...)
+}
+
+func TestPublicKeyForPrivateKey(t *testing.T) {
+	n := secp256k1.Params().N
+	values := []*big.Int{
+		big.NewInt(1),
+		big.NewInt(2),
+		big.NewInt(15),
+		big.NewInt(16),
+		new(big.Int).Sub(n, big.NewInt(1)),
+		new(big.Int).Sub(n, big.NewInt(16)),
+		new(big.Int).Rsh(n, 1),
+	}
+	r := rand.New(rand.NewSource(38))
+	for i := 0; i < 64; i++ {
+		values = append(values, new(big.Int).Add(new(big.Int).Rand(r, new(big.Int).Sub(n, big.NewInt(1))), big.NewInt(1)))
+	}
+
+	for _, value := range values {
+		key := paddedScalar(value)
+		// the variable time multiplication of secp256k1/v4
+		want := secp256k1.PrivKeyFromBytes(key).PubKey().SerializeCompressed()
+		assert.Equal(t, want, publicKeyForPrivateKey(key), "%x", value)
+	}
+}
@@ -0,0 +1,29 @@
+package pool_party
+
+import (
+	"github.com/Pantani/errors"
+	"github.com/Pantani/pool-party/bip39"
+	"github.com/Pantani/pool-party/bip44"
+)
+
+// GenerateBIP38AddressPool generates the address pool based in the index and length, with the
+// private keys encrypted by the passphrase (BIP38) for paper backups. Only the UTXO coins are supported.
+// It returns the addresses with the encrypted keys (6P...) and an error if occurs
+func (p *Pool) GenerateBIP38AddressPool(start, length int, passphrase string) (bip44.Addresses, error) {
+	if p.descriptor != nil {
+		return nil, errors.E("watch-only pool has no private keys", errors.Params{"coin": p.coin})
+	}
+	addresses, err := p.GenerateAddressPool(start, length)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := addresses.BIP38(p.coin, passphrase)
+	if err != nil {
+		return nil, errors.E(err, "error to encrypt the address pool", errors.Params{"coin": p.coin, "start": start, "length": length})
+	}
+	for i := range addresses {
+		bip39.Zero(addresses[i].Privkey)
+		addresses[i].Privkey = []byte(encrypted[i])
+	}
+	return addresses, nil
+}
@@ -0,0 +1,403 @@
+// Package bip38 implements the passphrase-protected private keys (BIP38) of the UTXO coins,
+// the non-EC-multiply mode, encrypting a known private key, and the EC-multiply mode, where
+// a third party generates encrypted keys from the intermediate code of the passphrase owner.
+//
+// The BIP38 spec can be found at
+// https://github.com/bitcoin/bips/blob/master/bip-0038.mediawiki
+package bip38
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/Pantani/pool-party/bip39"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/base58"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// encryptedKeyLength is the length of the encrypted keys without the base58 checksum
+	encryptedKeyLength = 39
+
+	// intermediateCodeLength is the length of the intermediate codes without the base58 checksum
+	intermediateCodeLength = 49
+
+	// the flag byte bits
+	flagNonECMultiply = 0xc0
+	flagCompressed    = 0x20
+	flagLotSequence   = 0x04
+
+	// maxLot and maxSequence are the limits of the lot and sequence numbers
+	maxLot      = 1048575
+	maxSequence = 4095
+)
+
+var (
+	// prefixes of the base58 payloads
+	prefixNonECMultiply = []byte{0x01, 0x42}
+	prefixECMultiply    = []byte{0x01, 0x43}
+	magicNoLot          = []byte{0x2c, 0xe9, 0xb3, 0xe1, 0xff, 0x39, 0xe2, 0x53}
+	magicLot            = []byte{0x2c, 0xe9, 0xb3, 0xe1, 0xff, 0x39, 0xe2, 0x51}
+
+	// ErrInvalidEncryptedKey is returned when decoding a malformed encrypted key
+	ErrInvalidEncryptedKey = errors.New("invalid bip38 encrypted key")
+
+	// ErrInvalidIntermediateCode is returned when decoding a malformed intermediate code
+	ErrInvalidIntermediateCode = errors.New("invalid bip38 intermediate code")
+
+	// ErrWrongPassphrase is returned when the decrypted key doesn't match the address hash
+	ErrWrongPassphrase = errors.New("wrong bip38 passphrase")
+
+	// ErrInvalidFactor is returned when the random seed generates an invalid private key
+	// factor, which has a negligible probability
+	ErrInvalidFactor = errors.New("invalid bip38 seed factor")
+
+	// ErrInvalidLotSequence is returned when the lot or sequence numbers are out of range
+	ErrInvalidLotSequence = errors.New("lot must be up to 1048575 and sequence up to 4095")
+)
+
+// Encrypt encrypts the private key with the passphrase by the non-EC-multiply mode,
+// the address hash is computed with the pubkey hash version of the network
+// It returns the encrypted key (6P...) and an error if occurs
+func Encrypt(wif *btcutil.WIF, passphrase string, net *chaincfg.Params) (string, error) {
+	address, err := addressOf(wif.PrivKey.PubKey(), wif.CompressPubKey, net)
+	if err != nil {
+		return "", err
+	}
+	addressHash := hashAddress(address)
+
+	derived, err := scrypt.Key(normalize(passphrase), addressHash, 16384, 8, 8, 64)
+	if err != nil {
+		return "", err
+	}
+	defer bip39.Zero(derived)
+	privKey := paddedKey(wif.PrivKey)
+	defer bip39.Zero(privKey)
+
+	flag := byte(flagNonECMultiply)
+	if wif.CompressPubKey {
+		flag |= flagCompressed
+	}
+	payload := make([]byte, 0, encryptedKeyLength)
+	payload = append(payload, prefixNonECMultiply...)
+	payload = append(payload, flag)
+	payload = append(payload, addressHash...)
+	payload = append(payload, encryptBlock(xor(privKey[:16], derived[:16]), derived[32:])...)
+	payload = append(payload, encryptBlock(xor(privKey[16:], derived[16:32]), derived[32:])...)
+	return encode(payload), nil
+}
+
+// Decrypt decrypts the encrypted key of both modes with the passphrase, checking the address hash
+// It returns the private key in the wallet import format of the network and an error if occurs
+func Decrypt(encrypted, passphrase string, net *chaincfg.Params) (*btcutil.WIF, error) {
+	payload, err := decode(encrypted, encryptedKeyLength)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncryptedKey, err)
+	}
+	defer bip39.Zero(payload)
+	flag, addressHash := payload[2], payload[3:7]
+	compressed := flag&flagCompressed != 0
+
+	var privKey []byte
+	switch {
+	case bytes.Equal(payload[:2], prefixNonECMultiply) && flag&^flagCompressed == flagNonECMultiply:
+		privKey, err = decryptNonECMultiply(payload, passphrase)
+	case bytes.Equal(payload[:2], prefixECMultiply) && flag&^(flagCompressed|flagLotSequence) == 0:
+		privKey, err = decryptECMultiply(payload, passphrase)
+	default:
+		return nil, fmt.Errorf("%w: unknown prefix or flag", ErrInvalidEncryptedKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer bip39.Zero(privKey)
+	if !isValidPrivateKey(privKey) {
+		return nil, ErrWrongPassphrase
+	}
+
+	privk, pubk := btcec.PrivKeyFromBytes(btcec.S256(), privKey)
+	address, err := addressOf(pubk, compressed, net)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(hashAddress(address), addressHash) {
+		return nil, ErrWrongPassphrase
+	}
+	return btcutil.NewWIF(privk, net, compressed)
+}
+
+// decryptNonECMultiply decrypts the private key of the non-EC-multiply mode
+func decryptNonECMultiply(payload []byte, passphrase string) ([]byte, error) {
+	derived, err := scrypt.Key(normalize(passphrase), payload[3:7], 16384, 8, 8, 64)
+	if err != nil {
+		return nil, err
+	}
+	defer bip39.Zero(derived)
+	privKey := make([]byte, 0, 32)
+	privKey = append(privKey, xor(decryptBlock(payload[7:23], derived[32:]), derived[:16])...)
+	privKey = append(privKey, xor(decryptBlock(payload[23:39], derived[32:]), derived[16:32])...)
+	return privKey, nil
+}
+
+// decryptECMultiply decrypts the private key of the EC-multiply mode, the passfactor times factorb
+func decryptECMultiply(payload []byte, passphrase string) ([]byte, error) {
+	flag, addressHash, ownerEntropy := payload[2], payload[3:7], payload[7:15]
+	passFactor, err := newPassFactor(passphrase, ownerEntropy, flag&flagLotSequence != 0)
+	if err != nil {
+		return nil, err
+	}
+	defer bip39.Zero(passFactor)
+	_, passPoint := btcec.PrivKeyFromBytes(btcec.S256(), passFactor)
+
+	derived, err := scrypt.Key(passPoint.SerializeCompressed(), append(append([]byte{}, addressHash...), ownerEntropy...), 1024, 1, 1, 64)
+	if err != nil {
+		return nil, err
+	}
+	defer bip39.Zero(derived)
+
+	// encryptedpart2 = AES(encryptedpart1[8:16] + seedb[16:24] XOR derivedhalf1[16:32])
+	part2 := xor(decryptBlock(payload[23:39], derived[32:]), derived[16:32])
+	defer bip39.Zero(part2)
+	part1 := append(append([]byte{}, payload[15:23]...), part2[:8]...)
+	seedB := append(xor(decryptBlock(part1, derived[32:]), derived[:16]), part2[8:]...)
+	defer bip39.Zero(seedB)
+
+	factorB := doubleSha256(seedB)
+	defer bip39.Zero(factorB)
+	privKey := new(big.Int).SetBytes(passFactor)
+	privKey.Mul(privKey, new(big.Int).SetBytes(factorB))
+	privKey.Mod(privKey, btcec.S256().N)
+	key := paddedBytes(privKey)
+	privKey.SetInt64(0)
+	return key, nil
+}
+
+// NewIntermediateCode creates the intermediate code (passphrase...) of the passphrase, without
+// lot and sequence numbers, reading the owner salt from the random source
+// It returns the intermediate code and an error if occurs
+func NewIntermediateCode(random io.Reader, passphrase string) (string, error) {
+	ownerSalt := make([]byte, 8)
+	if _, err := io.ReadFull(random, ownerSalt); err != nil {
+		return "", err
+	}
+	return newIntermediateCode(passphrase, ownerSalt, false)
+}
+
+// NewIntermediateCodeWithLot creates the intermediate code of the passphrase with the lot
+// (up to 1048575) and sequence (up to 4095) numbers, reading the owner salt from the random source
+// It returns the intermediate code and an error if occurs
+func NewIntermediateCodeWithLot(random io.Reader, passphrase string, lot, sequence uint32) (string, error) {
+	if lot > maxLot || sequence > maxSequence {
+		return "", ErrInvalidLotSequence
+	}
+	ownerEntropy := make([]byte, 8)
+	if _, err := io.ReadFull(random, ownerEntropy[:4]); err != nil {
+		return "", err
+	}
+	lotSequence := lot*4096 + sequence
+	ownerEntropy[4], ownerEntropy[5], ownerEntropy[6], ownerEntropy[7] = byte(lotSequence>>24), byte(lotSequence>>16), byte(lotSequence>>8), byte(lotSequence)
+	return newIntermediateCode(passphrase, ownerEntropy, true)
+}
+
+// newIntermediateCode creates the intermediate code of the passphrase with the owner entropy
+func newIntermediateCode(passphrase string, ownerEntropy []byte, lotSequence bool) (string, error) {
+	passFactor, err := newPassFactor(passphrase, ownerEntropy, lotSequence)
+	if err != nil {
+		return "", err
+	}
+	defer bip39.Zero(passFactor)
+	_, passPoint := btcec.PrivKeyFromBytes(btcec.S256(), passFactor)
+
+	magic := magicNoLot
+	if lotSequence {
+		magic = magicLot
+	}
+	payload := make([]byte, 0, intermediateCodeLength)
+	payload = append(payload, magic...)
+	payload = append(payload, ownerEntropy...)
+	payload = append(payload, passPoint.SerializeCompressed()...)
+	return encode(payload), nil
+}
+
+// newPassFactor derives the passfactor of the passphrase and owner entropy, with the owner
+// salt of the first 4 bytes when the entropy has the lot and sequence numbers
+func newPassFactor(passphrase string, ownerEntropy []byte, lotSequence bool) ([]byte, error) {
+	ownerSalt := ownerEntropy
+	if lotSequence {
+		ownerSalt = ownerEntropy[:4]
+	}
+	preFactor, err := scrypt.Key(normalize(passphrase), ownerSalt, 16384, 8, 8, 32)
+	if err != nil {
+		return nil, err
+	}
+	if !lotSequence {
+		return preFactor, nil
+	}
+	defer bip39.Zero(preFactor)
+	return doubleSha256(append(append([]byte{}, preFactor...), ownerEntropy...)), nil
+}
+
+// EncryptWithIntermediateCode generates a new encrypted key from the intermediate code, by the
+// EC-multiply mode, reading the seedb from the random source. The private key is only known
+// by the owner of the intermediate code passphrase.
+// It returns the encrypted key (6P...), the address of the key and an error if occurs
+func EncryptWithIntermediateCode(random io.Reader, intermediateCode string, compressed bool, net *chaincfg.Params) (string, string, error) {
+	code, err := decode(intermediateCode, intermediateCodeLength)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %v", ErrInvalidIntermediateCode, err)
+	}
+	lotSequence := bytes.Equal(code[:8], magicLot)
+	if !lotSequence && !bytes.Equal(code[:8], magicNoLot) {
+		return "", "", fmt.Errorf("%w: unknown magic", ErrInvalidIntermediateCode)
+	}
+	ownerEntropy, passPointBytes := code[8:16], code[16:49]
+	passPoint, err := btcec.ParsePubKey(passPointBytes, btcec.S256())
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %v", ErrInvalidIntermediateCode, err)
+	}
+
+	seedB := make([]byte, 24)
+	if _, err := io.ReadFull(random, seedB); err != nil {
+		return "", "", err
+	}
+	defer bip39.Zero(seedB)
+	factorB := doubleSha256(seedB)
+	defer bip39.Zero(factorB)
+	if !isValidPrivateKey(factorB) {
+		return "", "", ErrInvalidFactor
+	}
+
+	// the generated public key is passpoint * factorb
+	x, y := btcec.S256().ScalarMult(passPoint.X, passPoint.Y, factorB)
+	address, err := addressOf(&btcec.PublicKey{Curve: btcec.S256(), X: x, Y: y}, compressed, net)
+	if err != nil {
+		return "", "", err
+	}
+	addressHash := hashAddress(address)
+
+	derived, err := scrypt.Key(passPointBytes, append(append([]byte{}, addressHash...), ownerEntropy...), 1024, 1, 1, 64)
+	if err != nil {
+		return "", "", err
+	}
+	defer bip39.Zero(derived)
+	part1 := encryptBlock(xor(seedB[:16], derived[:16]), derived[32:])
+	part2 := encryptBlock(xor(append(append([]byte{}, part1[8:]...), seedB[16:]...), derived[16:32]), derived[32:])
+
+	flag := byte(0)
+	if compressed {
+		flag |= flagCompressed
+	}
+	if lotSequence {
+		flag |= flagLotSequence
+	}
+	payload := make([]byte, 0, encryptedKeyLength)
+	payload = append(payload, prefixECMultiply...)
+	payload = append(payload, flag)
+	payload = append(payload, addressHash...)
+	payload = append(payload, ownerEntropy...)
+	payload = append(payload, part1[:8]...)
+	payload = append(payload, part2...)
+	return encode(payload), address, nil
+}
+
+// addressOf returns the pay-to-pubkey-hash address of the public key for the network
+func addressOf(pubk *btcec.PublicKey, compressed bool, net *chaincfg.Params) (string, error) {
+	serialized := pubk.SerializeUncompressed()
+	if compressed {
+		serialized = pubk.SerializeCompressed()
+	}
+	address, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(serialized), net)
+	if err != nil {
+		return "", err
+	}
+	return address.EncodeAddress(), nil
+}
+
+// hashAddress returns the address hash, the first 4 bytes of the double SHA256 of the address
+func hashAddress(address string) []byte {
+	return doubleSha256([]byte(address))[:4]
+}
+
+// doubleSha256 returns the SHA256 of the SHA256 of the data
+func doubleSha256(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+// normalize returns the passphrase normalized by the unicode NFC form
+func normalize(passphrase string) []byte {
+	return norm.NFC.Bytes([]byte(passphrase))
+}
+
+// encryptBlock encrypts the 16 bytes block with AES-256 (ECB, a single block)
+func encryptBlock(block, key []byte) []byte {
+	cipher, _ := aes.NewCipher(key)
+	result := make([]byte, aes.BlockSize)
+	cipher.Encrypt(result, block)
+	return result
+}
+
+// decryptBlock decrypts the 16 bytes block with AES-256 (ECB, a single block)
+func decryptBlock(block, key []byte) []byte {
+	cipher, _ := aes.NewCipher(key)
+	result := make([]byte, aes.BlockSize)
+	cipher.Decrypt(result, block)
+	return result
+}
+
+// xor returns a new slice with the XOR of a and b, of the length of a
+func xor(a, b []byte) []byte {
+	result := make([]byte, len(a))
+	for i := range a {
+		result[i] = a[i] ^ b[i]
+	}
+	return result
+}
+
+// encode encodes the payload with base58 and the double SHA256 checksum
+func encode(payload []byte) string {
+	return base58.Encode(append(payload, doubleSha256(payload)[:4]...))
+}
+
+// decode decodes the base58 payload checking the length and the checksum
+func decode(data string, length int) ([]byte, error) {
+	decoded := base58.Decode(data)
+	if len(decoded) != length+4 {
+		return nil, fmt.Errorf("invalid length %d", len(decoded))
+	}
+	payload, checksum := decoded[:length], decoded[length:]
+	if !bytes.Equal(doubleSha256(payload)[:4], checksum) {
+		return nil, errors.New("checksum doesn't match")
+	}
+	return payload, nil
+}
+
+// paddedKey returns the 32 bytes private key
+func paddedKey(privk *btcec.PrivateKey) []byte {
+	return paddedBytes(privk.D)
+}
+
+// paddedBytes returns the big-endian 32 bytes of the integer
+func paddedBytes(n *big.Int) []byte {
+	result := make([]byte, 32)
+	b := n.Bytes()
+	copy(result[32-len(b):], b)
+	bip39.Zero(b)
+	return result
+}
+
+// isValidPrivateKey returns true if the key is in the range [1, n-1] of the curve order
+func isValidPrivateKey(key []byte) bool {
+	k := new(big.Int).SetBytes(key)
+	return k.Sign() > 0 && k.Cmp(btcec.S256().N) < 0
+}
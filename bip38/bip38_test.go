@@ -0,0 +1,123 @@
+package bip38
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/stretchr/testify/assert"
+)
+
+// BIP38 test vectors, the passphrase code is the intermediate code of the EC-multiply vectors
+var testVectors = []struct {
+	name           string
+	passphrase     string
+	passphraseCode string
+	encrypted      string
+	wif            string
+	address        string
+}{
+	{name: "no compression, no EC multiply 1", passphrase: "TestingOneTwoThree", encrypted: "6PRVWUbkzzsbcVac2qwfssoUJAN1Xhrg6bNk8J7Nzm5H7kxEbn2Nh2ZoGg", wif: "5KN7MzqK5wt2TP1fQCYyHBtDrXdJuXbUzm4A9rKAteGu3Qi5CVR"},
+	{name: "no compression, no EC multiply 2", passphrase: "Satoshi", encrypted: "6PRNFFkZc2NZ6dJqFfhRoFNMR9Lnyj7dYGrzdgXXVMXcxoKTePPX1dWByq", wif: "5HtasZ6ofTHP6HCwTqTkLDuLQisYPah7aUnSKfC7h4hMUVw2gi5"},
+	{name: "compression, no EC multiply 1", passphrase: "TestingOneTwoThree", encrypted: "6PYNKZ1EAgYgmQfmNVamxyXVWHzK5s6DGhwP4J5o44cvXdoY7sRzhtpUeo", wif: "L44B5gGEpqEDRS9vVPz7QT35jcBG2r3CZwSwQ4fCewXAhAhqGVpP"},
+	{name: "compression, no EC multiply 2", passphrase: "Satoshi", encrypted: "6PYLtMnXvfG3oJde97zRyLYFZCYizPU5T3LwgdYJz1fRhh16bU7u6PPmY7", wif: "KwYgW8gcxj1JWJXhPSu4Fqwzfhp5Yfi42mdYmMa4XqK7NJxXUSK7"},
+	{name: "EC multiply, no lot 1", passphrase: "TestingOneTwoThree", passphraseCode: "passphrasepxFy57B9v8HtUsszJYKReoNDV6VHjUSGt8EVJmux9n1J3Ltf1gRxyDGXqnf9qm", encrypted: "6PfQu77ygVyJLZjfvMLyhLMQbYnu5uguoJJ4kMCLqWwPEdfpwANVS76gTX", wif: "5K4caxezwjGCGfnoPTZ8tMcJBLB7Jvyjv4xxeacadhq8nLisLR2", address: "1PE6TQi6HTVNz5DLwB1LcpMBALubfuN2z2"},
+	{name: "EC multiply, no lot 2", passphrase: "Satoshi", passphraseCode: "passphraseoRDGAXTWzbp72eVbtUDdn1rwpgPUGjNZEc6CGBo8i5EC1FPW8wcnLdq4ThKzAS", encrypted: "6PfLGnQs6VZnrNpmVKfjotbnQuaJK4KZoPFrAjx1JMJUa1Ft8gnf5WxfKd", wif: "5KJ51SgxWaAYR13zd9ReMhJpwrcX47xTJh2D3fGPG9CM8vkv5sH", address: "1CqzrtZC6mXSAhoxtFwVjz8LtwLJjDYU3V"},
+	{name: "EC multiply, lot 1", passphrase: "MOLON LABE", passphraseCode: "passphraseaB8feaLQDENqCgr4gKZpmf4VoaT6qdjJNJiv7fsKvjqavcJxvuR1hy25aTu5sX", encrypted: "6PgNBNNzDkKdhkT6uJntUXwwzQV8Rr2tZcbkDcuC9DZRsS6AtHts4Ypo1j", wif: "5JLdxTtcTHcfYcmJsNVy1v2PMDx432JPoYcBTVVRHpPaxUrdtf8", address: "1Jscj8ALrYu2y9TD8NrpvDBugPedmbj4Yh"},
+	{name: "EC multiply, lot 2", passphrase: "ΜΟΛΩΝ ΛΑΒΕ", passphraseCode: "passphrased3z9rQJHSyBkNBwTRPkUGNVEVrUAcfAXDyRU1V28ie6hNFbqDwbFBvsTK7yWVK", encrypted: "6PgGWtx25kUg8QWvwuJAgorN6k9FbE25rv5dMRwu5SKMnfpfVe5mar2ngH", wif: "5KMKKuUmAkiNbA3DazMQiLfDq47qs8MAEThm4yL8R2PhV1ov33D", address: "1Lurmih3KruL4xDB5FmHof38yawNtP9oGf"},
+}
+
+func TestDecrypt(t *testing.T) {
+	for _, tt := range testVectors {
+		t.Run(tt.name, func(t *testing.T) {
+			wif, err := Decrypt(tt.encrypted, tt.passphrase, &chaincfg.MainNetParams)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wif, wif.String())
+			if tt.address != "" {
+				address, err := addressOf(wif.PrivKey.PubKey(), wif.CompressPubKey, &chaincfg.MainNetParams)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.address, address)
+			}
+		})
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	for _, tt := range testVectors[:4] {
+		t.Run(tt.name, func(t *testing.T) {
+			wif, err := btcutil.DecodeWIF(tt.wif)
+			assert.NoError(t, err)
+			encrypted, err := Encrypt(wif, tt.passphrase, &chaincfg.MainNetParams)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.encrypted, encrypted)
+		})
+	}
+}
+
+func TestDecryptWrongPassphrase(t *testing.T) {
+	_, err := Decrypt(testVectors[2].encrypted, "wrong", &chaincfg.MainNetParams)
+	assert.Equal(t, ErrWrongPassphrase, err)
+	_, err = Decrypt(testVectors[4].encrypted, "wrong", &chaincfg.MainNetParams)
+	assert.Equal(t, ErrWrongPassphrase, err)
+
+	// the address hash depends on the network version
+	litecoin := chaincfg.MainNetParams
+	litecoin.PubKeyHashAddrID = 0x30
+	_, err = Decrypt(testVectors[2].encrypted, testVectors[2].passphrase, &litecoin)
+	assert.Equal(t, ErrWrongPassphrase, err)
+
+	for _, encrypted := range []string{"6PYNKZ1EAgYgmQfmNVamxyXVWHzK5s6DGhwP4J5o44cvXdoY7sRzhtpUep", "6PYNKZ1EAgYgmQfm", testVectors[4].passphraseCode} {
+		_, err = Decrypt(encrypted, "Satoshi", &chaincfg.MainNetParams)
+		assert.True(t, errors.Is(err, ErrInvalidEncryptedKey))
+	}
+}
+
+func TestNewIntermediateCode(t *testing.T) {
+	for _, tt := range testVectors[4:] {
+		t.Run(tt.name, func(t *testing.T) {
+			// the owner entropy of the vector passphrase code
+			decoded := base58.Decode(tt.passphraseCode)
+			ownerEntropy := decoded[8:16]
+			var code string
+			var err error
+			if bytes.Equal(decoded[:8], magicNoLot) {
+				code, err = NewIntermediateCode(bytes.NewReader(ownerEntropy), tt.passphrase)
+			} else {
+				lotSequence := uint32(ownerEntropy[4])<<24 | uint32(ownerEntropy[5])<<16 | uint32(ownerEntropy[6])<<8 | uint32(ownerEntropy[7])
+				code, err = NewIntermediateCodeWithLot(bytes.NewReader(ownerEntropy[:4]), tt.passphrase, lotSequence/4096, lotSequence%4096)
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.passphraseCode, code)
+		})
+	}
+
+	_, err := NewIntermediateCodeWithLot(rand.Reader, "Satoshi", maxLot+1, 0)
+	assert.Equal(t, ErrInvalidLotSequence, err)
+}
+
+func TestEncryptWithIntermediateCode(t *testing.T) {
+	for _, tt := range []int{4, 6} {
+		tt := testVectors[tt]
+		t.Run(tt.name, func(t *testing.T) {
+			for _, compressed := range []bool{false, true} {
+				encrypted, address, err := EncryptWithIntermediateCode(rand.Reader, tt.passphraseCode, compressed, &chaincfg.MainNetParams)
+				assert.NoError(t, err)
+				assert.Equal(t, "6P", encrypted[:2])
+
+				wif, err := Decrypt(encrypted, tt.passphrase, &chaincfg.MainNetParams)
+				assert.NoError(t, err)
+				assert.Equal(t, compressed, wif.CompressPubKey)
+				got, err := addressOf(wif.PrivKey.PubKey(), compressed, &chaincfg.MainNetParams)
+				assert.NoError(t, err)
+				assert.Equal(t, address, got)
+			}
+		})
+	}
+
+	_, _, err := EncryptWithIntermediateCode(rand.Reader, testVectors[0].encrypted, true, &chaincfg.MainNetParams)
+	assert.True(t, errors.Is(err, ErrInvalidIntermediateCode))
+}
@@ -0,0 +1,26 @@
+package pool_party
+
+import (
+	"testing"
+
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool_GenerateBIP38AddressPool(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Bitcoin, testMnemonic, testPassphrase)
+	addresses, err := pool.GenerateAddressPool(0, 1)
+	assert.NoError(t, err)
+	encrypted, err := pool.GenerateBIP38AddressPool(0, 1, testPassword)
+	assert.NoError(t, err)
+	assert.Len(t, encrypted, 1)
+	assert.Equal(t, addresses[0].Address, encrypted[0].Address)
+	assert.Equal(t, "6P", string(encrypted[0].Privkey[:2]))
+
+	wif, err := bip44.DecryptBIP38(bip44.Bitcoin, string(encrypted[0].Privkey), testPassword)
+	assert.NoError(t, err)
+	assert.Equal(t, string(addresses[0].Privkey), wif)
+
+	_, err = NewPoolWithSecret(bip44.Ethereum, testMnemonic, testPassphrase).GenerateBIP38AddressPool(0, 1, testPassword)
+	assert.Error(t, err)
+}
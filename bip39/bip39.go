@@ -65,6 +65,9 @@ var (
 
 	// ErrChecksumIncorrect is returned when entropy has the incorrect checksum.
 	ErrChecksumIncorrect = errors.New("checksum incorrect")
+
+	// ErrInvalidWordList is returned when the word list doesn't have 2048 words.
+	ErrInvalidWordList = errors.New("word list must have 2048 words")
 )
 
 func init() {
@@ -97,14 +100,7 @@ func GetWordIndex(word string) (int, bool) {
 //
 // bitSize has to be a multiple 32 and be within the inclusive range of {128, 256}
 func NewEntropy(bitSize int) ([]byte, error) {
-	err := validateEntropyBitSize(bitSize)
-	if err != nil {
-		return nil, err
-	}
-
-	entropy := make([]byte, bitSize/8)
-	_, err = rand.Read(entropy)
-	return entropy, err
+	return NewEntropyFromReader(rand.Reader, bitSize)
 }
 
 // EntropyFromMnemonic takes a mnemonic generated by this library,
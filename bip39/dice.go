@@ -0,0 +1,178 @@
+package bip39
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// chiSquaredD6 and chiSquaredCoin are the chi-squared critical values with p = 0.001
+	// for 5 and 1 degrees of freedom, the rolls are rejected as biased above them
+	chiSquaredD6   = 20.52
+	chiSquaredCoin = 10.83
+
+	// maxRunD6 and maxRunCoin are the longest runs of the same result accepted, longer
+	// runs have a probability below 1e-4 with the rolls needed for 256 bits
+	maxRunD6   = 8
+	maxRunCoin = 24
+)
+
+var (
+	// ErrNotEnoughRolls is returned when the dice rolls or coin flips don't have the
+	// bits of entropy requested
+	ErrNotEnoughRolls = errors.New("not enough rolls for the entropy size")
+
+	// ErrBiasedRolls is returned when the dice rolls or coin flips fail the statistical
+	// sanity checks, like a die always rolling six
+	ErrBiasedRolls = errors.New("rolls failed the randomness checks")
+)
+
+// EntropyFromDice converts the rolls of a six sided die (1 to 6) to bitSize bits of entropy.
+// Each roll of 1 to 4 adds two bits and each roll of 5 or 6 adds one bit, so the entropy has
+// no modulo bias and every roll adds 5/3 bits on average (77 rolls for 128 bits at least,
+// 154 for 256 bits). The rolls left after bitSize bits are ignored.
+// An error is returned if there are not enough rolls or if they fail the statistical checks.
+func EntropyFromDice(rolls string, bitSize int) ([]byte, error) {
+	if err := validateEntropyBitSize(bitSize); err != nil {
+		return nil, err
+	}
+	dice, err := DiceEntropy(rolls, 6)
+	if err != nil {
+		return nil, err
+	}
+	defer Zero(dice.Data)
+	if err := checkRolls(dice.Data, "123456", chiSquaredD6, maxRunD6); err != nil {
+		return nil, err
+	}
+
+	bits := newBitWriter(bitSize)
+	for _, roll := range dice.Data {
+		if bits.full() {
+			break
+		}
+		value := roll - '1'
+		if value < 4 {
+			bits.write(value>>1, value&1)
+		} else {
+			bits.write(value - 4)
+		}
+	}
+	if !bits.full() {
+		Zero(bits.data)
+		return nil, fmt.Errorf("%w: %d of %d bits", ErrNotEnoughRolls, bits.n, bitSize)
+	}
+	return bits.data, nil
+}
+
+// EntropyFromCoinFlips converts the coin flips, written as H (1) and T (0) or as 1 and 0,
+// to bitSize bits of entropy, one bit per flip. The flips after bitSize are ignored.
+// An error is returned if there are not enough flips or if they fail the statistical checks.
+func EntropyFromCoinFlips(flips string, bitSize int) ([]byte, error) {
+	if err := validateEntropyBitSize(bitSize); err != nil {
+		return nil, err
+	}
+	data := make([]byte, 0, len(flips))
+	defer func() { Zero(data) }()
+	for _, r := range strings.ToUpper(flips) {
+		switch r {
+		case ' ', ',':
+		case 'H', '1':
+			data = append(data, '1')
+		case 'T', '0':
+			data = append(data, '0')
+		default:
+			return nil, fmt.Errorf("%w: flip %q is not H, T, 1 or 0", ErrInvalidUserEntropy, r)
+		}
+	}
+	if len(data) < bitSize {
+		return nil, fmt.Errorf("%w: %d of %d bits", ErrNotEnoughRolls, len(data), bitSize)
+	}
+	if err := checkRolls(data, "01", chiSquaredCoin, maxRunCoin); err != nil {
+		return nil, err
+	}
+
+	bits := newBitWriter(bitSize)
+	for _, flip := range data[:bitSize] {
+		bits.write(flip - '0')
+	}
+	return bits.data, nil
+}
+
+// NewMnemonicFromDice generates the mnemonic of bitSize bits of entropy from six sided die rolls
+// It returns the mnemonic and an error if the rolls are invalid
+func NewMnemonicFromDice(rolls string, bitSize int) (string, error) {
+	entropy, err := EntropyFromDice(rolls, bitSize)
+	if err != nil {
+		return "", err
+	}
+	defer Zero(entropy)
+	return NewMnemonic(entropy)
+}
+
+// NewMnemonicFromCoinFlips generates the mnemonic of bitSize bits of entropy from coin flips
+// It returns the mnemonic and an error if the flips are invalid
+func NewMnemonicFromCoinFlips(flips string, bitSize int) (string, error) {
+	entropy, err := EntropyFromCoinFlips(flips, bitSize)
+	if err != nil {
+		return "", err
+	}
+	defer Zero(entropy)
+	return NewMnemonic(entropy)
+}
+
+// checkRolls runs the sanity checks of the rolls, a chi-squared test of the frequency of
+// each result and the longest run of the same result
+func checkRolls(rolls []byte, results string, maxChiSquared float64, maxRun int) error {
+	counts := make(map[byte]int, len(results))
+	run := 0
+	for i, roll := range rolls {
+		counts[roll]++
+		if i > 0 && roll == rolls[i-1] {
+			run++
+		} else {
+			run = 1
+		}
+		if run > maxRun {
+			return fmt.Errorf("%w: %d consecutive %c", ErrBiasedRolls, run, roll)
+		}
+	}
+
+	expected := float64(len(rolls)) / float64(len(results))
+	chiSquared := 0.0
+	for i := range results {
+		diff := float64(counts[results[i]]) - expected
+		chiSquared += diff * diff / expected
+	}
+	if chiSquared > maxChiSquared {
+		return fmt.Errorf("%w: chi-squared %.2f above %.2f", ErrBiasedRolls, chiSquared, maxChiSquared)
+	}
+	return nil
+}
+
+// bitWriter writes the entropy bits, most significant first
+type bitWriter struct {
+	data []byte
+	n    int
+}
+
+// newBitWriter creates the writer of size bits
+func newBitWriter(size int) *bitWriter {
+	return &bitWriter{data: make([]byte, size/8)}
+}
+
+// write appends the bits (0 or 1) while the writer isn't full
+func (w *bitWriter) write(bits ...byte) {
+	for _, bit := range bits {
+		if w.full() {
+			return
+		}
+		w.data[w.n/8] |= bit << (7 - uint(w.n%8))
+		w.n++
+	}
+}
+
+// full returns true if every bit was written
+func (w *bitWriter) full() bool {
+	return w.n == len(w.data)*8
+}
@@ -0,0 +1,86 @@
+package bip39
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntropyFromDice(t *testing.T) {
+	// 1-4 add two bits (00, 01, 10, 11) and 5-6 add one bit (0, 1)
+	rolls := strings.Repeat("123456", 30)
+	tests := []struct {
+		rolls   string
+		bitSize int
+		want    string
+	}{
+		{rolls: rolls[:76], bitSize: 128, want: "1b46d1b46d1b46d1b46d1b46d1b46d1b"},
+		{rolls: rolls, bitSize: 128, want: "1b46d1b46d1b46d1b46d1b46d1b46d1b"},
+		{rolls: rolls, bitSize: 256, want: "1b46d1b46d1b46d1b46d1b46d1b46d1b46d1b46d1b46d1b46d1b46d1b46d1b46"},
+	}
+	for _, tt := range tests {
+		entropy, err := EntropyFromDice(tt.rolls, tt.bitSize)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, hex.EncodeToString(entropy))
+
+		mnemonic, err := NewMnemonicFromDice(tt.rolls, tt.bitSize)
+		assert.NoError(t, err)
+		assert.True(t, IsMnemonicValid(mnemonic))
+		assert.Len(t, strings.Fields(mnemonic), tt.bitSize/32*3)
+	}
+}
+
+func TestEntropyFromDiceInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		rolls   string
+		bitSize int
+		err     error
+	}{
+		{name: "not enough rolls", rolls: strings.Repeat("123456", 13)[:75], bitSize: 128, err: ErrNotEnoughRolls},
+		{name: "not enough rolls for 256 bits", rolls: strings.Repeat("123456", 13), bitSize: 256, err: ErrNotEnoughRolls},
+		{name: "long run", rolls: strings.Repeat("123456", 10) + "666666666" + strings.Repeat("123456", 10), bitSize: 128, err: ErrBiasedRolls},
+		{name: "loaded die", rolls: strings.Repeat("6162", 40), bitSize: 128, err: ErrBiasedRolls},
+		{name: "invalid roll", rolls: strings.Repeat("123457", 20), bitSize: 128, err: ErrInvalidUserEntropy},
+		{name: "invalid bit size", rolls: strings.Repeat("123456", 30), bitSize: 100, err: ErrEntropyLengthInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := EntropyFromDice(tt.rolls, tt.bitSize)
+			assert.True(t, errors.Is(err, tt.err), "got %v", err)
+			_, err = NewMnemonicFromDice(tt.rolls, tt.bitSize)
+			assert.True(t, errors.Is(err, tt.err), "got %v", err)
+		})
+	}
+}
+
+func TestEntropyFromCoinFlips(t *testing.T) {
+	entropy, err := EntropyFromCoinFlips(strings.Repeat("H t, ", 64), 128)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("aa", 16), hex.EncodeToString(entropy))
+
+	entropy, err = EntropyFromCoinFlips(strings.Repeat("0011", 70), 256)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("33", 32), hex.EncodeToString(entropy))
+
+	mnemonic, err := NewMnemonicFromCoinFlips(strings.Repeat("10", 64), 128)
+	assert.NoError(t, err)
+	assert.True(t, IsMnemonicValid(mnemonic))
+
+	tests := []struct {
+		flips string
+		err   error
+	}{
+		{flips: strings.Repeat("HT", 63), err: ErrNotEnoughRolls},
+		{flips: strings.Repeat("HHT", 64), err: ErrBiasedRolls},
+		{flips: strings.Repeat("HT", 40) + strings.Repeat("H", 25) + strings.Repeat("TH", 40), err: ErrBiasedRolls},
+		{flips: strings.Repeat("HX", 64), err: ErrInvalidUserEntropy},
+	}
+	for _, tt := range tests {
+		_, err := EntropyFromCoinFlips(tt.flips, 128)
+		assert.True(t, errors.Is(err, tt.err), "got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package bip39
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// MixMode is the function used to combine the user entropy with the system randomness
+type MixMode int
+
+const (
+	// MixXOR XORs the system entropy with the SHA256 hash of the user entropy
+	MixXOR MixMode = iota
+
+	// MixHash hashes the system entropy together with the user entropy, SHA256(system || user)
+	MixHash
+)
+
+// cardRanks and cardSuits are the symbols of the cards of a 52 cards deck, like AS or TD
+const (
+	cardRanks = "A23456789TJQK"
+	cardSuits = "SHDC"
+)
+
+var (
+	// ErrInvalidUserEntropy is returned when parsing malformed dice rolls or cards
+	ErrInvalidUserEntropy = errors.New("invalid user entropy")
+
+	// ErrInvalidMixMode is returned when mixing the entropy with an unknown mode
+	ErrInvalidMixMode = errors.New("invalid entropy mix mode")
+)
+
+// UserEntropy represents the entropy supplied by the user, like dice rolls or a card
+// shuffle, and the estimate of its bits of entropy
+type UserEntropy struct {
+	Data []byte  // normalized user input
+	Bits float64 // estimated bits of entropy, assuming fair dice and a well shuffled deck
+}
+
+// NewEntropyFromReader reads the entropy bytes from the reader, like crypto/rand.Reader
+// or a hardware RNG, so long as the requested size bitSize is an appropriate size.
+//
+// bitSize has to be a multiple 32 and be within the inclusive range of {128, 256}
+func NewEntropyFromReader(r io.Reader, bitSize int) ([]byte, error) {
+	if err := validateEntropyBitSize(bitSize); err != nil {
+		return nil, err
+	}
+	entropy := make([]byte, bitSize/8)
+	if _, err := io.ReadFull(r, entropy); err != nil {
+		Zero(entropy)
+		return nil, err
+	}
+	return entropy, nil
+}
+
+// DiceEntropy parses the rolls of a die with the number of sides, written as the digits
+// 1 to sides (eg "3615242..." for a six sided die), each one adding log2(sides) bits
+func DiceEntropy(rolls string, sides int) (*UserEntropy, error) {
+	if sides < 2 || sides > 9 {
+		return nil, fmt.Errorf("%w: dice sides must be between 2 and 9", ErrInvalidUserEntropy)
+	}
+	data := make([]byte, 0, len(rolls))
+	for _, r := range rolls {
+		switch {
+		case r == ' ' || r == ',':
+			continue
+		case r < '1' || r > rune('0'+sides):
+			return nil, fmt.Errorf("%w: roll %q is not between 1 and %d", ErrInvalidUserEntropy, r, sides)
+		}
+		data = append(data, byte(r))
+	}
+	return &UserEntropy{Data: data, Bits: float64(len(data)) * math.Log2(float64(sides))}, nil
+}
+
+// CardEntropy parses the cards of a shuffled 52 cards deck, written as the rank (A23456789TJQK)
+// and the suit (SHDC) like "AS 7D TC ...". The n cards add log2(52!/(52-n)!) bits, so a full
+// shuffle adds about 225 bits.
+func CardEntropy(cards string) (*UserEntropy, error) {
+	cards = strings.ToUpper(strings.Join(strings.Fields(cards), ""))
+	if len(cards)%2 != 0 {
+		return nil, fmt.Errorf("%w: incomplete card %q", ErrInvalidUserEntropy, cards[len(cards)-1:])
+	}
+	seen := make(map[string]bool, len(cards)/2)
+	bits := 0.0
+	for i := 0; i < len(cards); i += 2 {
+		card := cards[i : i+2]
+		if !strings.ContainsRune(cardRanks, rune(card[0])) || !strings.ContainsRune(cardSuits, rune(card[1])) {
+			return nil, fmt.Errorf("%w: invalid card %q", ErrInvalidUserEntropy, card)
+		}
+		if seen[card] {
+			return nil, fmt.Errorf("%w: duplicated card %q", ErrInvalidUserEntropy, card)
+		}
+		seen[card] = true
+		bits += math.Log2(float64(52 - len(seen) + 1))
+	}
+	return &UserEntropy{Data: []byte(cards), Bits: bits}, nil
+}
+
+// MixEntropy reads bitSize bits of system entropy from the reader and mixes them with the
+// user entropy, so the result is as strong as the best of both sources. A nil user entropy
+// returns the system entropy.
+// It returns the entropy, the bits contributed by the user entropy (up to bitSize) and an error if occurs
+func MixEntropy(r io.Reader, bitSize int, user *UserEntropy, mode MixMode) ([]byte, int, error) {
+	if mode != MixXOR && mode != MixHash {
+		return nil, 0, ErrInvalidMixMode
+	}
+	entropy, err := NewEntropyFromReader(r, bitSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	if user == nil || len(user.Data) == 0 {
+		return entropy, 0, nil
+	}
+
+	switch mode {
+	case MixXOR:
+		digest := sha256.Sum256(user.Data)
+		for i := range entropy {
+			entropy[i] ^= digest[i]
+		}
+		Zero(digest[:])
+	case MixHash:
+		h := sha256.New()
+		h.Write(entropy)
+		h.Write(user.Data)
+		digest := h.Sum(nil)
+		copy(entropy, digest)
+		Zero(digest)
+	}
+
+	bits := int(math.Min(math.Floor(user.Bits), float64(bitSize)))
+	return entropy, bits, nil
+}
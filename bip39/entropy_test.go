@@ -0,0 +1,100 @@
+package bip39
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fullDeck is a full 52 cards deck in order
+const fullDeck = "AS 2S 3S 4S 5S 6S 7S 8S 9S TS JS QS KS AH 2H 3H 4H 5H 6H 7H 8H 9H TH JH QH KH " +
+	"AD 2D 3D 4D 5D 6D 7D 8D 9D TD JD QD KD AC 2C 3C 4C 5C 6C 7C 8C 9C TC JC QC KC"
+
+func TestNewEntropyFromReader(t *testing.T) {
+	source := bytes.Repeat([]byte{0x7f}, 32)
+	entropy, err := NewEntropyFromReader(bytes.NewReader(source), 256)
+	assert.NoError(t, err)
+	assert.Equal(t, source, entropy)
+
+	mnemonic, err := NewMnemonic(entropy)
+	assert.NoError(t, err)
+	assert.Equal(t, "legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title", mnemonic)
+
+	_, err = NewEntropyFromReader(bytes.NewReader(source[:15]), 128)
+	assert.Error(t, err)
+	_, err = NewEntropyFromReader(bytes.NewReader(source), 120)
+	assert.Equal(t, ErrEntropyLengthInvalid, err)
+}
+
+func TestDiceEntropy(t *testing.T) {
+	user, err := DiceEntropy("1234 56,61", 6)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("1234566"+"1"), user.Data)
+	assert.InDelta(t, 20.68, user.Bits, 0.01)
+
+	user, err = DiceEntropy("1221", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 4.0, user.Bits)
+
+	for _, tt := range []struct {
+		rolls string
+		sides int
+	}{{"1237", 6}, {"0123", 6}, {"12a", 6}, {"11", 1}, {"11", 10}} {
+		_, err := DiceEntropy(tt.rolls, tt.sides)
+		assert.True(t, errors.Is(err, ErrInvalidUserEntropy), tt.rolls)
+	}
+}
+
+func TestCardEntropy(t *testing.T) {
+	user, err := CardEntropy(strings.ToLower(fullDeck))
+	assert.NoError(t, err)
+	assert.Equal(t, strings.ReplaceAll(fullDeck, " ", ""), string(user.Data))
+	assert.InDelta(t, 225.58, user.Bits, 0.01)
+
+	user, err = CardEntropy("AS KD")
+	assert.NoError(t, err)
+	assert.InDelta(t, 11.37, user.Bits, 0.01)
+
+	for _, cards := range []string{"AS KD A", "AS 1D", "AS XX", "AS KD AS"} {
+		_, err := CardEntropy(cards)
+		assert.True(t, errors.Is(err, ErrInvalidUserEntropy), cards)
+	}
+}
+
+func TestMixEntropy(t *testing.T) {
+	system := bytes.Repeat([]byte{0x01}, 16)
+	user, err := CardEntropy(fullDeck)
+	assert.NoError(t, err)
+
+	entropy, bits, err := MixEntropy(bytes.NewReader(system), 128, user, MixXOR)
+	assert.NoError(t, err)
+	assert.Equal(t, 128, bits)
+	digest := sha256.Sum256(user.Data)
+	for i := range entropy {
+		assert.Equal(t, system[i]^digest[i], entropy[i])
+	}
+
+	entropy, bits, err = MixEntropy(bytes.NewReader(system), 128, user, MixHash)
+	assert.NoError(t, err)
+	assert.Equal(t, 128, bits)
+	digest = sha256.Sum256(append(append([]byte{}, system...), user.Data...))
+	assert.Equal(t, digest[:16], entropy)
+
+	dice, err := DiceEntropy("123456", 6)
+	assert.NoError(t, err)
+	_, bits, err = MixEntropy(bytes.NewReader(system), 128, dice, MixXOR)
+	assert.NoError(t, err)
+	assert.Equal(t, 15, bits)
+
+	entropy, bits, err = MixEntropy(bytes.NewReader(system), 128, nil, MixHash)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, bits)
+	assert.Equal(t, system, entropy)
+
+	_, _, err = MixEntropy(bytes.NewReader(system), 128, user, MixMode(5))
+	assert.Equal(t, ErrInvalidMixMode, err)
+}
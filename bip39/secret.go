@@ -0,0 +1,131 @@
+package bip39
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// maxWordLength is the length of the longest word of the english word list, plus the separator,
+// the words of other languages may take up to 4 times more bytes in UTF-8
+const maxWordLength = 9
+
+// NewMnemonicBytes returns the mnemonic words for the given entropy as a byte slice,
+// so the caller can zero the mnemonic after use.
+// If the provide entropy is invalid, an error will be returned.
+func NewMnemonicBytes(entropy []byte) ([]byte, error) {
+	return NewMnemonicBytesWithWordList(entropy, wordList, " ")
+}
+
+// NewMnemonicBytesWithWordList is like NewMnemonicBytes, but the mnemonic words are taken from the
+// word list of 2048 words, joined by the separator (eg the ideographic space for Japanese),
+// without changing the package word list.
+func NewMnemonicBytesWithWordList(entropy []byte, list []string, separator string) ([]byte, error) {
+	if len(list) != 2048 {
+		return nil, ErrInvalidWordList
+	}
+	entropyBitLength := len(entropy) * 8
+	if err := validateEntropyBitSize(entropyBitLength); err != nil {
+		return nil, err
+	}
+	sentenceLength := (entropyBitLength + entropyBitLength/32) / 11
+
+	// entropy followed by the checksum bits
+	data := make([]byte, len(entropy)+1)
+	defer Zero(data)
+	copy(data, entropy)
+	hash := sha256.Sum256(entropy)
+	data[len(entropy)] = hash[0]
+	Zero(hash[:])
+
+	// preallocate the mnemonic, so the buffer is never copied when growing
+	mnemonic := make([]byte, 0, sentenceLength*maxWordLength*4)
+	for i := 0; i < sentenceLength; i++ {
+		if i > 0 {
+			mnemonic = append(mnemonic, separator...)
+		}
+		mnemonic = append(mnemonic, list[readBits(data, i*11, 11)]...)
+	}
+	return mnemonic, nil
+}
+
+// NewSeedBytes creates the seed from the mnemonic and password byte slices, validating
+// the mnemonic words and checksum, without copying the secrets to strings.
+// An error is returned if the mnemonic is invalid.
+func NewSeedBytes(mnemonic, password []byte) ([]byte, error) {
+	if err := validateMnemonicBytes(mnemonic); err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 0, len("mnemonic")+len(password))
+	salt = append(salt, "mnemonic"...)
+	salt = append(salt, password...)
+	defer Zero(salt)
+	return pbkdf2.Key(mnemonic, salt, 2048, 64, sha512.New), nil
+}
+
+// Zero overwrites the buffer with zeros
+func Zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// EntropyFromMnemonicBytes takes the mnemonic byte slice and returns its entropy, validating
+// the words and checksum without copying the mnemonic to a string.
+// An error is returned if the mnemonic is invalid.
+func EntropyFromMnemonicBytes(mnemonic []byte) ([]byte, error) {
+	return entropyFromWords(bytes.Fields(mnemonic))
+}
+
+// validateMnemonicBytes checks the mnemonic is formed by words of the word list
+// separated by single spaces, and the checksum is correct
+func validateMnemonicBytes(mnemonic []byte) error {
+	entropy, err := entropyFromWords(bytes.Split(mnemonic, []byte(" ")))
+	Zero(entropy)
+	return err
+}
+
+// entropyFromWords returns the entropy of the mnemonic words, checking the checksum
+func entropyFromWords(words [][]byte) ([]byte, error) {
+	if len(words)%3 != 0 || len(words) < 12 || len(words) > 24 {
+		return nil, ErrInvalidMnemonic
+	}
+
+	// pack the 11 bits word indexes, the entropy followed by the checksum
+	data := make([]byte, (len(words)*11+7)/8)
+	defer Zero(data)
+	for i, word := range words {
+		index, ok := wordMap[string(word)]
+		if !ok {
+			return nil, ErrInvalidMnemonic
+		}
+		for bit := 0; bit < 11; bit++ {
+			if index&(1<<uint(10-bit)) != 0 {
+				pos := i*11 + bit
+				data[pos/8] |= 1 << uint(7-pos%8)
+			}
+		}
+	}
+
+	entropyLength := len(words) / 3 * 4
+	checksumBits := len(words) / 3
+	hash := sha256.Sum256(data[:entropyLength])
+	defer Zero(hash[:])
+	if readBits(data, entropyLength*8, checksumBits) != readBits(hash[:], 0, checksumBits) {
+		return nil, ErrChecksumIncorrect
+	}
+	entropy := make([]byte, entropyLength)
+	copy(entropy, data)
+	return entropy, nil
+}
+
+// readBits reads count bits (up to 16) of the data starting by the bit offset
+func readBits(data []byte, offset, count int) int {
+	value := 0
+	for bit := offset; bit < offset+count; bit++ {
+		value = value<<1 | int(data[bit/8]>>uint(7-bit%8)&1)
+	}
+	return value
+}
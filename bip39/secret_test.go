@@ -0,0 +1,59 @@
+package bip39
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMnemonicBytes(t *testing.T) {
+	for _, vector := range testVectors() {
+		entropy, err := hex.DecodeString(vector.entropy)
+		assert.NoError(t, err)
+
+		mnemonic, err := NewMnemonicBytes(entropy)
+		assert.NoError(t, err)
+		assert.Equal(t, vector.mnemonic, string(mnemonic))
+
+		seed, err := NewSeedBytes(mnemonic, []byte("TREZOR"))
+		assert.NoError(t, err)
+		assert.Equal(t, vector.seed, hex.EncodeToString(seed))
+	}
+
+	_, err := NewMnemonicBytes([]byte{1, 2, 3})
+	assert.Equal(t, ErrEntropyLengthInvalid, err)
+}
+
+func TestNewSeedBytesInvalidMnemonics(t *testing.T) {
+	for _, mnemonic := range []string{
+		"",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon  about",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon aboot",
+		"Abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+	} {
+		_, err := NewSeedBytes([]byte(mnemonic), nil)
+		assert.Error(t, err, mnemonic)
+	}
+}
+
+func TestZero(t *testing.T) {
+	secret := []byte("secret")
+	Zero(secret)
+	assert.Equal(t, bytes.Repeat([]byte{0}, 6), secret)
+}
+
+func TestEntropyFromMnemonicBytes(t *testing.T) {
+	for _, vector := range testVectors() {
+		entropy, err := EntropyFromMnemonicBytes([]byte(vector.mnemonic))
+		assert.NoError(t, err)
+		assert.Equal(t, vector.entropy, hex.EncodeToString(entropy))
+	}
+	_, err := EntropyFromMnemonicBytes([]byte("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon yellow"))
+	assert.Equal(t, ErrChecksumIncorrect, err)
+	_, err = EntropyFromMnemonicBytes([]byte("abandon abandon abandon"))
+	assert.Equal(t, ErrInvalidMnemonic, err)
+}
@@ -0,0 +1,121 @@
+package bip39
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrInvalidXORParts is returned when splitting in less than 2 parts or combining
+	// mnemonics of different lengths
+	ErrInvalidXORParts = errors.New("invalid seed xor parts")
+)
+
+// SplitMnemonicXOR splits the 12, 18 or 24 words mnemonic in parts valid mnemonics of the same
+// length, like the Coldcard Seed XOR. The entropies of the first parts-1 mnemonics are read from
+// the reader, like crypto/rand.Reader, and the entropy of the last one is the XOR of them with the
+// mnemonic entropy, so every part is needed to combine them back.
+// It returns the mnemonics of the parts and an error if occurs
+func SplitMnemonicXOR(r io.Reader, mnemonic string, parts int) ([]string, error) {
+	split, err := SplitMnemonicXORBytes(r, []byte(mnemonic), parts)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(split))
+	for _, part := range split {
+		result = append(result, string(part))
+		Zero(part)
+	}
+	return result, nil
+}
+
+// SplitMnemonicXORBytes is like SplitMnemonicXOR, but the mnemonic and the parts are byte
+// slices, so the caller can zero them after use.
+// It returns the mnemonics of the parts and an error if occurs
+func SplitMnemonicXORBytes(r io.Reader, mnemonic []byte, parts int) ([][]byte, error) {
+	if parts < 2 {
+		return nil, fmt.Errorf("%w: %d parts", ErrInvalidXORParts, parts)
+	}
+	entropy, err := xorEntropy(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	defer Zero(entropy)
+
+	result := make([][]byte, 0, parts)
+	part := make([]byte, len(entropy))
+	defer Zero(part)
+	for i := 0; i < parts-1; i++ {
+		if _, err := io.ReadFull(r, part); err != nil {
+			zeroParts(result)
+			return nil, err
+		}
+		for k := range entropy {
+			entropy[k] ^= part[k]
+		}
+		words, err := NewMnemonicBytes(part)
+		if err != nil {
+			zeroParts(result)
+			return nil, err
+		}
+		result = append(result, words)
+	}
+	last, err := NewMnemonicBytes(entropy)
+	if err != nil {
+		zeroParts(result)
+		return nil, err
+	}
+	return append(result, last), nil
+}
+
+// CombineMnemonicXOR combines the Seed XOR parts, XORing the entropies of the mnemonics
+// It returns the original mnemonic and an error if occurs
+func CombineMnemonicXOR(parts []string) (string, error) {
+	if len(parts) < 2 {
+		return "", fmt.Errorf("%w: %d parts", ErrInvalidXORParts, len(parts))
+	}
+	var result []byte
+	for i, part := range parts {
+		entropy, err := xorEntropy([]byte(part))
+		if err != nil {
+			Zero(result)
+			return "", fmt.Errorf("%w: part %d", err, i+1)
+		}
+		if result == nil {
+			result = entropy
+			continue
+		}
+		if len(entropy) != len(result) {
+			Zero(result)
+			Zero(entropy)
+			return "", fmt.Errorf("%w: parts have different lengths", ErrInvalidXORParts)
+		}
+		for k := range result {
+			result[k] ^= entropy[k]
+		}
+		Zero(entropy)
+	}
+	defer Zero(result)
+	return NewMnemonic(result)
+}
+
+// xorEntropy returns the entropy of the 12, 18 or 24 words mnemonic
+func xorEntropy(mnemonic []byte) ([]byte, error) {
+	entropy, err := EntropyFromMnemonicBytes(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	if size := len(entropy); size != 16 && size != 24 && size != 32 {
+		Zero(entropy)
+		return nil, fmt.Errorf("%w: %d words", ErrInvalidXORParts, size*3/4)
+	}
+	return entropy, nil
+}
+
+// zeroParts zeroes the mnemonics of the parts
+func zeroParts(parts [][]byte) {
+	for _, part := range parts {
+		Zero(part)
+	}
+}
@@ -0,0 +1,76 @@
+package bip39
+
+import (
+	"bytes"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombineMnemonicXOR(t *testing.T) {
+	// the Coldcard Seed XOR example
+	parts := []string{
+		"romance wink lottery autumn shop bring dawn tongue range crater truth ability miss spice fitness easy legal release recall obey exchange recycle dragon room",
+		"lion misery divide hurry latin fluid camp advance illegal lab pyramid unaware eager fringe sick camera series noodle toy crowd jeans select depth lounge",
+		"vault nominee cradle silk own frown throw leg cactus recall talent worry gadget surface shy planet purpose coffee drip few seven term squeeze educate",
+	}
+	mnemonic, err := CombineMnemonicXOR(parts)
+	assert.NoError(t, err)
+	assert.Equal(t, "silent toe meat possible chair blossom wait occur this worth option bag nurse find fish scene bench asthma bike wage world quit primary indoor", mnemonic)
+}
+
+func TestSplitMnemonicXOR(t *testing.T) {
+	for _, bitSize := range []int{128, 192, 256} {
+		entropy, err := NewEntropy(bitSize)
+		assert.NoError(t, err)
+		mnemonic, err := NewMnemonic(entropy)
+		assert.NoError(t, err)
+
+		parts, err := SplitMnemonicXOR(rand.Reader, mnemonic, 3)
+		assert.NoError(t, err)
+		assert.Len(t, parts, 3)
+		for _, part := range parts {
+			assert.True(t, IsMnemonicValid(part))
+			assert.Len(t, strings.Fields(part), bitSize*3/32)
+			assert.NotEqual(t, mnemonic, part)
+		}
+		combined, err := CombineMnemonicXOR(parts)
+		assert.NoError(t, err)
+		assert.Equal(t, mnemonic, combined)
+
+		// every part is needed
+		combined, err = CombineMnemonicXOR(parts[:2])
+		assert.NoError(t, err)
+		assert.NotEqual(t, mnemonic, combined)
+	}
+}
+
+func TestSplitMnemonicXORBytes(t *testing.T) {
+	mnemonic := []byte("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	parts, err := SplitMnemonicXORBytes(rand.Reader, mnemonic, 2)
+	assert.NoError(t, err)
+	assert.Len(t, parts, 2)
+	combined, err := CombineMnemonicXOR([]string{string(parts[0]), string(parts[1])})
+	assert.NoError(t, err)
+	assert.Equal(t, string(mnemonic), combined)
+}
+
+func TestSplitMnemonicXORInvalid(t *testing.T) {
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	_, err := SplitMnemonicXOR(rand.Reader, mnemonic, 1)
+	assert.ErrorIs(t, err, ErrInvalidXORParts)
+	_, err = SplitMnemonicXOR(bytes.NewReader(nil), mnemonic, 2)
+	assert.Error(t, err)
+	_, err = SplitMnemonicXOR(rand.Reader, "abandon abandon abandon", 2)
+	assert.Error(t, err)
+	// 15 words
+	_, err = SplitMnemonicXOR(rand.Reader, "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon address", 2)
+	assert.ErrorIs(t, err, ErrInvalidXORParts)
+
+	_, err = CombineMnemonicXOR([]string{mnemonic})
+	assert.ErrorIs(t, err, ErrInvalidXORParts)
+	_, err = CombineMnemonicXOR([]string{mnemonic, "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote"})
+	assert.ErrorIs(t, err, ErrInvalidXORParts)
+}
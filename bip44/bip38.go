@@ -0,0 +1,82 @@
+package bip44
+
+import (
+	"crypto/rand"
+
+	"github.com/Pantani/errors"
+	"github.com/Pantani/pool-party/bip38"
+	"github.com/btcsuite/btcutil"
+)
+
+// BIP38 encrypts the address private key with the passphrase by the BIP38 non-EC-multiply mode,
+// for paper backups. Only the UTXO coins are supported, with the address version of the coin.
+// It returns the encrypted key (6P...) and an error if occurs
+func (a Address) BIP38(coin Coin, passphrase string) (string, error) {
+	altcoin, err := bip38Coin(coin)
+	if err != nil {
+		return "", err
+	}
+	wif, err := btcutil.DecodeWIF(string(a.Privkey))
+	if err != nil || !wif.IsForNet(altcoin.Params()) {
+		return "", errors.E("invalid address private key", errors.Params{"address": a.Address, "coin": coin})
+	}
+	defer WipePrivateKey(wif.PrivKey)
+	encrypted, err := bip38.Encrypt(wif, passphrase, altcoin.Params())
+	if err != nil {
+		return "", errors.E(err, "error to encrypt the private key", errors.Params{"address": a.Address})
+	}
+	return encrypted, nil
+}
+
+// BIP38 encrypts all addresses private keys with the passphrase by the BIP38 non-EC-multiply mode
+// It returns the encrypted keys in the same order of the addresses and an error if occurs
+func (addrs Addresses) BIP38(coin Coin, passphrase string) ([]string, error) {
+	result := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		encrypted, err := addr.BIP38(coin, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, encrypted)
+	}
+	return result, nil
+}
+
+// DecryptBIP38 decrypts the BIP38 encrypted key of both modes with the passphrase
+// It returns the private key in the wallet import format of the coin and an error if occurs
+func DecryptBIP38(coin Coin, encrypted, passphrase string) (string, error) {
+	altcoin, err := bip38Coin(coin)
+	if err != nil {
+		return "", err
+	}
+	wif, err := bip38.Decrypt(encrypted, passphrase, altcoin.Params())
+	if err != nil {
+		return "", errors.E(err, "error to decrypt the private key", errors.Params{"coin": coin})
+	}
+	defer WipePrivateKey(wif.PrivKey)
+	return wif.String(), nil
+}
+
+// NewBIP38Address generates a new address from the intermediate code of the passphrase owner, by the
+// BIP38 EC-multiply mode. The address private key is the encrypted key, only the owner can decrypt it.
+// It returns the address and an error if occurs
+func NewBIP38Address(coin Coin, intermediateCode string, compressed bool) (Address, error) {
+	altcoin, err := bip38Coin(coin)
+	if err != nil {
+		return Address{}, err
+	}
+	encrypted, address, err := bip38.EncryptWithIntermediateCode(rand.Reader, intermediateCode, compressed, altcoin.Params())
+	if err != nil {
+		return Address{}, errors.E(err, "error to generate the encrypted key", errors.Params{"coin": coin})
+	}
+	return Address{Address: address, Privkey: []byte(encrypted)}, nil
+}
+
+// bip38Coin returns the UTXO coin, BIP38 isn't supported by the Ethereum based coins
+func bip38Coin(coin Coin) (*Altcoin, error) {
+	altcoin, ok := CoinList[coin]
+	if !ok || altcoin.IsEthereum() {
+		return nil, errors.E("BIP38 is only supported for UTXO coins", errors.Params{"coin": coin})
+	}
+	return altcoin, nil
+}
@@ -0,0 +1,48 @@
+package bip44
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddress_BIP38(t *testing.T) {
+	// non-EC-multiply vector of the BIP38 spec, compressed key
+	addr := Address{Address: "164MQi977u9GUteHr4EPH27VkkdxmfCvGW", Privkey: []byte("L44B5gGEpqEDRS9vVPz7QT35jcBG2r3CZwSwQ4fCewXAhAhqGVpP")}
+	encrypted, err := addr.BIP38(Bitcoin, "TestingOneTwoThree")
+	assert.NoError(t, err)
+	assert.Equal(t, "6PYNKZ1EAgYgmQfmNVamxyXVWHzK5s6DGhwP4J5o44cvXdoY7sRzhtpUeo", encrypted)
+
+	wif, err := DecryptBIP38(Bitcoin, encrypted, "TestingOneTwoThree")
+	assert.NoError(t, err)
+	assert.Equal(t, string(addr.Privkey), wif)
+
+	_, err = DecryptBIP38(Bitcoin, encrypted, "wrong")
+	assert.Error(t, err)
+	_, err = addr.BIP38(Litecoin, "TestingOneTwoThree")
+	assert.Error(t, err)
+	_, err = addr.BIP38(Ethereum, "TestingOneTwoThree")
+	assert.Error(t, err)
+}
+
+func TestNewBIP38Address(t *testing.T) {
+	// intermediate code of the EC-multiply vector of the BIP38 spec, passphrase TestingOneTwoThree
+	const code = "passphrasepxFy57B9v8HtUsszJYKReoNDV6VHjUSGt8EVJmux9n1J3Ltf1gRxyDGXqnf9qm"
+	addr, err := NewBIP38Address(Bitcoin, code, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "6P", string(addr.Privkey[:2]))
+
+	wif, err := DecryptBIP38(Bitcoin, string(addr.Privkey), "TestingOneTwoThree")
+	assert.NoError(t, err)
+	key, err := btcutil.DecodeWIF(wif)
+	assert.NoError(t, err)
+	address, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(key.SerializePubKey()), CoinList[Bitcoin].Params())
+	assert.NoError(t, err)
+	assert.Equal(t, addr.Address, address.EncodeAddress())
+
+	_, err = NewBIP38Address(Ethereum, code, false)
+	assert.Error(t, err)
+	_, err = NewBIP38Address(Bitcoin, "passphrase", false)
+	assert.Error(t, err)
+}
@@ -1,185 +1,336 @@
 package bip44
 
 import (
+	"context"
+	"encoding/binary"
 	"encoding/hex"
-	"github.com/Pantani/pool-party/bip39"
 
 	"github.com/Pantani/errors"
 	log "github.com/Pantani/logger"
+	"github.com/Pantani/pool-party/bip32"
+	"github.com/Pantani/pool-party/bip39"
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcutil"
-	"github.com/btcsuite/btcutil/hdkeychain"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
+const (
+	// minSeedBytes is the minimum number of bytes allowed for a seed to a master node.
+	minSeedBytes = 16 // 128 bits
+
+	// maxSeedBytes is the maximum number of bytes allowed for a seed to a master node.
+	maxSeedBytes = 64 // 512 bits
+)
+
 // bip44 creates a bip44 with count receive addresses, based on pkb (bip39 key)
 // m/44'/cointype'/0'/0/i
 func bip44(coin *Altcoin, start, qty int, pkb []byte) (*Account, error) {
-	account := &Account{Coin: coin.Name, CoinType: coin.CoinType}
-	net := &chaincfg.MainNetParams
-
-	// cointype as specified in https://github.com/satoshilabs/slips/blob/master/slip-0044.md
-	net.PubKeyHashAddrID = coin.PubKeyHashAddrID
-	net.PrivateKeyID = coin.PrivateKeyID
+	return bip44WithPath(context.Background(), coin, DefaultPathTemplate, 0, start, qty, pkb)
+}
 
+// bip44WithPath creates an account with count receive addresses derived from the
+// path template, based on pkb (bip39 key). The context is checked between indexes,
+// if it's done the account is returned with the addresses derived so far and the context error
+func bip44WithPath(ctx context.Context, coin *Altcoin, template PathTemplate, accountIndex, start, qty int, pkb []byte) (*Account, error) {
 	// See https://github.com/bitcoin/bips/blob/master/bip-0044.mediawiki
-	ext, err := hdkeychain.NewMaster(pkb, net)
+	ext, err := newMasterKey(pkb)
 	if err != nil {
 		return nil, err
 	}
+	defer ext.Wipe()
+	return bip44WithKey(ctx, coin, ext, template, accountIndex, start, qty, false)
+}
 
-	// m/44'
+// bip44WithKey creates an account with count receive addresses derived from the path
+// template, based on the master key or an account extended key. The account key replaces
+// the path components up to its depth and becomes the root of the address key origins.
+// The legacy flag derives the keys like hdkeychain of btcutil v1.0.2 (see bip32.NewLegacyChildKey).
+func bip44WithKey(ctx context.Context, coin *Altcoin, ext *bip32.Key, template PathTemplate, accountIndex, start, qty int, legacy bool) (*Account, error) {
+	account := &Account{Coin: coin.Name, CoinType: coin.CoinType}
 
-	// Child returns a derived child extended key at the given index.  When this
-	// extended key is a private extended key (as determined by the IsPrivate
-	// function), a private extended key will be derived.  Otherwise, the derived
-	// extended key will be also be a public extended key.
-	purpose, err := ext.Child(44 + hdkeychain.HardenedKeyStart)
-	if err != nil {
-		return nil, err
-	}
+	// cointype as specified in https://github.com/satoshilabs/slips/blob/master/slip-0044.md
+	net := coin.Params()
 
-	// m/44'/altcointype'
-	coinType, err := purpose.Child(uint32(coin.CoinType) + hdkeychain.HardenedKeyStart)
+	// split the template into the path before the {index} component (eg m/44'/altcointype'/0'/0),
+	// the {index} component and the path after it
+	prefix, component, suffix, err := template.split(coin.CoinType, accountIndex)
 	if err != nil {
 		return nil, err
 	}
+	if !ext.IsPrivate {
+		return nil, errors.E("extended key is not private")
+	}
+	if depth := int(ext.Depth); depth > 0 {
+		if depth > len(prefix) || prefix[depth-1] != binary.BigEndian.Uint32(ext.ChildNumber) {
+			return nil, errors.E("extended key doesn't match the path template", errors.Params{"depth": depth, "template": template})
+		}
+		prefix = prefix[depth:]
+	}
+	derivePath, newChildKey := (*bip32.Key).DerivePath, (*bip32.Key).NewChildKey
+	if legacy {
+		derivePath, newChildKey = (*bip32.Key).DeriveLegacyPath, (*bip32.Key).NewLegacyChildKey
+	}
 
-	// m/44'/altcointype'/0'
-	acct0, err := coinType.Child(0 + hdkeychain.HardenedKeyStart)
+	// Account extended private key (eg to import in electrum), the last hardened
+	// key before the {index} component (m/44'/altcointype'/0')
+	hardened := len(prefix)
+	for hardened > 0 && prefix[hardened-1] < bip32.FirstHardenedChild {
+		hardened--
+	}
+	account.Key, err = derivePath(ext, prefix[:hardened])
 	if err != nil {
 		return nil, err
 	}
 
-	// Account extended private key (eg to import in electrum)
-	account.Key = acct0
-
 	// m/44'/altcointype'/0'/0
 	// 0 = external accounts for receive addresses
 	// 1 = internal accounts for change
-	acct0External, err := acct0.Child(0)
+	account.External, err = account.Key.DerivePath(prefix[hardened:])
 	if err != nil {
 		return nil, err
 	}
-	account.External = acct0External
 
+	origin := ext.Origin(prefix)
 	for i := start; i < (start + qty); i++ {
-		receive, err := acct0External.Child(uint32(i))
+		if err := ctx.Err(); err != nil {
+			return account, err
+		}
+		index, err := component.index(i)
+		if err != nil {
+			return nil, err
+		}
+		child, err := newChildKey(account.External, index)
 		if err != nil {
 			log.Error(err, "Failed to create receive address", log.Params{"i": i})
 			continue
 		}
-		// ECPrivKey converts the extended key to a btcec private key and returns it.
-		// As you might imagine this is only possible if the extended key is a private
-		// extended key (as determined by the IsPrivate function).  The ErrNotPrivExtKey
-		// error will be returned if this function is called on a public extended key.
-		privk, err := receive.ECPrivKey()
+		receive, err := derivePath(child, suffix)
+		child.Wipe()
 		if err != nil {
-			log.Error(err, "converts the extended key to a private key failed", log.Params{"i": i, "receive": receive})
+			log.Error(err, "Failed to create receive address", log.Params{"i": i})
 			continue
 		}
-
-		// ECPubKey converts the extended key to a btcec public key and returns it.
-		pubk, err := receive.ECPubKey()
+		addressOrigin := origin.Child(index)
+		addressOrigin.Path = append(addressOrigin.Path, suffix...)
+		address, err := newAddress(coin, net, receive, i, addressOrigin)
+		receive.Wipe()
 		if err != nil {
-			log.Error(err, "converts the extended key to a public key failed", log.Params{"i": i, "receive": receive})
+			log.Error(err, "address conversion failed", log.Params{"i": i, "receive": receive, "net": net})
 			continue
 		}
+		account.Addresses = append(account.Addresses, address)
+	}
+	return account, nil
+}
 
-		// Ethereum and Energi addresses are handle differently
-		if coin.Name == "Ethereum" || coin.Name == "Energi" {
-			// create our address from the publickey
-			address := crypto.PubkeyToAddress(*pubk.ToECDSA())
+// newAddress creates the coin address with the pub and privkey of the extended key
+func newAddress(coin *Altcoin, net *chaincfg.Params, receive *bip32.Key, index int, origin bip32.KeyOrigin) (Address, error) {
+	// PrivKeyFromBytes converts the extended key bytes to a btcec private and public keys.
+	privk, pubk := btcec.PrivKeyFromBytes(btcec.S256(), receive.Key)
+	defer WipePrivateKey(privk)
 
-			// add the address to our addresses, with the pub and privkey as a string (compressed)
-			account.Addresses = append(account.Addresses,
-				Address{
-					Address: address.String(),
-					Pubkey:  "0x" + hex.EncodeToString(pubk.SerializeCompressed()),
-					Privkey: "0x" + hex.EncodeToString(privk.Serialize()),
-					Index:   i,
-				})
+	// Ethereum and Energi addresses are handle differently
+	if coin.IsEthereum() {
+		// create our address from the publickey
+		address := crypto.PubkeyToAddress(*pubk.ToECDSA())
 
-			continue
-		}
+		// add the address to our addresses, with the pub and privkey as 0x hex (compressed)
+		pkb := privk.Serialize()
+		defer bip39.Zero(pkb)
+		privkey := make([]byte, 2+hex.EncodedLen(len(pkb)))
+		copy(privkey, "0x")
+		hex.Encode(privkey[2:], pkb)
+		return Address{
+			Address: address.String(),
+			Pubkey:  "0x" + hex.EncodeToString(pubk.SerializeCompressed()),
+			Privkey: privkey,
+			Index:   index,
+			Origin:  &origin,
+		}, nil
+	}
 
-		// Address converts the extended key to a standard bitcoin pay-to-pubkey-hash
-		// address for the passed network.
-		address, err := receive.Address(net)
-		if err != nil {
-			log.Error(err, "address conversion failed", log.Params{"i": i, "receive": receive, "net": net})
-			continue
-		}
+	// NewAddressPubKeyHash converts the public key hash to a standard bitcoin
+	// pay-to-pubkey-hash address for the passed network.
+	address, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(pubk.SerializeCompressed()), net)
+	if err != nil {
+		return Address{}, err
+	}
 
-		// NewWIF creates a new WIF structure to export an address and its private key
-		// as a string encoded in the Wallet Import Format.  The compress argument
-		// specifies whether the address intended to be imported or exported was created
-		// by serializing the public key compressed rather than uncompressed.
-		wif, err := btcutil.NewWIF(privk, net, true)
-		if err != nil {
-			log.Error(err, "creates a new WIF structure failed", log.Params{"i": i, "receive": receive, "net": net})
-			continue
-		}
-		account.Addresses = append(account.Addresses,
-			Address{
-				Address: address.String(),
-				Pubkey:  hex.EncodeToString(pubk.SerializeCompressed()),
-				Privkey: wif.String(),
-				Index:   i,
-			})
+	// NewWIF creates a new WIF structure to export an address and its private key
+	// as a string encoded in the Wallet Import Format.  The compress argument
+	// specifies whether the address intended to be imported or exported was created
+	// by serializing the public key compressed rather than uncompressed.
+	wif, err := btcutil.NewWIF(privk, net, true)
+	if err != nil {
+		return Address{}, err
 	}
-	return account, nil
+	return Address{
+		Address: address.String(),
+		Pubkey:  hex.EncodeToString(pubk.SerializeCompressed()),
+		Privkey: []byte(wif.String()),
+		Index:   index,
+		Origin:  &origin,
+	}, nil
 }
 
+// GenerateWallets generates the account with the receive addresses from start to start+qty,
+// derived from the mnemonic by the BIP44 path m/44'/cointype'/0'/0/i
+// It returns the account and an error if occurs
 func GenerateWallets(coin Coin, mnemonic, passphrase string, start, qty int) (*Account, error) {
-	if _, ok := CoinList[coin]; !ok {
-		return nil, errors.E("Invalid coin", errors.Params{"coin": coin})
-	}
-	var (
-		pk  *btcec.PrivateKey
-		err error
-	)
-	wallet := &Wallet{Seedwords: mnemonic}
+	return GenerateWalletsWithPath(coin, mnemonic, passphrase, DefaultPathTemplate, 0, start, qty)
+}
+
+// GenerateWalletsContext is like GenerateWallets, but stops the derivation when the context is done.
+// It returns the account with the addresses generated before the cancellation and the context error
+func GenerateWalletsContext(ctx context.Context, coin Coin, mnemonic, passphrase string, start, qty int) (*Account, error) {
+	return GenerateWalletsWithPathContext(ctx, coin, mnemonic, passphrase, DefaultPathTemplate, 0, start, qty)
+}
 
+// GenerateWalletsWithPath generates the account with the receive addresses from start to start+qty,
+// derived from the mnemonic by the path template, like m/44'/60'/{account}'/0/{index}
+// It returns the account and an error if occurs
+func GenerateWalletsWithPath(coin Coin, mnemonic, passphrase string, template PathTemplate, accountIndex, start, qty int) (*Account, error) {
+	return GenerateWalletsWithPathContext(context.Background(), coin, mnemonic, passphrase, template, accountIndex, start, qty)
+}
+
+// GenerateWalletsWithPathContext is like GenerateWalletsWithPath, but stops the derivation when the context is done.
+// It returns the account with the addresses generated before the cancellation and the context error
+func GenerateWalletsWithPathContext(ctx context.Context, coin Coin, mnemonic, passphrase string, template PathTemplate, accountIndex, start, qty int) (*Account, error) {
 	// https://github.com/bitcoin/bips/blob/master/bip-0039.mediawiki
 	// to create binary bip39 from the mnemonic, we use the PBKDF2 function with a mnemonic sentence (in UTF-8 NFKD)
 	// used as the passphrase and the string "mnemonic" + passphrase (again in UTF-8 NFKD) used as the salt. i
 	// The iteration count is set to 2048 and HMAC-SHA512 is used as the pseudo-random function.
 	// The length of the derived key is 512 bits (= 64 bytes).
-	// don't use an extra passphrase for the bip39
-	wallet.Seed, err = bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
 	if err != nil {
 		return nil, err
 	}
-	// hexencode the bip39 and give it to pkstr
-	pkstr := hex.EncodeToString(wallet.Seed)
+	defer bip39.Zero(seed)
+	return GenerateWalletsFromSeedContext(ctx, coin, seed, template, accountIndex, start, qty)
+}
 
-	// we have a user specified 256bits private key in hex OR we have a 512bits bip39 key in hex
-	// set the key back to bytes
-	pkb, err := hex.DecodeString(pkstr)
-	if err != nil {
-		return nil, err
+// GenerateWalletsFromSeed generates the account with the receive addresses from start to start+qty,
+// derived from the bip39 seed by the path template. The seed is not retained by the account.
+// It returns the account and an error if occurs
+func GenerateWalletsFromSeed(coin Coin, seed []byte, template PathTemplate, accountIndex, start, qty int) (*Account, error) {
+	return GenerateWalletsFromSeedContext(context.Background(), coin, seed, template, accountIndex, start, qty)
+}
+
+// GenerateWalletsFromSeedContext is like GenerateWalletsFromSeed, but stops the derivation when the context is done.
+// It returns the account with the addresses generated before the cancellation and the context error
+func GenerateWalletsFromSeedContext(ctx context.Context, coin Coin, seed []byte, template PathTemplate, accountIndex, start, qty int) (*Account, error) {
+	if _, ok := CoinList[coin]; !ok {
+		return nil, errors.E("Invalid coin", errors.Params{"coin": coin})
 	}
 
 	// PrivKeyFromBytes returns a private and public key for `curve' based on the
 	// private key passed as an argument as a byte slice.
-	pk, _ = btcec.PrivKeyFromBytes(btcec.S256(), pkb)
-	// we have to specify a net (use the mainnet (bitcoin) for now)
-	net := &chaincfg.MainNetParams
+	pk, _ := btcec.PrivKeyFromBytes(btcec.S256(), seed)
 
-	// NewMaster creates a new master node (bip32 root key) for use in creating a hierarchical
+	// bip44 creates a new master node (bip32 root key) for use in creating a hierarchical
 	// deterministic key chain.
 	// see https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki#Master_key_generation
-	// pkb is the bip39 bip39 key
-	if _, err := hdkeychain.NewMaster(pkb, net); err != nil {
+	account, err := bip44WithPath(ctx, CoinList[coin], template, accountIndex, start, qty, seed)
+	if account == nil {
+		WipePrivateKey(pk)
 		return nil, err
 	}
-	account, err := bip44(CoinList[coin], start, qty, pkb)
+	account.PrivateKey = pk.Serialize()
+	account.Masterkey = pk
+	return account, err
+}
+
+// GenerateWalletsFromKeyContext generates the account with the receive addresses from start to start+qty,
+// derived by the path template from the master key, or from an account extended key that replaces
+// the template components up to its depth (eg m/44'/cointype'/0' for the default template).
+// The context is checked between indexes like GenerateWalletsContext.
+// It returns the account and an error if occurs
+func GenerateWalletsFromKeyContext(ctx context.Context, coin Coin, key *bip32.Key, template PathTemplate, accountIndex, start, qty int) (*Account, error) {
+	if _, ok := CoinList[coin]; !ok {
+		return nil, errors.E("Invalid coin", errors.Params{"coin": coin})
+	}
+	return bip44WithKey(ctx, CoinList[coin], key, template, accountIndex, start, qty, false)
+}
+
+// GenerateLegacyWalletsFromKeyContext is like GenerateWalletsFromKeyContext, but derives the keys
+// like hdkeychain of btcutil v1.0.2, used by pool-party before the bip32 package. hdkeychain drops
+// the leading zero bytes of the private keys when deriving hardened children, so about 1 in 128
+// seeds has different BIP44 addresses. It's only meant to recover the funds of these addresses.
+// It returns the account and an error if occurs
+func GenerateLegacyWalletsFromKeyContext(ctx context.Context, coin Coin, key *bip32.Key, template PathTemplate, accountIndex, start, qty int) (*Account, error) {
+	if _, ok := CoinList[coin]; !ok {
+		return nil, errors.E("Invalid coin", errors.Params{"coin": coin})
+	}
+	return bip44WithKey(ctx, CoinList[coin], key, template, accountIndex, start, qty, true)
+}
+
+// HasLegacyDerivation checks if the addresses derived by the path template from the key
+// differ from the legacy hdkeychain derivation (see GenerateLegacyWalletsFromKeyContext),
+// so the legacy addresses must be swept or kept watched
+// It returns true if the addresses differ and an error if occurs
+func HasLegacyDerivation(coin Coin, key *bip32.Key, template PathTemplate, accountIndex, start, qty int) (bool, error) {
+	account, err := GenerateWalletsFromKeyContext(context.Background(), coin, key, template, accountIndex, start, qty)
+	if err != nil {
+		return false, err
+	}
+	defer account.Wipe()
+	legacy, err := GenerateLegacyWalletsFromKeyContext(context.Background(), coin, key, template, accountIndex, start, qty)
+	if err != nil {
+		return false, err
+	}
+	defer legacy.Wipe()
+	if len(account.Addresses) != len(legacy.Addresses) {
+		return true, nil
+	}
+	for i := range account.Addresses {
+		if account.Addresses[i].Address != legacy.Addresses[i].Address {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// NewMasterKey creates the bip32 root key from the mnemonic and passphrase
+// It returns the master extended key and an error if occurs
+func NewMasterKey(mnemonic, passphrase string) (*bip32.Key, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
 	if err != nil {
 		return nil, err
 	}
-	account.PrivateKey = hex.EncodeToString(pk.Serialize())
-	account.Masterkey = pk
-	return account, nil
+	defer bip39.Zero(seed)
+	return newMasterKey(seed)
+}
+
+// NewMasterKeyFromSeed creates the bip32 root key from the bip39 seed
+// It returns the master extended key and an error if occurs
+func NewMasterKeyFromSeed(seed []byte) (*bip32.Key, error) {
+	return newMasterKey(seed)
+}
+
+// Fingerprint returns the first 32 bits of the key identifier (hash160 of the public key),
+// read as little endian like the BIP174 master key fingerprint
+func Fingerprint(key *bip32.Key) uint32 {
+	return binary.LittleEndian.Uint32(key.Fingerprint())
+}
+
+// newMasterKey creates the bip32 root key from the seed, validating the seed length
+// between 128 and 512 bits as recommended by the bip32 spec.
+func newMasterKey(seed []byte) (*bip32.Key, error) {
+	if len(seed) < minSeedBytes || len(seed) > maxSeedBytes {
+		return nil, errors.E("invalid seed length", errors.Params{"length": len(seed)})
+	}
+	return bip32.NewMasterKey(seed)
+}
+
+// WipePrivateKey zeroes the private key scalar
+func WipePrivateKey(privk *btcec.PrivateKey) {
+	if privk == nil || privk.D == nil {
+		return
+	}
+	words := privk.D.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	privk.D.SetInt64(0)
 }
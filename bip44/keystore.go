@@ -0,0 +1,118 @@
+package bip44
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Pantani/errors"
+	"github.com/Pantani/pool-party/bip39"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+const (
+	keystoreDirPerm  = 0700
+	keystoreFilePerm = 0600
+)
+
+// Keystore encrypts the address private key into a Web3 Secret Storage (keystore v3) JSON,
+// the format used by geth and clef. Only Ethereum based addresses (Ethereum and Energi) are supported.
+// It returns the keystore JSON and an error if occurs
+func (a Address) Keystore(passphrase string, scryptN, scryptP int) ([]byte, error) {
+	key, err := a.keystoreKey()
+	if err != nil {
+		return nil, err
+	}
+	defer WipePrivateKey((*btcec.PrivateKey)(key.PrivateKey))
+	keyJSON, err := keystore.EncryptKey(key, passphrase, scryptN, scryptP)
+	if err != nil {
+		return nil, errors.E(err, "error to encrypt the keystore", errors.Params{"address": a.Address})
+	}
+	return keyJSON, nil
+}
+
+// Keystores encrypts all addresses private keys into Web3 Secret Storage (keystore v3) JSONs
+// It returns the keystore JSONs in the same order of the addresses and an error if occurs
+func (addrs Addresses) Keystores(passphrase string, scryptN, scryptP int) ([][]byte, error) {
+	result := make([][]byte, 0, len(addrs))
+	for _, addr := range addrs {
+		keyJSON, err := addr.Keystore(passphrase, scryptN, scryptP)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, keyJSON)
+	}
+	return result, nil
+}
+
+// ExportKeystores writes all addresses as keystore v3 files inside the directory,
+// following the geth file naming convention (UTC--<created_at>--<address hex>),
+// so the directory can be used directly as a geth/clef keystore.
+// It returns the written file paths and an error if occurs
+func (addrs Addresses) ExportKeystores(dir, passphrase string, scryptN, scryptP int) ([]string, error) {
+	if err := os.MkdirAll(dir, keystoreDirPerm); err != nil {
+		return nil, errors.E(err, "error to create the keystore directory", errors.Params{"dir": dir})
+	}
+	files := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		keyJSON, err := addr.Keystore(passphrase, scryptN, scryptP)
+		if err != nil {
+			return files, err
+		}
+		file := filepath.Join(dir, keyFileName(common.HexToAddress(addr.Address)))
+		if err := ioutil.WriteFile(file, keyJSON, keystoreFilePerm); err != nil {
+			return files, errors.E(err, "error to write the keystore file", errors.Params{"file": file})
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// keystoreKey converts the address private key to a go-ethereum keystore key,
+// the caller must wipe the key
+func (a Address) keystoreKey() (*keystore.Key, error) {
+	if !bytes.HasPrefix(a.Privkey, []byte("0x")) || !common.IsHexAddress(a.Address) {
+		return nil, errors.E("keystore export is only supported for Ethereum addresses", errors.Params{"address": a.Address})
+	}
+	pkb := make([]byte, hex.DecodedLen(len(a.Privkey)-2))
+	defer bip39.Zero(pkb)
+	if _, err := hex.Decode(pkb, a.Privkey[2:]); err != nil {
+		return nil, errors.E(err, "invalid private key", errors.Params{"address": a.Address})
+	}
+	privk, err := crypto.ToECDSA(pkb)
+	if err != nil {
+		return nil, errors.E(err, "invalid private key", errors.Params{"address": a.Address})
+	}
+	address := crypto.PubkeyToAddress(privk.PublicKey)
+	if address != common.HexToAddress(a.Address) {
+		WipePrivateKey((*btcec.PrivateKey)(privk))
+		return nil, errors.E("private key doesn't match the address", errors.Params{"address": a.Address})
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		WipePrivateKey((*btcec.PrivateKey)(privk))
+		return nil, errors.E(err, "error to generate the keystore id")
+	}
+	return &keystore.Key{
+		Id:         id,
+		Address:    address,
+		PrivateKey: privk,
+	}, nil
+}
+
+// keyFileName implements the geth naming convention for keyfiles:
+// UTC--<created_at UTC ISO8601>--<address hex>
+func keyFileName(address common.Address) string {
+	t := time.Now().UTC()
+	ts := fmt.Sprintf("%04d-%02d-%02dT%02d-%02d-%02d.%09dZ",
+		t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond())
+	return fmt.Sprintf("UTC--%s--%s", ts, hex.EncodeToString(address[:]))
+}
@@ -0,0 +1,73 @@
+package bip44
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddress_Keystore(t *testing.T) {
+	account, err := GenerateWallets(Ethereum, mnemonic, "", 0, 1)
+	assert.NoError(t, err)
+	addr := account.Addresses[0]
+
+	keyJSON, err := addr.Keystore("password", keystore.LightScryptN, keystore.LightScryptP)
+	assert.NoError(t, err)
+
+	key, err := keystore.DecryptKey(keyJSON, "password")
+	assert.NoError(t, err)
+	assert.Equal(t, common.HexToAddress(addr.Address), key.Address)
+
+	_, err = keystore.DecryptKey(keyJSON, "wrong")
+	assert.Equal(t, keystore.ErrDecrypt, err)
+}
+
+func TestAddress_KeystoreNotEthereum(t *testing.T) {
+	account, err := GenerateWallets(Bitcoin, mnemonic, "", 0, 1)
+	assert.NoError(t, err)
+
+	_, err = account.Addresses[0].Keystore("password", keystore.LightScryptN, keystore.LightScryptP)
+	assert.Error(t, err)
+}
+
+func TestAddress_KeystoreWrongKey(t *testing.T) {
+	account, err := GenerateWallets(Ethereum, mnemonic, "", 0, 2)
+	assert.NoError(t, err)
+
+	addr := account.Addresses[0]
+	addr.Privkey = account.Addresses[1].Privkey
+	_, err = addr.Keystore("password", keystore.LightScryptN, keystore.LightScryptP)
+	assert.Error(t, err)
+}
+
+func TestAddresses_ExportKeystores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bip44-keystore")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	account, err := GenerateWallets(Energi, mnemonic, "", 5, 3)
+	assert.NoError(t, err)
+
+	files, err := account.Addresses.ExportKeystores(filepath.Join(dir, "keystore"), "password", keystore.LightScryptN, keystore.LightScryptP)
+	assert.NoError(t, err)
+	assert.Len(t, files, 3)
+
+	ks := keystore.NewKeyStore(filepath.Join(dir, "keystore"), keystore.LightScryptN, keystore.LightScryptP)
+	for i, file := range files {
+		addr := common.HexToAddress(account.Addresses[i].Address)
+		assert.True(t, strings.HasSuffix(file, strings.ToLower(addr.Hex()[2:])))
+		assert.True(t, ks.HasAddress(addr))
+
+		keyJSON, err := ioutil.ReadFile(file)
+		assert.NoError(t, err)
+		key, err := keystore.DecryptKey(keyJSON, "password")
+		assert.NoError(t, err)
+		assert.Equal(t, addr, key.Address)
+	}
+}
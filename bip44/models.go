@@ -1,8 +1,10 @@
 package bip44
 
 import (
+	"github.com/Pantani/pool-party/bip32"
+	"github.com/Pantani/pool-party/bip39"
 	"github.com/btcsuite/btcd/btcec"
-	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
 )
 
 type Addresses []Address
@@ -10,31 +12,61 @@ type Addresses []Address
 type Address struct {
 	Address string
 	Pubkey  string
-	Privkey string
+	Privkey []byte // WIF, or 0x hex for Ethereum based coins, zeroed by Account.Wipe
 	Index   int
+	Origin  *bip32.KeyOrigin // master key fingerprint and derivation path of the address key
 }
 
 type Account struct {
 	Coin       string
 	CoinType   int
-	Key        *hdkeychain.ExtendedKey // bip44 extended key (m/44'/cointype'/0')
-	External   *hdkeychain.ExtendedKey // external extended key (m/44'/cointype'/0'/0)
+	Key        *bip32.Key // bip44 extended key (m/44'/cointype'/0')
+	External   *bip32.Key // external extended key (m/44'/cointype'/0'/0)
 	Masterkey  *btcec.PrivateKey
 	Addresses  Addresses
-	PrivateKey string
+	PrivateKey []byte
+}
+
+// Wipe zeroes the account extended keys, master key and address private keys
+func (a *Account) Wipe() {
+	if a.Key != nil {
+		a.Key.Wipe()
+	}
+	if a.External != nil {
+		a.External.Wipe()
+	}
+	WipePrivateKey(a.Masterkey)
+	bip39.Zero(a.PrivateKey)
+	for i := range a.Addresses {
+		bip39.Zero(a.Addresses[i].Privkey)
+		a.Addresses[i].Privkey = nil
+	}
 }
 
 type Wallet struct {
 	Seed      []byte    // bip39 64byte (512bits) bip39
-	Seedwords string    // bip39 mnemonic
+	Seedwords []byte    // bip39 mnemonic
 	Accounts  []Account // different coin accounts
 }
 
+// Wipe zeroes the seed, the mnemonic and the accounts keys
+func (w *Wallet) Wipe() {
+	bip39.Zero(w.Seed)
+	bip39.Zero(w.Seedwords)
+	w.Seedwords = nil
+	for i := range w.Accounts {
+		w.Accounts[i].Wipe()
+	}
+}
+
 type Altcoin struct {
 	Name             string
 	PubKeyHashAddrID byte
+	ScriptHashAddrID byte
 	PrivateKeyID     byte
 	CoinType         int
+	MessagePrefix    string // signmessage magic prefix, empty for Ethereum based coins
+	Bech32HRP        string // segwit human-readable part, empty if segwit is not supported
 }
 
 type Coin string
@@ -49,10 +81,30 @@ const (
 )
 
 var CoinList = map[Coin]*Altcoin{
-	Ethereum: {"Ethereum", 0xff, 0xff, 60},
-	Energi:   {"Energi", 0xff, 0xff, 39797},
-	Bitcoin:  {"Bitcoin", 0x00, 0x80, 0},
-	Litecoin: {"Litecoin", 0x30, 0xb0, 2},
-	Dash:     {"Dash", 0x4c, 0xcc, 5},
-	Dogecoin: {"Dogecoin", 0x1e, 0x9e, 3},
+	Ethereum: {Name: "Ethereum", PubKeyHashAddrID: 0xff, ScriptHashAddrID: 0xff, PrivateKeyID: 0xff, CoinType: 60},
+	Energi:   {Name: "Energi", PubKeyHashAddrID: 0xff, ScriptHashAddrID: 0xff, PrivateKeyID: 0xff, CoinType: 39797},
+	Bitcoin:  {Name: "Bitcoin", PubKeyHashAddrID: 0x00, ScriptHashAddrID: 0x05, PrivateKeyID: 0x80, CoinType: 0, MessagePrefix: "Bitcoin Signed Message:\n", Bech32HRP: "bc"},
+	Litecoin: {Name: "Litecoin", PubKeyHashAddrID: 0x30, ScriptHashAddrID: 0x32, PrivateKeyID: 0xb0, CoinType: 2, MessagePrefix: "Litecoin Signed Message:\n", Bech32HRP: "ltc"},
+	Dash:     {Name: "Dash", PubKeyHashAddrID: 0x4c, ScriptHashAddrID: 0x10, PrivateKeyID: 0xcc, CoinType: 5, MessagePrefix: "DarkCoin Signed Message:\n"},
+	Dogecoin: {Name: "Dogecoin", PubKeyHashAddrID: 0x1e, ScriptHashAddrID: 0x16, PrivateKeyID: 0x9e, CoinType: 3, MessagePrefix: "Dogecoin Signed Message:\n"},
+}
+
+// IsEthereum returns true for the coins using Ethereum addresses and keys
+func (c *Altcoin) IsEthereum() bool {
+	return c != nil && (c.Name == "Ethereum" || c.Name == "Energi")
+}
+
+// SupportsSegwit returns true for the coins with segregated witness activated
+func (c *Altcoin) SupportsSegwit() bool {
+	return c != nil && len(c.Bech32HRP) > 0
+}
+
+// Params returns the chain params with the coin address and private key ids
+func (c *Altcoin) Params() *chaincfg.Params {
+	net := chaincfg.MainNetParams
+	net.PubKeyHashAddrID = c.PubKeyHashAddrID
+	net.ScriptHashAddrID = c.ScriptHashAddrID
+	net.PrivateKeyID = c.PrivateKeyID
+	net.Bech32HRPSegwit = c.Bech32HRP
+	return &net
 }
@@ -0,0 +1,105 @@
+package bip44
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Pantani/errors"
+	"github.com/Pantani/pool-party/bip32"
+)
+
+const (
+	// DefaultPathTemplate is the BIP44 path template, m/44'/cointype'/account'/0/i
+	DefaultPathTemplate PathTemplate = "m/44'/{coin}'/{account}'/0/{index}"
+
+	coinPlaceholder    = "{coin}"
+	accountPlaceholder = "{account}"
+	indexPlaceholder   = "{index}"
+)
+
+// PathTemplate represents a derivation path with placeholders, like m/44'/60'/{account}'/0/{index}.
+// The {index} placeholder is required and must be used once, {coin} (the SLIP-44 coin type)
+// and {account} are optional.
+type PathTemplate string
+
+// Path resolves the template placeholders and parses the derivation path
+// It returns the derivation path and an error if occurs
+func (t PathTemplate) Path(coinType, account, index int) (bip32.DerivationPath, error) {
+	prefix, child, suffix, err := t.split(coinType, account)
+	if err != nil {
+		return nil, err
+	}
+	childIndex, err := child.index(index)
+	if err != nil {
+		return nil, err
+	}
+	path := append(bip32.DerivationPath{}, prefix...)
+	path = append(path, childIndex)
+	return append(path, suffix...), nil
+}
+
+// Parent resolves the template placeholders and parses the path of the parent key of the
+// {index} component, the key that derives the addresses from its extended public key
+// It returns the parent path and an error if the {index} component is hardened or isn't the last one
+func (t PathTemplate) Parent(coinType, account int) (bip32.DerivationPath, error) {
+	prefix, child, suffix, err := t.split(coinType, account)
+	if err != nil {
+		return nil, err
+	}
+	if index, _ := child.index(0); index >= bip32.FirstHardenedChild || len(suffix) > 0 {
+		return nil, errors.E("path template addresses can't be derived from the parent public key", errors.Params{"template": t})
+	}
+	return prefix, nil
+}
+
+// Validate checks the template syntax
+// It returns an error if the template is invalid
+func (t PathTemplate) Validate() error {
+	_, err := t.Path(0, 0, 0)
+	return err
+}
+
+// indexComponent represents the path component with the {index} placeholder
+type indexComponent string
+
+// index resolves the {index} placeholder and parses the child number
+func (c indexComponent) index(index int) (uint32, error) {
+	if index < 0 || int64(index) >= int64(bip32.FirstHardenedChild) {
+		return 0, errors.E("index out of range", errors.Params{"index": index})
+	}
+	return bip32.ParseChildNumber(strings.Replace(string(c), indexPlaceholder, strconv.Itoa(index), 1))
+}
+
+// split resolves the {coin} and {account} placeholders and splits the template into
+// the path before the {index} component, the {index} component and the path after it.
+func (t PathTemplate) split(coinType, account int) (bip32.DerivationPath, indexComponent, bip32.DerivationPath, error) {
+	if account < 0 || int64(account) >= int64(bip32.FirstHardenedChild) {
+		return nil, "", nil, errors.E("account out of range", errors.Params{"account": account})
+	}
+	template := strings.Replace(string(t), coinPlaceholder, strconv.Itoa(coinType), -1)
+	template = strings.Replace(template, accountPlaceholder, strconv.Itoa(account), -1)
+	if strings.Count(template, indexPlaceholder) != 1 {
+		return nil, "", nil, errors.E("path template must have exactly one {index} placeholder", errors.Params{"template": t})
+	}
+
+	components := strings.Split(template, "/")
+	position := 0
+	for i, component := range components {
+		if strings.Contains(component, indexPlaceholder) {
+			position = i
+		}
+	}
+	prefix, err := bip32.ParseDerivationPath(strings.Join(components[:position], "/"))
+	if err != nil {
+		return nil, "", nil, errors.E(err, "invalid path template", errors.Params{"template": t})
+	}
+	suffix, err := bip32.ParseDerivationPath(strings.Join(components[position+1:], "/"))
+	if err != nil {
+		return nil, "", nil, errors.E(err, "invalid path template", errors.Params{"template": t})
+	}
+	child := indexComponent(components[position])
+	if _, err := child.index(0); err != nil {
+		return nil, "", nil, errors.E(err, "invalid path template", errors.Params{"template": t})
+	}
+	return prefix, child, suffix, nil
+}
@@ -0,0 +1,124 @@
+package bip44
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/Pantani/pool-party/bip32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathTemplate_Path(t *testing.T) {
+	tests := []struct {
+		template PathTemplate
+		account  int
+		index    int
+		want     string
+	}{
+		{template: DefaultPathTemplate, account: 0, index: 5, want: "m/44'/60'/0'/0/5"},
+		{template: DefaultPathTemplate, account: 3, index: 7, want: "m/44'/60'/3'/0/7"},
+		{template: "m/44'/60'/{index}'/0/0", index: 2, want: "m/44'/60'/2'/0/0"},
+		{template: "m/44'/60'/0'/{index}", index: 9, want: "m/44'/60'/0'/9"},
+		{template: "m/84h/{coin}h/{account}h/1/{index}", account: 1, index: 1, want: "m/84'/60'/1'/1/1"},
+		{template: "{index}/0", index: 4, want: "m/4/0"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.template), func(t *testing.T) {
+			got, err := tt.template.Path(60, tt.account, tt.index)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestPathTemplate_Parent(t *testing.T) {
+	tests := []struct {
+		template PathTemplate
+		want     string
+		wantErr  bool
+	}{
+		{template: DefaultPathTemplate, want: "m/44'/60'/2'/0"},
+		{template: "m/0'/0/{index}", want: "m/0'/0"},
+		{template: "{index}", want: "m"},
+		{template: "m/44'/60'/{index}'/0/0", wantErr: true},
+		{template: "m/44'/60'/0'/{index}'", wantErr: true},
+		{template: "m/44'/60'/0'/0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.template), func(t *testing.T) {
+			got, err := tt.template.Parent(60, 2)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestPathTemplate_Invalid(t *testing.T) {
+	for _, template := range []PathTemplate{
+		"m/44'/60'/0'/0",
+		"m/44'/60'/{index}'/0/{index}",
+		"m/44'/60'/{account}/0/{index}x",
+		"m/44'/60'/x/0/{index}",
+		"m/44'/60'/{acount}'/0/{index}",
+	} {
+		t.Run(string(template), func(t *testing.T) {
+			assert.Error(t, template.Validate())
+		})
+	}
+
+	_, err := DefaultPathTemplate.Path(60, -1, 0)
+	assert.Error(t, err)
+	_, err = DefaultPathTemplate.Path(60, 0, int(bip32.FirstHardenedChild))
+	assert.Error(t, err)
+}
+
+func TestGenerateWalletsWithPath(t *testing.T) {
+	want, err := GenerateWallets(Ethereum, mnemonic, "", 0, 3)
+	assert.NoError(t, err)
+	got, err := GenerateWalletsWithPath(Ethereum, mnemonic, "", "m/44'/60'/{account}'/0/{index}", 0, 0, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, want.Addresses, got.Addresses)
+	assert.Equal(t, want.Key, got.Key)
+	assert.Equal(t, want.External, got.External)
+
+	// the path template addresses match the manual derivation
+	master, err := NewMasterKey(mnemonic, "")
+	assert.NoError(t, err)
+	template := PathTemplate("m/44'/60'/{index}'/0/0")
+	got, err = GenerateWalletsWithPath(Ethereum, mnemonic, "", template, 0, 2, 2)
+	assert.NoError(t, err)
+	for i, addr := range got.Addresses {
+		assert.Equal(t, 2+i, addr.Index)
+		path, err := template.Path(60, 0, addr.Index)
+		assert.NoError(t, err)
+		key, err := master.DerivePath(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "0x"+hex.EncodeToString(key.PublicKey().Key), addr.Pubkey)
+	}
+
+	_, err = GenerateWalletsWithPath(Ethereum, mnemonic, "", "m/44'/60'/0'/0", 0, 0, 1)
+	assert.Error(t, err)
+}
+
+func TestGenerateWalletsOrigin(t *testing.T) {
+	master, err := NewMasterKey(mnemonic, "")
+	assert.NoError(t, err)
+	for _, coin := range []Coin{Bitcoin, Ethereum} {
+		account, err := GenerateWalletsWithPath(coin, mnemonic, "", "m/44'/{coin}'/{account}'/{index}/0", 1, 2, 2)
+		assert.NoError(t, err)
+		for _, addr := range account.Addresses {
+			assert.Equal(t, master.Fingerprint(), addr.Origin.Fingerprint)
+			path, err := PathTemplate("m/44'/{coin}'/{account}'/{index}/0").Path(CoinList[coin].CoinType, 1, addr.Index)
+			assert.NoError(t, err)
+			assert.Equal(t, path, addr.Origin.Path)
+
+			key, err := master.DerivePath(addr.Origin.Path)
+			assert.NoError(t, err)
+			assert.Contains(t, addr.Pubkey, hex.EncodeToString(key.PublicKey().Key))
+		}
+	}
+}
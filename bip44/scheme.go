@@ -0,0 +1,50 @@
+package bip44
+
+import "github.com/Pantani/errors"
+
+// DerivationScheme represents the derivation path used by a wallet software
+type DerivationScheme struct {
+	Name         string
+	Template     PathTemplate
+	EthereumOnly bool // the scheme is only used by Ethereum wallets
+}
+
+type Scheme string
+
+const (
+	BIP44      Scheme = "BIP44"      // m/44'/cointype'/0'/0/i
+	MetaMask   Scheme = "MetaMask"   // m/44'/60'/0'/0/i, also used by Trezor and Ledger Live legacy accounts
+	LedgerLive Scheme = "LedgerLive" // m/44'/60'/i'/0/0
+	LegacyMEW  Scheme = "LegacyMEW"  // m/44'/60'/0'/i, also used by the Ledger Chrome app
+)
+
+var SchemeList = map[Scheme]*DerivationScheme{
+	BIP44:      {Name: "BIP44", Template: DefaultPathTemplate},
+	MetaMask:   {Name: "MetaMask", Template: "m/44'/60'/0'/0/{index}", EthereumOnly: true},
+	LedgerLive: {Name: "Ledger Live", Template: "m/44'/60'/{index}'/0/0", EthereumOnly: true},
+	LegacyMEW:  {Name: "Legacy MEW", Template: "m/44'/60'/0'/{index}", EthereumOnly: true},
+}
+
+// Template returns the path template of the scheme for the coin
+// It returns the path template and an error if the scheme is invalid or not supported by the coin
+func (s Scheme) Template(coin Coin) (PathTemplate, error) {
+	scheme, ok := SchemeList[s]
+	if !ok {
+		return "", errors.E("Invalid derivation scheme", errors.Params{"scheme": s})
+	}
+	if scheme.EthereumOnly && !CoinList[coin].IsEthereum() {
+		return "", errors.E("derivation scheme is only supported for Ethereum based coins", errors.Params{"scheme": s, "coin": coin})
+	}
+	return scheme.Template, nil
+}
+
+// GenerateWalletsWithScheme generates the account with the receive addresses from start to start+qty,
+// derived from the mnemonic by the wallet derivation scheme
+// It returns the account and an error if occurs
+func GenerateWalletsWithScheme(coin Coin, mnemonic, passphrase string, scheme Scheme, start, qty int) (*Account, error) {
+	template, err := scheme.Template(coin)
+	if err != nil {
+		return nil, err
+	}
+	return GenerateWalletsWithPath(coin, mnemonic, passphrase, template, 0, start, qty)
+}
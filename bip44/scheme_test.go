@@ -0,0 +1,52 @@
+package bip44
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateWalletsWithScheme(t *testing.T) {
+	const hardhat = "test test test test test test test test test test test junk"
+	account, err := GenerateWalletsWithScheme(Ethereum, hardhat, "", MetaMask, 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", account.Addresses[0].Address)
+	assert.Equal(t, "0x70997970C51812dc3A010C7d01b50e0d17dc79C8", account.Addresses[1].Address)
+
+	master, err := NewMasterKey(hardhat, "")
+	assert.NoError(t, err)
+	for _, scheme := range []Scheme{BIP44, MetaMask, LedgerLive, LegacyMEW} {
+		t.Run(string(scheme), func(t *testing.T) {
+			account, err := GenerateWalletsWithScheme(Ethereum, hardhat, "", scheme, 3, 2)
+			assert.NoError(t, err)
+			assert.Len(t, account.Addresses, 2)
+			for _, addr := range account.Addresses {
+				path, err := SchemeList[scheme].Template.Path(60, 0, addr.Index)
+				assert.NoError(t, err)
+				key, err := master.DerivePath(path)
+				assert.NoError(t, err)
+				assert.Equal(t, "0x"+hex.EncodeToString(key.PublicKey().Key), addr.Pubkey)
+			}
+		})
+	}
+
+	// Ledger Live first account is the MetaMask first address
+	ledger, err := GenerateWalletsWithScheme(Ethereum, hardhat, "", LedgerLive, 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, account.Addresses[0].Address, ledger.Addresses[0].Address)
+	assert.NotEqual(t, account.Addresses[1].Address, ledger.Addresses[1].Address)
+}
+
+func TestGenerateWalletsWithSchemeInvalid(t *testing.T) {
+	_, err := GenerateWalletsWithScheme(Ethereum, mnemonic, "", "Exodus", 0, 1)
+	assert.Error(t, err)
+	_, err = GenerateWalletsWithScheme(Bitcoin, mnemonic, "", MetaMask, 0, 1)
+	assert.Error(t, err)
+
+	account, err := GenerateWalletsWithScheme(Bitcoin, mnemonic, "", BIP44, 0, 1)
+	assert.NoError(t, err)
+	want, err := GenerateWallets(Bitcoin, mnemonic, "", 0, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, want.Addresses, account.Addresses)
+}
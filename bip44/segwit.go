@@ -0,0 +1,71 @@
+package bip44
+
+import (
+	"encoding/hex"
+
+	"github.com/Pantani/errors"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+)
+
+// Segwit converts the addresses to the native segwit (P2WPKH) addresses of their public keys,
+// like the BIP84, Electrum segwit and LND wallets
+// It returns the converted addresses and an error if the coin doesn't support segwit
+func (addrs Addresses) Segwit(coin Coin) (Addresses, error) {
+	altcoin, ok := CoinList[coin]
+	if !ok || !altcoin.SupportsSegwit() {
+		return nil, errors.E("segwit addresses are not supported for the coin", errors.Params{"coin": coin})
+	}
+	result := make(Addresses, 0, len(addrs))
+	for _, addr := range addrs {
+		pubkey, err := hex.DecodeString(addr.Pubkey)
+		if err != nil {
+			return nil, errors.E(err, "invalid address public key", errors.Params{"address": addr.Address})
+		}
+		witness, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(pubkey), altcoin.Params())
+		if err != nil {
+			return nil, errors.E(err, "error to create the segwit address", errors.Params{"address": addr.Address})
+		}
+		addr.Address = witness.EncodeAddress()
+		result = append(result, addr)
+	}
+	return result, nil
+}
+
+// NestedSegwit converts the addresses to the nested segwit (P2SH-P2WPKH) addresses of their
+// public keys, like the BIP49 and LND wallets
+// It returns the converted addresses and an error if the coin doesn't support segwit
+func (addrs Addresses) NestedSegwit(coin Coin) (Addresses, error) {
+	altcoin, ok := CoinList[coin]
+	if !ok || !altcoin.SupportsSegwit() {
+		return nil, errors.E("segwit addresses are not supported for the coin", errors.Params{"coin": coin})
+	}
+	result := make(Addresses, 0, len(addrs))
+	for _, addr := range addrs {
+		pubkey, err := hex.DecodeString(addr.Pubkey)
+		if err != nil {
+			return nil, errors.E(err, "invalid address public key", errors.Params{"address": addr.Address})
+		}
+		nested, err := nestedSegwitAddress(pubkey, altcoin.Params())
+		if err != nil {
+			return nil, errors.E(err, "error to create the nested segwit address", errors.Params{"address": addr.Address})
+		}
+		addr.Address = nested.EncodeAddress()
+		result = append(result, addr)
+	}
+	return result, nil
+}
+
+// nestedSegwitAddress returns the P2SH address of the P2WPKH script of the public key
+func nestedSegwitAddress(pubkey []byte, params *chaincfg.Params) (*btcutil.AddressScriptHash, error) {
+	witness, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(pubkey), params)
+	if err != nil {
+		return nil, err
+	}
+	script, err := txscript.PayToAddrScript(witness)
+	if err != nil {
+		return nil, err
+	}
+	return btcutil.NewAddressScriptHash(script, params)
+}
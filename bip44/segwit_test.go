@@ -0,0 +1,51 @@
+package bip44
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddresses_Segwit(t *testing.T) {
+	// the BIP84 test vector, m/84'/0'/0'/0/0
+	addrs := Addresses{{
+		Address: "1JaUQDVNRdhfNsVncGkXedaPSM5Gc54Hso",
+		Pubkey:  "0330d54fd0dd420a6e5f8d3624f5f3482cae350f79d5f0753bf5beef9c2d91af3c",
+		Index:   0,
+	}}
+	got, err := addrs.Segwit(Bitcoin)
+	assert.NoError(t, err)
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", got[0].Address)
+		assert.Equal(t, addrs[0].Pubkey, got[0].Pubkey)
+	}
+	// the addresses are copied
+	assert.Equal(t, "1JaUQDVNRdhfNsVncGkXedaPSM5Gc54Hso", addrs[0].Address)
+
+	_, err = addrs.Segwit(Dogecoin)
+	assert.Error(t, err)
+	_, err = addrs.Segwit(Ethereum)
+	assert.Error(t, err)
+	_, err = Addresses{{Pubkey: "zz"}}.Segwit(Bitcoin)
+	assert.Error(t, err)
+}
+
+func TestAddresses_NestedSegwit(t *testing.T) {
+	// the abandon mnemonic, m/49'/0'/0'/0/0
+	addrs := Addresses{{
+		Address: "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA",
+		Pubkey:  "039b3b694b8fc5b5e07fb069c783cac754f5d38c3e08bed1960e31fdb1dda35c24",
+		Index:   0,
+	}}
+	got, err := addrs.NestedSegwit(Bitcoin)
+	assert.NoError(t, err)
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, "37VucYSaXLCAsxYyAPfbSi9eh4iEcbShgf", got[0].Address)
+		assert.Equal(t, addrs[0].Pubkey, got[0].Pubkey)
+	}
+
+	_, err = addrs.NestedSegwit(Dogecoin)
+	assert.Error(t, err)
+	_, err = Addresses{{Pubkey: "zz"}}.NestedSegwit(Bitcoin)
+	assert.Error(t, err)
+}
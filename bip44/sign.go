@@ -0,0 +1,278 @@
+package bip44
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	"github.com/Pantani/errors"
+	"github.com/Pantani/pool-party/bip39"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// SignMessage signs the message with the address private key.
+// Ethereum based coins use the EIP-191 personal_sign format and return the hex signature,
+// the other coins use the Bitcoin signmessage format and return the base64 signature.
+// It returns the signature and an error if occurs
+func (a Address) SignMessage(coin Coin, message []byte) (string, error) {
+	altcoin, ok := CoinList[coin]
+	if !ok {
+		return "", errors.E("Invalid coin", errors.Params{"coin": coin})
+	}
+	if altcoin.IsEthereum() {
+		return a.SignPersonalMessage(message)
+	}
+	privk, err := a.privateKey()
+	if err != nil {
+		return "", err
+	}
+	defer WipePrivateKey(privk)
+	hash, err := messageHash(altcoin, message)
+	if err != nil {
+		return "", err
+	}
+	sig, err := btcec.SignCompact(btcec.S256(), privk, hash, true)
+	if err != nil {
+		return "", errors.E(err, "error to sign the message", errors.Params{"address": a.Address})
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// RecoverMessage recovers the signer address of a message signed by SignMessage
+// It returns the signer address and an error if occurs
+func RecoverMessage(coin Coin, message []byte, signature string) (string, error) {
+	altcoin, ok := CoinList[coin]
+	if !ok {
+		return "", errors.E("Invalid coin", errors.Params{"coin": coin})
+	}
+	if altcoin.IsEthereum() {
+		return RecoverPersonalMessage(message, signature)
+	}
+	pubkey, _, err := recoverMessagePubKey(altcoin, message, signature)
+	if err != nil {
+		return "", err
+	}
+	address, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(pubkey), altcoin.Params())
+	if err != nil {
+		return "", err
+	}
+	return address.EncodeAddress(), nil
+}
+
+// VerifyMessage verifies if the message was signed by the address.
+// The address can be the P2PKH address of the signer or, for the segwit coins,
+// its native segwit (P2WPKH) or nested segwit (P2SH-P2WPKH) address, like the addresses of
+// the Electrum segwit and LND pools.
+// It returns true if the recovered signer matches the address and an error if occurs
+func VerifyMessage(coin Coin, address string, message []byte, signature string) (bool, error) {
+	altcoin, ok := CoinList[coin]
+	if !ok {
+		return false, errors.E("Invalid coin", errors.Params{"coin": coin})
+	}
+	if altcoin.IsEthereum() {
+		signer, err := RecoverPersonalMessage(message, signature)
+		if err != nil {
+			return false, err
+		}
+		return common.HexToAddress(signer) == common.HexToAddress(address), nil
+	}
+	pubkey, compressed, err := recoverMessagePubKey(altcoin, message, signature)
+	if err != nil {
+		return false, err
+	}
+	signer, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(pubkey), altcoin.Params())
+	if err != nil {
+		return false, err
+	}
+	if signer.EncodeAddress() == address {
+		return true, nil
+	}
+	// segwit addresses only commit to compressed public keys
+	if !compressed || !altcoin.SupportsSegwit() {
+		return false, nil
+	}
+	witness, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(pubkey), altcoin.Params())
+	if err != nil {
+		return false, err
+	}
+	if witness.EncodeAddress() == strings.ToLower(address) {
+		return true, nil
+	}
+	nested, err := nestedSegwitAddress(pubkey, altcoin.Params())
+	if err != nil {
+		return false, err
+	}
+	return nested.EncodeAddress() == address, nil
+}
+
+// recoverMessagePubKey recovers the serialized public key of a Bitcoin signmessage signature.
+// The BIP137 segwit headers (35-42) are read as the compressed key headers.
+func recoverMessagePubKey(coin *Altcoin, message []byte, signature string) ([]byte, bool, error) {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return nil, false, errors.E(err, "invalid base64 signature")
+	}
+	if len(sig) > 0 && sig[0] >= 35 && sig[0] <= 42 {
+		sig[0] = 31 + (sig[0]-35)%4
+	}
+	hash, err := messageHash(coin, message)
+	if err != nil {
+		return nil, false, err
+	}
+	pubk, compressed, err := btcec.RecoverCompact(btcec.S256(), sig, hash)
+	if err != nil {
+		return nil, false, errors.E(err, "error to recover the public key")
+	}
+	if compressed {
+		return pubk.SerializeCompressed(), true, nil
+	}
+	return pubk.SerializeUncompressed(), false, nil
+}
+
+// SignPersonalMessage signs the message using the EIP-191 personal_sign format:
+// keccak256("\x19Ethereum Signed Message:\n" + len(message) + message)
+// It returns the hex signature with the recovery id as 27/28 and an error if occurs
+func (a Address) SignPersonalMessage(message []byte) (string, error) {
+	return a.signEthereumHash(accounts.TextHash(message))
+}
+
+// RecoverPersonalMessage recovers the signer address of an EIP-191 personal_sign signature
+// It returns the signer address and an error if occurs
+func RecoverPersonalMessage(message []byte, signature string) (string, error) {
+	return recoverEthereumHash(accounts.TextHash(message), signature)
+}
+
+// VerifyPersonalMessage verifies if the EIP-191 message was signed by the address
+// It returns true if the recovered signer matches the address and an error if occurs
+func VerifyPersonalMessage(address string, message []byte, signature string) (bool, error) {
+	signer, err := RecoverPersonalMessage(message, signature)
+	if err != nil {
+		return false, err
+	}
+	return common.HexToAddress(signer) == common.HexToAddress(address), nil
+}
+
+// SignTypedData signs the EIP-712 typed data:
+// keccak256("\x19\x01" + domainSeparator + hashStruct(message))
+// It returns the hex signature with the recovery id as 27/28 and an error if occurs
+func (a Address) SignTypedData(typedData apitypes.TypedData) (string, error) {
+	hash, err := TypedDataHash(typedData)
+	if err != nil {
+		return "", err
+	}
+	return a.signEthereumHash(hash)
+}
+
+// RecoverTypedData recovers the signer address of an EIP-712 typed data signature
+// It returns the signer address and an error if occurs
+func RecoverTypedData(typedData apitypes.TypedData, signature string) (string, error) {
+	hash, err := TypedDataHash(typedData)
+	if err != nil {
+		return "", err
+	}
+	return recoverEthereumHash(hash, signature)
+}
+
+// VerifyTypedData verifies if the EIP-712 typed data was signed by the address
+// It returns true if the recovered signer matches the address and an error if occurs
+func VerifyTypedData(address string, typedData apitypes.TypedData, signature string) (bool, error) {
+	signer, err := RecoverTypedData(typedData, signature)
+	if err != nil {
+		return false, err
+	}
+	return common.HexToAddress(signer) == common.HexToAddress(address), nil
+}
+
+// TypedDataHash calculates the EIP-712 hash to be signed
+// It returns the hash and an error if occurs
+func TypedDataHash(typedData apitypes.TypedData) ([]byte, error) {
+	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	if err != nil {
+		return nil, errors.E(err, "error to hash the typed data domain")
+	}
+	typedDataHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		return nil, errors.E(err, "error to hash the typed data message", errors.Params{"primaryType": typedData.PrimaryType})
+	}
+	rawData := append([]byte("\x19\x01"), domainSeparator...)
+	rawData = append(rawData, typedDataHash...)
+	return crypto.Keccak256(rawData), nil
+}
+
+// signEthereumHash signs the hash with the address private key
+func (a Address) signEthereumHash(hash []byte) (string, error) {
+	if !bytes.HasPrefix(a.Privkey, []byte("0x")) {
+		return "", errors.E("address is not an Ethereum address", errors.Params{"address": a.Address})
+	}
+	privk, err := a.privateKey()
+	if err != nil {
+		return "", err
+	}
+	defer WipePrivateKey(privk)
+	sig, err := crypto.Sign(hash, privk.ToECDSA())
+	if err != nil {
+		return "", errors.E(err, "error to sign the message", errors.Params{"address": a.Address})
+	}
+	// transform the recovery id to the 27/28 convention used by personal_sign and eth_signTypedData
+	sig[crypto.RecoveryIDOffset] += 27
+	return hexutil.Encode(sig), nil
+}
+
+// recoverEthereumHash recovers the signer address of a hash signature
+func recoverEthereumHash(hash []byte, signature string) (string, error) {
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return "", errors.E(err, "invalid hex signature")
+	}
+	if len(sig) != crypto.SignatureLength {
+		return "", errors.E("invalid signature length", errors.Params{"length": len(sig)})
+	}
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pubk, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return "", errors.E(err, "error to recover the public key")
+	}
+	return crypto.PubkeyToAddress(*pubk).String(), nil
+}
+
+// messageHash calculates the Bitcoin signmessage double sha256 hash, prefixed with the coin magic
+func messageHash(coin *Altcoin, message []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := wire.WriteVarString(&buf, 0, coin.MessagePrefix); err != nil {
+		return nil, err
+	}
+	if err := wire.WriteVarBytes(&buf, 0, message); err != nil {
+		return nil, err
+	}
+	return chainhash.DoubleHashB(buf.Bytes()), nil
+}
+
+// privateKey decodes the address private key, as a 0x hex string for Ethereum
+// addresses or a WIF for the other coins
+func (a Address) privateKey() (*btcec.PrivateKey, error) {
+	if bytes.HasPrefix(a.Privkey, []byte("0x")) {
+		pkb := make([]byte, hex.DecodedLen(len(a.Privkey)-2))
+		defer bip39.Zero(pkb)
+		if _, err := hex.Decode(pkb, a.Privkey[2:]); err != nil {
+			return nil, errors.E(err, "invalid private key", errors.Params{"address": a.Address})
+		}
+		privk, _ := btcec.PrivKeyFromBytes(btcec.S256(), pkb)
+		return privk, nil
+	}
+	wif, err := btcutil.DecodeWIF(string(a.Privkey))
+	if err != nil {
+		return nil, errors.E(err, "invalid WIF private key", errors.Params{"address": a.Address})
+	}
+	return wif.PrivKey, nil
+}
@@ -0,0 +1,158 @@
+package bip44
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+)
+
+// EIP-712 example from https://eips.ethereum.org/EIPS/eip-712
+const typedDataJSON = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": "1",
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func TestAddress_SignPersonalMessage(t *testing.T) {
+	// web3.eth.accounts.sign vector
+	addr := Address{
+		Address: "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23",
+		Privkey: []byte("0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"),
+	}
+	want := "0xb91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291c"
+
+	got, err := addr.SignPersonalMessage([]byte("Some data"))
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	signer, err := RecoverPersonalMessage([]byte("Some data"), got)
+	assert.NoError(t, err)
+	assert.Equal(t, addr.Address, signer)
+
+	ok, err := VerifyPersonalMessage(addr.Address, []byte("Other data"), got)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestAddress_SignTypedData(t *testing.T) {
+	var typedData apitypes.TypedData
+	assert.NoError(t, json.Unmarshal([]byte(typedDataJSON), &typedData))
+
+	// keccak256("cow")
+	addr := Address{
+		Address: "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826",
+		Privkey: []byte("0xc85ef7d79691fe79573b1a7064c19c1a9819ebdbd1faaab1a8ec92344438aaf4"),
+	}
+	want := "0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b915621c"
+
+	got, err := addr.SignTypedData(typedData)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	ok, err := VerifyTypedData(addr.Address, typedData, got)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	typedData.Message["contents"] = "Hello, Alice!"
+	ok, err = VerifyTypedData(addr.Address, typedData, got)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestAddress_SignMessage(t *testing.T) {
+	for coin := range CoinList {
+		t.Run(string(coin), func(t *testing.T) {
+			account, err := GenerateWallets(coin, mnemonic, "", 0, 2)
+			assert.NoError(t, err)
+			addr := account.Addresses[0]
+
+			sig, err := addr.SignMessage(coin, []byte("proof of ownership"))
+			assert.NoError(t, err)
+
+			signer, err := RecoverMessage(coin, []byte("proof of ownership"), sig)
+			assert.NoError(t, err)
+			assert.Equal(t, addr.Address, signer)
+
+			ok, err := VerifyMessage(coin, addr.Address, []byte("proof of ownership"), sig)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+
+			ok, err = VerifyMessage(coin, account.Addresses[1].Address, []byte("proof of ownership"), sig)
+			assert.NoError(t, err)
+			assert.False(t, ok)
+
+			ok, err = VerifyMessage(coin, addr.Address, []byte("another message"), sig)
+			assert.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestVerifyMessageSegwit(t *testing.T) {
+	account, err := GenerateWallets(Bitcoin, mnemonic, "", 0, 2)
+	assert.NoError(t, err)
+	addresses, err := account.Addresses.Segwit(Bitcoin)
+	assert.NoError(t, err)
+	addr := addresses[0]
+
+	sig, err := addr.SignMessage(Bitcoin, []byte("proof of ownership"))
+	assert.NoError(t, err)
+	ok, err := VerifyMessage(Bitcoin, addr.Address, []byte("proof of ownership"), sig)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = VerifyMessage(Bitcoin, strings.ToUpper(addr.Address), []byte("proof of ownership"), sig)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = VerifyMessage(Bitcoin, addresses[1].Address, []byte("proof of ownership"), sig)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// BIP137 P2WPKH header
+	raw, err := base64.StdEncoding.DecodeString(sig)
+	assert.NoError(t, err)
+	raw[0] += 8
+	ok, err = VerifyMessage(Bitcoin, addr.Address, []byte("proof of ownership"), base64.StdEncoding.EncodeToString(raw))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestVerifyMessageInvalidSignature(t *testing.T) {
+	_, err := VerifyMessage(Bitcoin, "1F3sAm6ZtwLAUnj7d38pGFxtP3RVEvtsbV", []byte("message"), "invalid base64")
+	assert.Error(t, err)
+
+	_, err = VerifyMessage(Ethereum, "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23", []byte("message"), "0x1234")
+	assert.Error(t, err)
+
+	_, err = VerifyMessage("Monero", "address", []byte("message"), "signature")
+	assert.Error(t, err)
+}
@@ -0,0 +1,50 @@
+package bip44
+
+import (
+	"math/big"
+
+	"github.com/Pantani/errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SignEthereumTx signs the Ethereum transaction with the address private key.
+// Legacy (EIP-155), access list (EIP-2930) and dynamic fee (EIP-1559) transactions are supported.
+// A nil chainID is only allowed for legacy transactions and creates a pre EIP-155 (Homestead) signature.
+// It returns the signed transaction and an error if occurs
+func (a Address) SignEthereumTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if tx == nil {
+		return nil, errors.E("empty transaction")
+	}
+	if !common.IsHexAddress(a.Address) {
+		return nil, errors.E("address is not an Ethereum address", errors.Params{"address": a.Address})
+	}
+	var signer types.Signer
+	switch {
+	case chainID != nil:
+		signer = types.LatestSignerForChainID(chainID)
+	case tx.Type() == types.LegacyTxType:
+		signer = types.HomesteadSigner{}
+	default:
+		return nil, errors.E("chain id is required for typed transactions", errors.Params{"type": tx.Type()})
+	}
+	if tx.Type() != types.LegacyTxType && tx.ChainId().Sign() != 0 && tx.ChainId().Cmp(chainID) != 0 {
+		return nil, errors.E("transaction chain id mismatch", errors.Params{"txChainID": tx.ChainId(), "chainID": chainID})
+	}
+
+	privk, err := a.privateKey()
+	if err != nil {
+		return nil, err
+	}
+	defer WipePrivateKey(privk)
+	key := privk.ToECDSA()
+	if crypto.PubkeyToAddress(key.PublicKey) != common.HexToAddress(a.Address) {
+		return nil, errors.E("private key doesn't match the address", errors.Params{"address": a.Address})
+	}
+	signed, err := types.SignTx(tx, signer, key)
+	if err != nil {
+		return nil, errors.E(err, "error to sign the transaction", errors.Params{"address": a.Address, "type": tx.Type()})
+	}
+	return signed, nil
+}
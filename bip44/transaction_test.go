@@ -0,0 +1,85 @@
+package bip44
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddress_SignEthereumTx(t *testing.T) {
+	account, err := GenerateWallets(Ethereum, mnemonic, "", 0, 1)
+	assert.NoError(t, err)
+	addr := account.Addresses[0]
+
+	to := common.HexToAddress("0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB")
+	chainID := big.NewInt(1)
+	accessList := types.AccessList{{Address: to, StorageKeys: []common.Hash{{0x01}}}}
+	tests := []struct {
+		name    string
+		tx      *types.Transaction
+		chainID *big.Int
+		wantErr bool
+	}{
+		{
+			name:    "legacy",
+			tx:      types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1e9), Gas: 21000, To: &to, Value: big.NewInt(1)}),
+			chainID: chainID,
+		},
+		{
+			name: "legacy homestead",
+			tx:   types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1e9), Gas: 21000, To: &to, Value: big.NewInt(1)}),
+		},
+		{
+			name:    "access list",
+			tx:      types.NewTx(&types.AccessListTx{ChainID: chainID, Nonce: 2, GasPrice: big.NewInt(1e9), Gas: 30000, To: &to, AccessList: accessList}),
+			chainID: chainID,
+		},
+		{
+			name:    "dynamic fee",
+			tx:      types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 3, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(2e9), Gas: 30000, To: &to, AccessList: accessList}),
+			chainID: chainID,
+		},
+		{
+			name:    "dynamic fee without chain id",
+			tx:      types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 3, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(2e9), Gas: 30000, To: &to}),
+			wantErr: true,
+		},
+		{
+			name:    "dynamic fee with wrong chain id",
+			tx:      types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(5), Nonce: 3, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(2e9), Gas: 30000, To: &to}),
+			chainID: chainID,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signed, err := addr.SignEthereumTx(tt.tx, tt.chainID)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.tx.Type(), signed.Type())
+
+			var signer types.Signer = types.HomesteadSigner{}
+			if tt.chainID != nil {
+				signer = types.LatestSignerForChainID(tt.chainID)
+			}
+			sender, err := types.Sender(signer, signed)
+			assert.NoError(t, err)
+			assert.Equal(t, common.HexToAddress(addr.Address), sender)
+		})
+	}
+}
+
+func TestAddress_SignEthereumTxNotEthereum(t *testing.T) {
+	account, err := GenerateWallets(Bitcoin, mnemonic, "", 0, 1)
+	assert.NoError(t, err)
+
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1e9), Gas: 21000})
+	_, err = account.Addresses[0].SignEthereumTx(tx, big.NewInt(1))
+	assert.Error(t, err)
+}
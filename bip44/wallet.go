@@ -0,0 +1,115 @@
+package bip44
+
+import (
+	"sort"
+
+	"github.com/Pantani/pool-party/bip39"
+)
+
+// Coins returns the supported coins sorted by name
+func Coins() []Coin {
+	coins := make([]Coin, 0, len(CoinList))
+	for coin := range CoinList {
+		coins = append(coins, coin)
+	}
+	sort.Slice(coins, func(i, j int) bool { return coins[i] < coins[j] })
+	return coins
+}
+
+// GenerateWallet generates the multi-coin wallet with one account per coin, each one with
+// the receive addresses from start to start+qty derived by the BIP44 path m/44'/cointype'/0'/0/i.
+// The bip39 seed is computed once for all the coins, if no coin is passed every supported coin
+// is derived. The wallet keeps the seed, it must be wiped by the caller.
+// It returns the wallet and an error if occurs
+func GenerateWallet(mnemonic, passphrase string, coins []Coin, start, qty int) (*Wallet, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return newWallet(seed, []byte(mnemonic), coins, start, qty)
+}
+
+// GenerateWalletBytes is like GenerateWallet, but the mnemonic and passphrase are byte slices,
+// so the secrets are never copied to strings. The wallet keeps a copy of the mnemonic, the
+// caller can zero its slices after the call.
+// It returns the wallet and an error if occurs
+func GenerateWalletBytes(mnemonic, passphrase []byte, coins []Coin, start, qty int) (*Wallet, error) {
+	seed, err := bip39.NewSeedBytes(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	seedwords := make([]byte, len(mnemonic))
+	copy(seedwords, mnemonic)
+	return newWallet(seed, seedwords, coins, start, qty)
+}
+
+// newWallet creates the wallet of the seed and derives the accounts of the coins,
+// the wallet is wiped if the derivation fails
+func newWallet(seed, seedwords []byte, coins []Coin, start, qty int) (*Wallet, error) {
+	wallet := &Wallet{Seed: seed, Seedwords: seedwords}
+	if err := wallet.GenerateAccounts(coins, start, qty); err != nil {
+		wallet.Wipe()
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// GenerateAccounts derives the wallet seed accounts of the coins, with the receive addresses
+// from start to start+qty, replacing the accounts already generated for the coins.
+// If no coin is passed every supported coin is derived, a coin passed more than once is
+// derived once.
+// It returns an error if occurs
+func (w *Wallet) GenerateAccounts(coins []Coin, start, qty int) error {
+	if len(coins) == 0 {
+		coins = Coins()
+	}
+	coins = uniqueCoins(coins)
+	accounts := make([]Account, 0, len(coins))
+	for _, coin := range coins {
+		account, err := GenerateWalletsFromSeed(coin, w.Seed, DefaultPathTemplate, 0, start, qty)
+		if err != nil {
+			for i := range accounts {
+				accounts[i].Wipe()
+			}
+			return err
+		}
+		accounts = append(accounts, *account)
+	}
+	for _, account := range accounts {
+		if existing := w.Account(Coin(account.Coin)); existing != nil {
+			existing.Wipe()
+			*existing = account
+			continue
+		}
+		w.Accounts = append(w.Accounts, account)
+	}
+	return nil
+}
+
+// uniqueCoins returns the coins without the repeated ones, keeping the order
+func uniqueCoins(coins []Coin) []Coin {
+	seen := make(map[Coin]bool, len(coins))
+	result := make([]Coin, 0, len(coins))
+	for _, coin := range coins {
+		if seen[coin] {
+			continue
+		}
+		seen[coin] = true
+		result = append(result, coin)
+	}
+	return result
+}
+
+// Account returns the wallet account of the coin, or nil if it wasn't generated
+func (w *Wallet) Account(coin Coin) *Account {
+	c, ok := CoinList[coin]
+	if !ok {
+		return nil
+	}
+	for i := range w.Accounts {
+		if w.Accounts[i].Coin == c.Name {
+			return &w.Accounts[i]
+		}
+	}
+	return nil
+}
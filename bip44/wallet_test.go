@@ -0,0 +1,106 @@
+package bip44
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCoins(t *testing.T) {
+	want := []Coin{Bitcoin, Dash, Dogecoin, Energi, Ethereum, Litecoin}
+	if got := Coins(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Coins() = %v, want %v", got, want)
+	}
+}
+
+func TestGenerateWallet(t *testing.T) {
+	wallet, err := GenerateWallet(mnemonic, "", nil, 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wallet.Wipe()
+	if len(wallet.Accounts) != len(CoinList) {
+		t.Fatalf("GenerateWallet() got %d accounts, want %d", len(wallet.Accounts), len(CoinList))
+	}
+	for _, coin := range Coins() {
+		want, err := GenerateWallets(coin, mnemonic, "", 0, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		account := wallet.Account(coin)
+		if account == nil {
+			t.Fatalf("Wallet.Account(%v) = nil", coin)
+		}
+		if !reflect.DeepEqual(account.Addresses, want.Addresses) {
+			t.Errorf("GenerateWallet() %v addresses = %v, want %v", coin, account.Addresses, want.Addresses)
+		}
+	}
+
+	// regenerating a coin replaces its account
+	if err := wallet.GenerateAccounts([]Coin{Bitcoin}, 10, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(wallet.Accounts) != len(CoinList) || wallet.Account(Bitcoin).Addresses[0].Index != 10 {
+		t.Errorf("Wallet.GenerateAccounts() didn't replace the Bitcoin account")
+	}
+}
+
+func TestUniqueCoins(t *testing.T) {
+	got := uniqueCoins([]Coin{Bitcoin, Ethereum, Bitcoin, Ethereum, Dash})
+	want := []Coin{Bitcoin, Ethereum, Dash}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("uniqueCoins() = %v, want %v", got, want)
+	}
+}
+
+func TestGenerateWalletDuplicatedCoins(t *testing.T) {
+	wallet, err := GenerateWallet(mnemonic, "", []Coin{Bitcoin, Ethereum, Bitcoin}, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wallet.Wipe()
+	if len(wallet.Accounts) != 2 {
+		t.Fatalf("GenerateWallet() got %d accounts, want 2", len(wallet.Accounts))
+	}
+	if err := wallet.GenerateAccounts([]Coin{Ethereum, Ethereum}, 5, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(wallet.Accounts) != 2 || wallet.Account(Ethereum).Addresses[0].Index != 5 {
+		t.Errorf("Wallet.GenerateAccounts() accounts = %v, want Bitcoin and Ethereum from 5", wallet.Accounts)
+	}
+}
+
+func TestGenerateWalletBytes(t *testing.T) {
+	want, err := GenerateWallet(mnemonic, "passphrase", []Coin{Bitcoin, Ethereum}, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer want.Wipe()
+	seedwords := []byte(mnemonic)
+	wallet, err := GenerateWalletBytes(seedwords, []byte("passphrase"), []Coin{Bitcoin, Ethereum}, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wallet.Wipe()
+	// the wallet keeps its own copy of the mnemonic
+	for i := range seedwords {
+		seedwords[i] = 0
+	}
+	if !reflect.DeepEqual(wallet, want) {
+		t.Errorf("GenerateWalletBytes() = %v, want %v", wallet, want)
+	}
+	if _, err := GenerateWalletBytes([]byte("abandon abandon"), nil, nil, 0, 1); err == nil {
+		t.Error("GenerateWalletBytes() expected an error for an invalid mnemonic")
+	}
+}
+
+func TestGenerateWalletInvalid(t *testing.T) {
+	if _, err := GenerateWallet(mnemonic, "", []Coin{Bitcoin, "Monero"}, 0, 1); err == nil {
+		t.Error("GenerateWallet() expected an error for an invalid coin")
+	}
+	if _, err := GenerateWallet("abandon abandon", "", nil, 0, 1); err == nil {
+		t.Error("GenerateWallet() expected an error for an invalid mnemonic")
+	}
+	if account := (&Wallet{}).Account(Bitcoin); account != nil {
+		t.Errorf("Wallet.Account() = %v, want nil", account)
+	}
+}
@@ -0,0 +1,97 @@
+package pool_party
+
+import (
+	"github.com/Pantani/errors"
+	"github.com/Pantani/pool-party/bip32"
+	"github.com/Pantani/pool-party/bip85"
+)
+
+// ChildMnemonic derives the BIP85 child mnemonic of the pool master key, with 12 to 24 words
+// in the language, for hot wallets backed up by the pool seed.
+// It returns the mnemonic and an error if occurs
+func (p *Pool) ChildMnemonic(language bip85.Language, words, index uint32) (string, error) {
+	var mnemonic string
+	err := p.withMasterKey(func(master *bip32.Key) (err error) {
+		mnemonic, err = bip85.Mnemonic(master, language, words, index)
+		return err
+	})
+	if err != nil {
+		return "", errors.E(err, "error to derive the child mnemonic", errors.Params{"language": language, "words": words, "index": index})
+	}
+	return mnemonic, nil
+}
+
+// ChildWIF derives the BIP85 child Bitcoin private key of the pool master key
+// It returns the key in the wallet import format and an error if occurs
+func (p *Pool) ChildWIF(index uint32) (string, error) {
+	var wif string
+	err := p.withMasterKey(func(master *bip32.Key) (err error) {
+		wif, err = bip85.WIF(master, index)
+		return err
+	})
+	if err != nil {
+		return "", errors.E(err, "error to derive the child WIF", errors.Params{"index": index})
+	}
+	return wif, nil
+}
+
+// ChildXPRV derives the BIP85 child master extended private key of the pool master key
+// It returns the serialized xprv and an error if occurs
+func (p *Pool) ChildXPRV(index uint32) (string, error) {
+	var xprv string
+	err := p.withMasterKey(func(master *bip32.Key) error {
+		key, err := bip85.XPRV(master, index)
+		if err != nil {
+			return err
+		}
+		defer key.Wipe()
+		xprv = key.B58Serialize()
+		return nil
+	})
+	if err != nil {
+		return "", errors.E(err, "error to derive the child xprv", errors.Params{"index": index})
+	}
+	return xprv, nil
+}
+
+// ChildHex derives the BIP85 child hex secret of 16 to 64 bytes of the pool master key
+// It returns the hex secret and an error if occurs
+func (p *Pool) ChildHex(bytes, index uint32) (string, error) {
+	var secret string
+	err := p.withMasterKey(func(master *bip32.Key) (err error) {
+		secret, err = bip85.Hex(master, bytes, index)
+		return err
+	})
+	if err != nil {
+		return "", errors.E(err, "error to derive the child hex", errors.Params{"bytes": bytes, "index": index})
+	}
+	return secret, nil
+}
+
+// ChildPassword derives the BIP85 child base64 password of 20 to 86 characters of the pool master key
+// It returns the password and an error if occurs
+func (p *Pool) ChildPassword(length, index uint32) (string, error) {
+	var password string
+	err := p.withMasterKey(func(master *bip32.Key) (err error) {
+		password, err = bip85.PasswordBase64(master, length, index)
+		return err
+	})
+	if err != nil {
+		return "", errors.E(err, "error to derive the child password", errors.Params{"length": length, "index": index})
+	}
+	return password, nil
+}
+
+// withMasterKey calls the function with the pool master key, wiping it after
+// It returns the function error or an error if occurs
+func (p *Pool) withMasterKey(f func(master *bip32.Key) error) error {
+	if p.descriptor != nil {
+		return errors.E("watch-only pool has no private keys", errors.Params{"coin": p.coin})
+	}
+	master, err := p.masterKey()
+	if err != nil {
+		return err
+	}
+	defer master.Wipe()
+	return f(master)
+}
@@ -0,0 +1,220 @@
+// Package bip85 implements the deterministic entropy from BIP32 keychains (BIP85), to derive
+// child mnemonics, WIF keys, extended private keys, hex secrets and passwords from one
+// backed-up master key.
+//
+// The BIP85 spec can be found at
+// https://github.com/bitcoin/bips/blob/master/bip-0085.mediawiki
+package bip85
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/Pantani/pool-party/bip32"
+	"github.com/Pantani/pool-party/bip39"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/tyler-smith/go-bip39/wordlists"
+)
+
+const (
+	// Purpose is the first hardened index of the BIP85 derivation paths, m/83696968'
+	Purpose = uint32(83696968)
+
+	// the application numbers of the derivation paths
+	appBIP39     = uint32(39)
+	appWIF       = uint32(2)
+	appXPRV      = uint32(32)
+	appHex       = uint32(128169)
+	appPwdBase64 = uint32(707764)
+)
+
+var (
+	// ErrNotMasterKey is returned when the entropy is derived from a public or non root key
+	ErrNotMasterKey = errors.New("bip85 requires the master private key")
+
+	// ErrUnsupportedLanguage is returned for the languages without word list
+	ErrUnsupportedLanguage = errors.New("unsupported bip39 language")
+
+	// ErrInvalidLength is returned when the mnemonic words, hex bytes or password
+	// length are out of the application range
+	ErrInvalidLength = errors.New("invalid bip85 length")
+
+	// ErrInvalidIndex is returned when an index of the path is already hardened,
+	// the indexes are hardened by the derivation
+	ErrInvalidIndex = errors.New("invalid bip85 index")
+
+	// ErrInvalidPrivateKey is returned when the derived entropy is not a valid
+	// private key, which has a negligible probability
+	ErrInvalidPrivateKey = errors.New("derived entropy is not a valid private key")
+
+	// hmacKey is the HMAC-SHA512 key of the entropy derivation
+	hmacKey = []byte("bip-entropy-from-k")
+)
+
+// Language is the language code of the BIP39 application
+type Language uint32
+
+const (
+	English Language = iota
+	Japanese
+	Korean
+	Spanish
+	ChineseSimplified
+	ChineseTraditional
+	French
+	Italian
+	Czech
+	Portuguese
+)
+
+// wordLists are the BIP39 word lists of the languages
+var wordLists = map[Language][]string{
+	English:            wordlists.English,
+	Japanese:           wordlists.Japanese,
+	Korean:             wordlists.Korean,
+	Spanish:            wordlists.Spanish,
+	ChineseSimplified:  wordlists.ChineseSimplified,
+	ChineseTraditional: wordlists.ChineseTraditional,
+	French:             wordlists.French,
+	Italian:            wordlists.Italian,
+	Czech:              wordlists.Czech,
+	Portuguese:         bip39.Portuguese,
+}
+
+// Entropy derives the 64 bytes of entropy of the path from the master key, the HMAC-SHA512
+// of the derived private key. Every index of the path is hardened, so the indexes must be
+// lower than bip32.FirstHardenedChild.
+// It returns the entropy and an error if occurs
+func Entropy(master *bip32.Key, path bip32.DerivationPath) ([]byte, error) {
+	if master == nil || !master.IsPrivate || master.Depth != 0 {
+		return nil, ErrNotMasterKey
+	}
+	hardened := make(bip32.DerivationPath, 0, len(path)+1)
+	hardened = append(hardened, Purpose+bip32.FirstHardenedChild)
+	for _, index := range path {
+		if index >= bip32.FirstHardenedChild {
+			return nil, fmt.Errorf("%w: %d", ErrInvalidIndex, index)
+		}
+		hardened = append(hardened, index+bip32.FirstHardenedChild)
+	}
+	key, err := master.DerivePath(hardened)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Wipe()
+	mac := hmac.New(sha512.New, hmacKey)
+	mac.Write(key.Key)
+	return mac.Sum(nil), nil
+}
+
+// Mnemonic derives the BIP39 child mnemonic of 12, 15, 18, 21 or 24 words in the language
+// m/83696968'/39'/{language}'/{words}'/{index}'
+// It returns the mnemonic and an error if occurs
+func Mnemonic(master *bip32.Key, language Language, words, index uint32) (string, error) {
+	list, ok := wordLists[language]
+	if !ok {
+		return "", fmt.Errorf("%w: %d", ErrUnsupportedLanguage, language)
+	}
+	if words < 12 || words > 24 || words%3 != 0 {
+		return "", fmt.Errorf("%w: %d words", ErrInvalidLength, words)
+	}
+	entropy, err := Entropy(master, bip32.DerivationPath{appBIP39, uint32(language), words, index})
+	if err != nil {
+		return "", err
+	}
+	defer bip39.Zero(entropy)
+	separator := " "
+	if language == Japanese {
+		separator = "　"
+	}
+	mnemonic, err := bip39.NewMnemonicBytesWithWordList(entropy[:words*4/3], list, separator)
+	if err != nil {
+		return "", err
+	}
+	defer bip39.Zero(mnemonic)
+	return string(mnemonic), nil
+}
+
+// WIF derives the compressed Bitcoin mainnet private key, m/83696968'/2'/{index}'
+// It returns the key in the wallet import format and an error if occurs
+func WIF(master *bip32.Key, index uint32) (string, error) {
+	entropy, err := Entropy(master, bip32.DerivationPath{appWIF, index})
+	if err != nil {
+		return "", err
+	}
+	defer bip39.Zero(entropy)
+	if !isValidPrivateKey(entropy[:32]) {
+		return "", ErrInvalidPrivateKey
+	}
+	privk, _ := btcec.PrivKeyFromBytes(btcec.S256(), entropy[:32])
+	wif, err := btcutil.NewWIF(privk, &chaincfg.MainNetParams, true)
+	if err != nil {
+		return "", err
+	}
+	return wif.String(), nil
+}
+
+// XPRV derives the child master extended private key, m/83696968'/32'/{index}',
+// the chain code is the first 32 bytes of the entropy and the private key the last 32 bytes
+// It returns the extended key and an error if occurs
+func XPRV(master *bip32.Key, index uint32) (*bip32.Key, error) {
+	entropy, err := Entropy(master, bip32.DerivationPath{appXPRV, index})
+	if err != nil {
+		return nil, err
+	}
+	defer bip39.Zero(entropy)
+	if !isValidPrivateKey(entropy[32:]) {
+		return nil, ErrInvalidPrivateKey
+	}
+	return &bip32.Key{
+		Version:     bip32.PrivateWalletVersion,
+		ChildNumber: []byte{0x00, 0x00, 0x00, 0x00},
+		FingerPrint: []byte{0x00, 0x00, 0x00, 0x00},
+		ChainCode:   append([]byte{}, entropy[:32]...),
+		Key:         append([]byte{}, entropy[32:]...),
+		Depth:       0x0,
+		IsPrivate:   true,
+	}, nil
+}
+
+// Hex derives the hex secret of 16 to 64 bytes, m/83696968'/128169'/{bytes}'/{index}'
+// It returns the hex secret and an error if occurs
+func Hex(master *bip32.Key, bytes, index uint32) (string, error) {
+	if bytes < 16 || bytes > 64 {
+		return "", fmt.Errorf("%w: %d bytes", ErrInvalidLength, bytes)
+	}
+	entropy, err := Entropy(master, bip32.DerivationPath{appHex, bytes, index})
+	if err != nil {
+		return "", err
+	}
+	defer bip39.Zero(entropy)
+	return hex.EncodeToString(entropy[:bytes]), nil
+}
+
+// PasswordBase64 derives the base64 password of 20 to 86 characters,
+// m/83696968'/707764'/{length}'/{index}'
+// It returns the password and an error if occurs
+func PasswordBase64(master *bip32.Key, length, index uint32) (string, error) {
+	if length < 20 || length > 86 {
+		return "", fmt.Errorf("%w: %d characters", ErrInvalidLength, length)
+	}
+	entropy, err := Entropy(master, bip32.DerivationPath{appPwdBase64, length, index})
+	if err != nil {
+		return "", err
+	}
+	defer bip39.Zero(entropy)
+	return base64.StdEncoding.EncodeToString(entropy)[:length], nil
+}
+
+// isValidPrivateKey returns true if the key is in the range [1, n-1] of the curve order
+func isValidPrivateKey(key []byte) bool {
+	k := new(big.Int).SetBytes(key)
+	return k.Sign() > 0 && k.Cmp(btcec.S256().N) < 0
+}
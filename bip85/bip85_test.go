@@ -0,0 +1,153 @@
+package bip85
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Pantani/pool-party/bip32"
+	"github.com/Pantani/pool-party/bip39"
+	"github.com/stretchr/testify/assert"
+	"github.com/tyler-smith/go-bip39/wordlists"
+)
+
+// testMasterKey is the master key of the BIP85 test vectors
+const testMasterKey = "xprv9s21ZrQH143K2LBWUUQRFXhucrQqBpKdRRxNVq2zBqsx8HVqFk2uYo8kmbaLLHRdqtQpUm98uKfu3vca1LqdGhUtyoFnCNkfmXRyPXLjbKb"
+
+func masterKey(t *testing.T) *bip32.Key {
+	master, err := bip32.B58Deserialize(testMasterKey)
+	assert.NoError(t, err)
+	return master
+}
+
+func TestEntropy(t *testing.T) {
+	tests := []struct {
+		path bip32.DerivationPath
+		want string
+	}{
+		// m/83696968'/0'/0'
+		{path: bip32.DerivationPath{0, 0}, want: "efecfbccffea313214232d29e71563d941229afb4338c21f9517c41aaa0d16f00b83d2a09ef747e7a64e8e2bd5a14869e693da66ce94ac2da570ab7ee48618f7"},
+		// m/83696968'/0'/1'
+		{path: bip32.DerivationPath{0, 1}, want: "70c6e3e8ebee8dc4c0dbba66076819bb8c09672527c4277ca8729532ad711872218f826919f6b67218adde99018a6df9095ab2b58d803b5b93ec9802085a690e"},
+	}
+	master := masterKey(t)
+	for _, tt := range tests {
+		entropy, err := Entropy(master, tt.path)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, hex.EncodeToString(entropy))
+	}
+
+	// the indexes are hardened by the derivation, hardened indexes are rejected
+	for _, path := range []bip32.DerivationPath{{bip32.FirstHardenedChild, 0}, {0, bip32.FirstHardenedChild + 1}, {0, 0xffffffff}} {
+		_, err := Entropy(master, path)
+		assert.True(t, errors.Is(err, ErrInvalidIndex), path)
+	}
+	_, err := WIF(master, bip32.FirstHardenedChild)
+	assert.True(t, errors.Is(err, ErrInvalidIndex))
+
+	_, err = Entropy(master.PublicKey(), bip32.DerivationPath{0, 0})
+	assert.Equal(t, ErrNotMasterKey, err)
+	child, err := master.NewChildKey(bip32.FirstHardenedChild)
+	assert.NoError(t, err)
+	_, err = Entropy(child, bip32.DerivationPath{0, 0})
+	assert.Equal(t, ErrNotMasterKey, err)
+}
+
+func TestMnemonic(t *testing.T) {
+	tests := []struct {
+		words uint32
+		want  string
+	}{
+		{words: 12, want: "girl mad pet galaxy egg matter matrix prison refuse sense ordinary nose"},
+		{words: 18, want: "near account window bike charge season chef number sketch tomorrow excuse sniff circle vital hockey outdoor supply token"},
+		{words: 24, want: "puppy ocean match cereal symbol another shed magic wrap hammer bulb intact gadget divorce twin tonight reason outdoor destroy simple truth cigar social volcano"},
+	}
+	master := masterKey(t)
+	for _, tt := range tests {
+		mnemonic, err := Mnemonic(master, English, tt.words, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, mnemonic)
+	}
+
+	for _, words := range []uint32{15, 21} {
+		mnemonic, err := Mnemonic(master, English, words, 0)
+		assert.NoError(t, err)
+		assert.Len(t, strings.Fields(mnemonic), int(words))
+	}
+
+	// the language is part of the path, the mnemonic is valid with the language word list
+	for language, list := range wordLists {
+		mnemonic, err := Mnemonic(master, language, 12, 0)
+		assert.NoError(t, err)
+		separator := " "
+		if language == Japanese {
+			separator = "　"
+		}
+		words := strings.Split(mnemonic, separator)
+		assert.Len(t, words, 12)
+		english := make([]string, len(words))
+		for i, word := range words {
+			index := indexOf(list, word)
+			assert.NotEqual(t, -1, index, word)
+			english[i] = wordlists.English[index]
+		}
+		assert.True(t, bip39.IsMnemonicValid(strings.Join(english, " ")), mnemonic)
+	}
+
+	// computed with the BIP39 Portuguese word list, m/83696968'/39'/9'/12'/0'
+	mnemonic, err := Mnemonic(master, Portuguese, 12, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "rota ossada infrator diocese tedioso ciranda arroba gelo oposto veicular visto creche", mnemonic)
+	_, err = Mnemonic(master, Language(10), 12, 0)
+	assert.True(t, errors.Is(err, ErrUnsupportedLanguage))
+	for _, words := range []uint32{0, 11, 13, 27} {
+		_, err = Mnemonic(master, English, words, 0)
+		assert.True(t, errors.Is(err, ErrInvalidLength))
+	}
+}
+
+// indexOf returns the index of the word in the list or -1
+func indexOf(list []string, word string) int {
+	for i := range list {
+		if list[i] == word {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestWIF(t *testing.T) {
+	wif, err := WIF(masterKey(t), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "Kzyv4uF39d4Jrw2W7UryTHwZr1zQVNk4dAFyqE6BuMrMh1Za7uhp", wif)
+}
+
+func TestXPRV(t *testing.T) {
+	key, err := XPRV(masterKey(t), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "xprv9s21ZrQH143K2srSbCSg4m4kLvPMzcWydgmKEnMmoZUurYuBuYG46c6P71UGXMzmriLzCCBvKQWBUv3vPB3m1SATMhp3uEjXHJ42jFg7myX", key.String())
+	assert.Equal(t, "ead0b33988a616cf6a497f1c169d9e92562604e38305ccd3fc96f2252c177682", hex.EncodeToString(key.Key))
+}
+
+func TestHex(t *testing.T) {
+	secret, err := Hex(masterKey(t), 64, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "492db4698cf3b73a5a24998aa3e9d7fa96275d85724a91e71aa2d645442f878555d078fd1f1f67e368976f04137b1f7a0d19232136ca50c44614af72b5582a5c", secret)
+
+	for _, bytes := range []uint32{15, 65} {
+		_, err = Hex(masterKey(t), bytes, 0)
+		assert.True(t, errors.Is(err, ErrInvalidLength))
+	}
+}
+
+func TestPasswordBase64(t *testing.T) {
+	password, err := PasswordBase64(masterKey(t), 21, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "dKLoepugzdVJvdL56ogNV", password)
+
+	for _, length := range []uint32{19, 87} {
+		_, err = PasswordBase64(masterKey(t), length, 0)
+		assert.True(t, errors.Is(err, ErrInvalidLength))
+	}
+}
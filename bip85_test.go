@@ -0,0 +1,41 @@
+package pool_party
+
+import (
+	"testing"
+
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/Pantani/pool-party/bip85"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool_BIP85(t *testing.T) {
+	// master key of the BIP85 test vectors
+	pool, err := NewPoolWithExtendedKey(bip44.Bitcoin, "xprv9s21ZrQH143K2LBWUUQRFXhucrQqBpKdRRxNVq2zBqsx8HVqFk2uYo8kmbaLLHRdqtQpUm98uKfu3vca1LqdGhUtyoFnCNkfmXRyPXLjbKb")
+	assert.NoError(t, err)
+
+	mnemonic, err := pool.ChildMnemonic(bip85.English, 12, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "girl mad pet galaxy egg matter matrix prison refuse sense ordinary nose", mnemonic)
+	wif, err := pool.ChildWIF(0)
+	assert.NoError(t, err)
+	assert.Equal(t, "Kzyv4uF39d4Jrw2W7UryTHwZr1zQVNk4dAFyqE6BuMrMh1Za7uhp", wif)
+	xprv, err := pool.ChildXPRV(0)
+	assert.NoError(t, err)
+	assert.Equal(t, "xprv9s21ZrQH143K2srSbCSg4m4kLvPMzcWydgmKEnMmoZUurYuBuYG46c6P71UGXMzmriLzCCBvKQWBUv3vPB3m1SATMhp3uEjXHJ42jFg7myX", xprv)
+	secret, err := pool.ChildHex(64, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "492db4698cf3b73a5a24998aa3e9d7fa96275d85724a91e71aa2d645442f878555d078fd1f1f67e368976f04137b1f7a0d19232136ca50c44614af72b5582a5c", secret)
+	password, err := pool.ChildPassword(21, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "dKLoepugzdVJvdL56ogNV", password)
+
+	// the child mnemonic creates a new pool
+	child := NewPoolWithSecret(bip44.Ethereum, mnemonic, "")
+	_, err = child.GenerateAddressPool(0, 1)
+	assert.NoError(t, err)
+
+	_, err = pool.ChildHex(8, 0)
+	assert.Error(t, err)
+	_, err = NewPool(bip44.Bitcoin).ChildWIF(0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Pantani/errors"
+	pool_party "github.com/Pantani/pool-party"
+	"github.com/Pantani/pool-party/bip32"
+	"github.com/Pantani/pool-party/bip39"
+	"github.com/Pantani/pool-party/bip44"
+)
+
+// newFlagSet creates the flag set of the command, with the output format flag
+func newFlagSet(env *environment, name string) (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet("pool-party "+name, flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	format := flags.String("format", formatTable, "output format: table, json or csv")
+	return flags, format
+}
+
+// parseFlags parses the arguments and checks the output format
+// It returns errUsage if the arguments are invalid
+func parseFlags(flags *flag.FlagSet, args []string, format *string) error {
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if err := validateFormat(*format); err != nil {
+		fmt.Fprintln(flags.Output(), errorMessage(err))
+		flags.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+// runGenerate generates a new mnemonic
+func runGenerate(env *environment, args []string) error {
+	flags, format := newFlagSet(env, "generate")
+	bits := flags.Int("bits", 256, "entropy bits: 128, 160, 192, 224 or 256")
+	if err := parseFlags(flags, args, format); err != nil {
+		return err
+	}
+	entropy, err := bip39.NewEntropy(*bits)
+	if err != nil {
+		return errors.E(err, "error to generate the entropy", errors.Params{"bits": *bits})
+	}
+	defer bip39.Zero(entropy)
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return err
+	}
+	out := &table{
+		columns: []string{"mnemonic", "words"},
+		rows:    [][]string{{mnemonic, strconv.Itoa(len(strings.Fields(mnemonic)))}},
+		single:  true,
+	}
+	return out.write(env.stdout, *format)
+}
+
+// runDerive derives the addresses of the coin account from the mnemonic or extended private key
+func runDerive(env *environment, args []string) error {
+	flags, format := newFlagSet(env, "derive")
+	coinName := flags.String("coin", string(bip44.Bitcoin), "coin name: "+coinNames())
+	account := flags.Int("account", 0, "account index")
+	chain := flags.Int("chain", 0, "chain index, 0 for the receive and 1 for the change addresses")
+	start := flags.Int("start", 0, "first address index")
+	count := flags.Int("count", 10, "number of addresses")
+	path := flags.String("path", "", "path template overriding the BIP44 path, like m/84'/{coin}'/{account}'/0/{index}")
+	private := flags.Bool("private", false, "include the private keys")
+	secretSource := flags.String("secret", sourceStdin, "mnemonic or extended private key source: stdin, file:PATH or env:NAME")
+	passphraseSource := flags.String("passphrase", "", "optional mnemonic passphrase source: stdin, file:PATH or env:NAME")
+	if err := parseFlags(flags, args, format); err != nil {
+		return err
+	}
+	coin, err := parseCoin(*coinName)
+	if err != nil {
+		return err
+	}
+	if *chain != 0 && *chain != 1 {
+		return errors.E("invalid chain, use 0 or 1", errors.Params{"chain": *chain})
+	}
+	template := *path
+	if template == "" {
+		template = fmt.Sprintf("m/44'/{coin}'/{account}'/%d/{index}", *chain)
+	}
+
+	secret, passphrase, err := readSecrets(env, *secretSource, *passphraseSource)
+	if err != nil {
+		return err
+	}
+	pool, err := newPool(coin, secret, passphrase)
+	if err != nil {
+		return err
+	}
+	defer pool.Wipe()
+	addresses, err := pool.GenerateAddressPoolWithPath(template, *account, *start, *count)
+	if err != nil {
+		return err
+	}
+
+	out := &table{columns: []string{"index", "path", "address", "pubkey"}}
+	if *private {
+		out.columns = append(out.columns, "privkey")
+	}
+	for _, addr := range addresses {
+		row := []string{strconv.Itoa(addr.Index), "", addr.Address, addr.Pubkey}
+		if addr.Origin != nil {
+			row[1] = addr.Origin.Path.String()
+		}
+		if *private {
+			row = append(row, string(addr.Privkey))
+		}
+		out.rows = append(out.rows, row)
+	}
+	return out.write(env.stdout, *format)
+}
+
+// runInspect prints the fields of an extended key
+func runInspect(env *environment, args []string) error {
+	flags, format := newFlagSet(env, "inspect")
+	keySource := flags.String("key", sourceStdin, "extended key source if not passed as argument: stdin, file:PATH or env:NAME")
+	if err := parseFlags(flags, args, format); err != nil {
+		return err
+	}
+	// the flags can follow the key argument
+	var serialized string
+	fromArgs := flags.NArg() > 0
+	if fromArgs {
+		serialized = flags.Arg(0)
+		if err := parseFlags(flags, flags.Args()[1:], format); err != nil {
+			return err
+		}
+		if flags.NArg() > 0 {
+			fmt.Fprintf(env.stderr, "unexpected arguments: %s\n", strings.Join(flags.Args(), " "))
+			flags.Usage()
+			return errUsage
+		}
+	} else {
+		var err error
+		if serialized, err = readSecret(env, *keySource); err != nil {
+			return err
+		}
+	}
+
+	key, err := bip32.B58Deserialize(serialized)
+	if err != nil {
+		return errors.E(err, "invalid extended key")
+	}
+	defer key.Wipe()
+	if key.IsPrivate && fromArgs {
+		fmt.Fprintln(env.stderr, "warning: private keys in the arguments are visible to other processes, use -key")
+	}
+
+	kind, network := keyVersion(key)
+	public := key.PublicKey()
+	out := &table{
+		columns: []string{"type", "network", "private", "depth", "parent_fingerprint", "child_number", "fingerprint", "chain_code", "public_key", "xpub"},
+		rows: [][]string{{
+			kind,
+			network,
+			strconv.FormatBool(key.IsPrivate),
+			strconv.Itoa(int(key.Depth)),
+			hex.EncodeToString(key.FingerPrint),
+			childNumber(key.ChildNumber),
+			hex.EncodeToString(key.Fingerprint()),
+			hex.EncodeToString(key.ChainCode),
+			hex.EncodeToString(public.Key),
+			public.B58Serialize(),
+		}},
+		single: true,
+	}
+	return out.write(env.stdout, *format)
+}
+
+// errInvalidMnemonic is returned by validate for the invalid mnemonics, after the output
+var errInvalidMnemonic = errors.E("invalid mnemonic")
+
+// runValidate validates the BIP39 mnemonic, the command fails if it's invalid
+func runValidate(env *environment, args []string) error {
+	flags, format := newFlagSet(env, "validate")
+	secretSource := flags.String("secret", sourceStdin, "mnemonic source: stdin, file:PATH or env:NAME")
+	if err := parseFlags(flags, args, format); err != nil {
+		return err
+	}
+	mnemonic, err := readSecret(env, *secretSource)
+	if err != nil {
+		return err
+	}
+	_, err = bip39.EntropyFromMnemonic(mnemonic)
+	reason := ""
+	if err != nil {
+		reason = err.Error()
+	}
+	out := &table{
+		columns: []string{"valid", "words", "error"},
+		rows:    [][]string{{strconv.FormatBool(err == nil), strconv.Itoa(len(strings.Fields(mnemonic))), reason}},
+		single:  true,
+	}
+	if werr := out.write(env.stdout, *format); werr != nil {
+		return werr
+	}
+	if err != nil {
+		return errInvalidMnemonic
+	}
+	return nil
+}
+
+// newPool creates the pool of the extended private key or of the mnemonic and passphrase
+func newPool(coin bip44.Coin, secret, passphrase string) (*pool_party.Pool, error) {
+	if strings.HasPrefix(secret, "xprv") || strings.HasPrefix(secret, "tprv") {
+		if passphrase != "" {
+			return nil, errors.E("extended keys have no passphrase")
+		}
+		return pool_party.NewPoolWithExtendedKey(coin, secret)
+	}
+	if !bip39.IsMnemonicValid(secret) {
+		return nil, errors.E("invalid mnemonic")
+	}
+	return pool_party.NewPoolWithSecret(coin, secret, passphrase), nil
+}
+
+// parseCoin returns the coin of the name, ignoring the case
+func parseCoin(name string) (bip44.Coin, error) {
+	for _, coin := range bip44.Coins() {
+		if strings.EqualFold(string(coin), name) {
+			return coin, nil
+		}
+	}
+	return "", errors.E("unknown coin", errors.Params{"coin": name, "coins": coinNames()})
+}
+
+// coinNames returns the names of the supported coins
+func coinNames() string {
+	names := make([]string, 0, len(bip44.CoinList))
+	for _, coin := range bip44.Coins() {
+		names = append(names, string(coin))
+	}
+	return strings.Join(names, ", ")
+}
+
+// keyVersion returns the serialization type and network of the key version
+func keyVersion(key *bip32.Key) (string, string) {
+	switch hex.EncodeToString(key.Version) {
+	case hex.EncodeToString(bip32.PrivateWalletVersion):
+		return "xprv", "mainnet"
+	case hex.EncodeToString(bip32.PublicWalletVersion):
+		return "xpub", "mainnet"
+	case hex.EncodeToString(bip32.TestnetPrivateWalletVersion):
+		return "tprv", "testnet"
+	case hex.EncodeToString(bip32.TestnetPublicWalletVersion):
+		return "tpub", "testnet"
+	}
+	return hex.EncodeToString(key.Version), "unknown"
+}
+
+// childNumber formats the child number, with the ' suffix if it's hardened
+func childNumber(b []byte) string {
+	index := binary.BigEndian.Uint32(b)
+	if index >= bip32.FirstHardenedChild {
+		return strconv.FormatUint(uint64(index-bip32.FirstHardenedChild), 10) + "'"
+	}
+	return strconv.FormatUint(uint64(index), 10)
+}
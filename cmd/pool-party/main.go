@@ -0,0 +1,105 @@
+// Command pool-party generates and validates mnemonics, derives address ranges and inspects
+// extended keys. The secrets are read from stdin, a file or an environment variable, never
+// from the command arguments, and the output is a table, JSON or CSV.
+//
+// Usage:
+//
+//	pool-party generate [-bits 256] [-format table|json|csv]
+//	pool-party derive [-coin Bitcoin] [-account 0] [-chain 0] [-start 0] [-count 10] [-secret stdin] [-passphrase env:NAME]
+//	pool-party inspect [-key stdin] [xpub]
+//	pool-party validate [-secret file:PATH]
+//
+// The secret sources are stdin (or -), file:PATH and env:NAME.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Pantani/errors"
+)
+
+// exit codes of the command
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// command is a pool-party subcommand
+type command struct {
+	name  string
+	usage string
+	run   func(env *environment, args []string) error
+}
+
+var commands = []command{
+	{name: "generate", usage: "generate a new BIP39 mnemonic", run: runGenerate},
+	{name: "derive", usage: "derive the addresses of a coin account", run: runDerive},
+	{name: "inspect", usage: "inspect an extended public or private key", run: runInspect},
+	{name: "validate", usage: "validate a BIP39 mnemonic", run: runValidate},
+}
+
+// environment is the input and output of the command
+type environment struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+	getenv         func(string) string
+}
+
+// errUsage is returned for the invalid flags, the usage was already printed by the flag set
+var errUsage = errors.E("invalid usage")
+
+func main() {
+	env := &environment{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv}
+	os.Exit(run(env, os.Args[1:]))
+}
+
+// run runs the subcommand of the arguments
+// It returns the exit code
+func run(env *environment, args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(env.stderr)
+		return exitUsage
+	}
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		err := cmd.run(env, args[1:])
+		switch {
+		case err == nil:
+			return exitOK
+		case err == errUsage:
+			return exitUsage
+		default:
+			fmt.Fprintf(env.stderr, "pool-party %s: %s\n", cmd.name, errorMessage(err))
+			return exitError
+		}
+	}
+	fmt.Fprintf(env.stderr, "pool-party: unknown command %q\n\n", args[0])
+	usage(env.stderr)
+	return exitUsage
+}
+
+// usage prints the subcommands
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: pool-party <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Secrets are read from stdin (or -), file:PATH or env:NAME.")
+	fmt.Fprintln(w, "Run pool-party <command> -h for the command flags.")
+}
+
+// errorMessage returns the error message without the stack of the pool errors
+func errorMessage(err error) string {
+	if e, ok := err.(*errors.Error); ok && e.Err != nil {
+		return e.Err.Error()
+	}
+	return err.Error()
+}
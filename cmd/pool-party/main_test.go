@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Pantani/pool-party/bip39"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	abandonMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+	// the BIP32 test vector 1 master keys
+	testXprv = "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi"
+	testXpub = "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8"
+)
+
+// runTest runs the command with the stdin and environment variables
+// It returns the exit code, stdout and stderr
+func runTest(stdin string, vars map[string]string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	env := &environment{
+		stdin:  strings.NewReader(stdin),
+		stdout: &stdout,
+		stderr: &stderr,
+		getenv: func(name string) string { return vars[name] },
+	}
+	code := run(env, args)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_Usage(t *testing.T) {
+	code, _, stderr := runTest("", nil)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "Usage: pool-party")
+
+	code, _, stderr = runTest("", nil, "unknown")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, `unknown command "unknown"`)
+
+	code, _, _ = runTest("", nil, "generate", "-format", "xml")
+	assert.Equal(t, exitUsage, code)
+	code, _, _ = runTest("", nil, "derive", "-unknown")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestGenerate(t *testing.T) {
+	code, stdout, _ := runTest("", nil, "generate", "-bits", "128", "-format", "json")
+	assert.Equal(t, exitOK, code)
+	var result map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	assert.Equal(t, "12", result["words"])
+	assert.True(t, bip39.IsMnemonicValid(result["mnemonic"]))
+
+	code, _, stderr := runTest("", nil, "generate", "-bits", "100")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "entropy length")
+}
+
+func TestDerive(t *testing.T) {
+	code, stdout, _ := runTest(abandonMnemonic, nil, "derive", "-count", "2", "-format", "csv")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "index,path,address,pubkey\n"+
+		"0,m/44'/0'/0'/0/0,1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA,03aaeb52dd7494c361049de67cc680e83ebcbbbdbeb13637d92cd845f70308af5e\n"+
+		"1,m/44'/0'/0'/0/1,1Ak8PffB2meyfYnbXZR9EGfLfFZVpzJvQP,02dfcaec532010d704860e20ad6aff8cf3477164ffb02f93d45c552dadc70ed24f\n", stdout)
+
+	// the change chain with the private keys, the mnemonic from an environment variable
+	vars := map[string]string{"MNEMONIC": abandonMnemonic}
+	code, stdout, _ = runTest("", vars, "derive", "-secret", "env:MNEMONIC", "-chain", "1", "-count", "1", "-private", "-format", "json")
+	assert.Equal(t, exitOK, code)
+	var result []map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	if assert.Len(t, result, 1) {
+		assert.Equal(t, "m/44'/0'/0'/1/0", result[0]["path"])
+		assert.NotEmpty(t, result[0]["privkey"])
+	}
+
+	// the passphrase from a file and a custom path
+	dir, err := ioutil.TempDir("", "pool-party")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "passphrase")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("TREZOR\n"), 0600))
+	code, stdout, _ = runTest(abandonMnemonic, nil, "derive", "-coin", "ethereum", "-passphrase", "file:"+path, "-path", "m/44'/60'/0'/0/{index}", "-count", "1")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "m/44'/60'/0'/0/0")
+	assert.NotContains(t, stdout, "0x9858EfFD232B4033E47d90003D41EC34EcaEda94")
+
+	// the extended private key
+	code, stdout, _ = runTest(testXprv, nil, "derive", "-count", "1", "-path", "m/0'/{index}")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "m/0'/0")
+}
+
+func TestDerive_Invalid(t *testing.T) {
+	tests := []struct {
+		stdin string
+		args  []string
+		err   string
+	}{
+		{stdin: "abandon abandon", args: []string{"derive"}, err: "invalid mnemonic"},
+		{stdin: abandonMnemonic, args: []string{"derive", "-coin", "Foocoin"}, err: "unknown coin"},
+		{stdin: abandonMnemonic, args: []string{"derive", "-chain", "2"}, err: "invalid chain"},
+		{stdin: abandonMnemonic, args: []string{"derive", "-passphrase", "stdin"}, err: "can't both be read from stdin"},
+		{stdin: "", args: []string{"derive"}, err: "empty secret"},
+		{stdin: "", args: []string{"derive", "-secret", "env:EMPTY"}, err: "empty secret environment variable"},
+		{stdin: "", args: []string{"derive", "-secret", "file:/nonexistent"}, err: "error to read the secret file"},
+		{stdin: "", args: []string{"derive", "-secret", abandonMnemonic}, err: "invalid secret source"},
+		{stdin: testXprv, args: []string{"derive", "-passphrase", "env:PASSPHRASE"}, err: "extended keys have no passphrase"},
+	}
+	vars := map[string]string{"PASSPHRASE": "secret"}
+	for _, tt := range tests {
+		code, stdout, stderr := runTest(tt.stdin, vars, tt.args...)
+		assert.Equal(t, exitError, code, tt.err)
+		assert.Empty(t, stdout)
+		assert.Contains(t, stderr, tt.err)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	code, stdout, stderr := runTest("", nil, "inspect", testXpub, "-format", "json")
+	assert.Equal(t, exitOK, code)
+	assert.Empty(t, stderr)
+	var result map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	assert.Equal(t, map[string]string{
+		"type":               "xpub",
+		"network":            "mainnet",
+		"private":            "false",
+		"depth":              "0",
+		"parent_fingerprint": "00000000",
+		"child_number":       "0",
+		"fingerprint":        "3442193e",
+		"chain_code":         "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508",
+		"public_key":         "0339a36013301597daef41fbe593a02cc513d0b55527ec2df1050e2e8ff49c85c2",
+		"xpub":               testXpub,
+	}, result)
+
+	// the private key from stdin
+	code, stdout, stderr = runTest(testXprv, nil, "inspect")
+	assert.Equal(t, exitOK, code)
+	assert.Empty(t, stderr)
+	assert.Contains(t, stdout, "xprv")
+	assert.Contains(t, stdout, testXpub)
+
+	// the private key in the arguments
+	code, _, stderr = runTest("", nil, "inspect", testXprv)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stderr, "warning")
+
+	code, _, stderr = runTest("", nil, "inspect", "xpub123")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "invalid extended key")
+	code, _, _ = runTest("", nil, "inspect", testXpub, testXpub)
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestValidate(t *testing.T) {
+	code, stdout, _ := runTest(abandonMnemonic+"\n", nil, "validate", "-format", "json")
+	assert.Equal(t, exitOK, code)
+	assert.JSONEq(t, `{"valid": "true", "words": "12", "error": ""}`, stdout)
+
+	code, stdout, stderr := runTest(strings.Replace(abandonMnemonic, "about", "abandon", 1), nil, "validate", "-format", "csv")
+	assert.Equal(t, exitError, code)
+	assert.Equal(t, "valid,words,error\nfalse,12,checksum incorrect\n", stdout)
+	assert.Contains(t, stderr, "invalid mnemonic")
+}
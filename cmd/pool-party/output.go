@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Pantani/errors"
+)
+
+// output formats
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// table is the command output, the column names and the rows. A single record table is
+// written as a JSON object and as a column of fields, and as an array of objects otherwise.
+type table struct {
+	columns []string
+	rows    [][]string
+	single  bool
+}
+
+// validateFormat checks the output format
+func validateFormat(format string) error {
+	switch format {
+	case formatTable, formatJSON, formatCSV:
+		return nil
+	}
+	return errors.E("invalid output format, use table, json or csv", errors.Params{"format": format})
+}
+
+// write writes the table in the format
+// It returns an error if occurs
+func (t *table) write(w io.Writer, format string) error {
+	switch format {
+	case formatJSON:
+		records := make([]map[string]string, 0, len(t.rows))
+		for _, row := range t.rows {
+			record := make(map[string]string, len(t.columns))
+			for i, column := range t.columns {
+				record[column] = row[i]
+			}
+			records = append(records, record)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if t.single && len(records) == 1 {
+			return encoder.Encode(records[0])
+		}
+		return encoder.Encode(records)
+	case formatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(t.columns); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(t.rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	case formatTable:
+		writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		if t.single && len(t.rows) == 1 {
+			// a single record is written as a column of fields
+			for i, column := range t.columns {
+				fmt.Fprintf(writer, "%s\t%s\n", column, t.rows[0][i])
+			}
+			return writer.Flush()
+		}
+		fmt.Fprintln(writer, strings.ToUpper(strings.Join(t.columns, "\t")))
+		for _, row := range t.rows {
+			fmt.Fprintln(writer, strings.Join(row, "\t"))
+		}
+		return writer.Flush()
+	}
+	return validateFormat(format)
+}
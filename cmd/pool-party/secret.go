@@ -0,0 +1,74 @@
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/Pantani/errors"
+)
+
+const (
+	// sourceStdin, sourceFile and sourceEnv are the secret sources, stdin, file:PATH and env:NAME
+	sourceStdin = "stdin"
+	sourceFile  = "file:"
+	sourceEnv   = "env:"
+)
+
+// readSecret reads the secret of the source, without the surrounding whitespace
+// It returns the secret and an error if occurs
+func readSecret(env *environment, source string) (string, error) {
+	var secret string
+	switch {
+	case source == sourceStdin || source == "-":
+		data, err := ioutil.ReadAll(env.stdin)
+		if err != nil {
+			return "", errors.E(err, "error to read the secret from stdin")
+		}
+		secret = string(data)
+	case strings.HasPrefix(source, sourceFile):
+		path := strings.TrimPrefix(source, sourceFile)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", errors.E(err, "error to read the secret file", errors.Params{"path": path})
+		}
+		secret = string(data)
+	case strings.HasPrefix(source, sourceEnv):
+		name := strings.TrimPrefix(source, sourceEnv)
+		secret = env.getenv(name)
+		if secret == "" {
+			return "", errors.E("empty secret environment variable", errors.Params{"name": name})
+		}
+	default:
+		return "", errors.E("invalid secret source, use stdin, file:PATH or env:NAME")
+	}
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return "", errors.E("empty secret", errors.Params{"source": source})
+	}
+	return secret, nil
+}
+
+// readSecrets reads the secret and the optional passphrase, only one of them can be read from stdin
+// It returns the secret, the passphrase and an error if occurs
+func readSecrets(env *environment, secretSource, passphraseSource string) (string, string, error) {
+	if passphraseSource != "" && isStdin(secretSource) && isStdin(passphraseSource) {
+		return "", "", errors.E("the secret and the passphrase can't both be read from stdin")
+	}
+	secret, err := readSecret(env, secretSource)
+	if err != nil {
+		return "", "", err
+	}
+	if passphraseSource == "" {
+		return secret, "", nil
+	}
+	passphrase, err := readSecret(env, passphraseSource)
+	if err != nil {
+		return "", "", err
+	}
+	return secret, passphrase, nil
+}
+
+// isStdin returns true for the stdin source
+func isStdin(source string) bool {
+	return source == sourceStdin || source == "-"
+}
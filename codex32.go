@@ -0,0 +1,72 @@
+package pool_party
+
+import (
+	"github.com/Pantani/errors"
+	"github.com/Pantani/pool-party/bip39"
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/Pantani/pool-party/codex32"
+)
+
+// Codex32 encodes the pool master seed as codex32 strings (BIP93) for paper or steel backups,
+// the secret string if the threshold is 0, or count shares with threshold of them needed to
+// recover the seed. If the identifier is empty, the master key fingerprint is used.
+// It returns the codex32 strings and an error if occurs
+func (p *Pool) Codex32(threshold int, id string, count int) ([]string, error) {
+	if p.descriptor != nil || p.extendedKey != nil {
+		return nil, errors.E("pool has no master seed", errors.Params{"coin": p.coin})
+	}
+	seed, err := p.seed()
+	if err != nil {
+		return nil, err
+	}
+	defer bip39.Zero(seed)
+	if id == "" {
+		master, err := bip44.NewMasterKeyFromSeed(seed)
+		if err != nil {
+			return nil, err
+		}
+		id = codex32.FingerprintID(master.Fingerprint())
+		master.Wipe()
+	}
+
+	var shares []*codex32.Share
+	if threshold == 0 {
+		secret, err := codex32.New(0, id, codex32.SecretIndex, seed)
+		if err != nil {
+			return nil, errors.E(err, "error to encode the codex32 secret", errors.Params{"id": id})
+		}
+		shares = append(shares, secret)
+	} else {
+		shares, err = codex32.Split(p.entropySource(), seed, threshold, id, count)
+		if err != nil {
+			return nil, errors.E(err, "error to split the codex32 shares", errors.Params{"threshold": threshold, "count": count})
+		}
+	}
+	result := make([]string, 0, len(shares))
+	for _, share := range shares {
+		result = append(result, share.String())
+		share.Wipe()
+	}
+	return result, nil
+}
+
+// NewPoolWithCodex32 creates the pool with the master seed recovered from the codex32 secret
+// string or from threshold shares (BIP93)
+// It returns the pool and an error if occurs
+func NewPoolWithCodex32(coin bip44.Coin, codes ...string) (*Pool, error) {
+	shares := make([]*codex32.Share, 0, len(codes))
+	for _, s := range codes {
+		share, err := codex32.Parse(s)
+		if err != nil {
+			return nil, errors.E(err, "invalid codex32 string")
+		}
+		defer share.Wipe()
+		shares = append(shares, share)
+	}
+	seed, err := codex32.Combine(shares)
+	if err != nil {
+		return nil, errors.E(err, "error to recover the codex32 secret")
+	}
+	defer bip39.Zero(seed)
+	return NewPoolWithSeed(coin, seed)
+}
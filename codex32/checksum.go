@@ -0,0 +1,81 @@
+package codex32
+
+import "math/big"
+
+// checksum is the BCH code of the codex32 strings, the short code for the data up to
+// 80 characters and the long code for the longer data
+type checksum struct {
+	generator [5]*big.Int
+	constant  *big.Int
+	length    int // characters of the checksum
+}
+
+var (
+	shortChecksum = newChecksum(13, "10ce0795c2fd1e62a",
+		"19dc500ce73fde210", "1bfae00def77fe529", "1fbd920fffe7bee52", "1739640bdeee3fdad", "07729a039cfc75f5a")
+	longChecksum = newChecksum(15, "43381e570bf4798ab26",
+		"3d59d273535ea62d897", "7a9becb6361c6c51507", "543f9b7e6c38d8a2a0e", "0c577eaeccf1990d13c", "1887f74f8dc71b10651")
+
+	// initialResidue is the residue of both codes before the data
+	initialResidue = big.NewInt(0x23181b3)
+)
+
+// newChecksum creates the checksum of the hex constant and generators
+func newChecksum(length int, constant string, generator ...string) *checksum {
+	c := &checksum{constant: hexInt(constant), length: length}
+	for i, g := range generator {
+		c.generator[i] = hexInt(g)
+	}
+	return c
+}
+
+// hexInt parses the hex number
+func hexInt(s string) *big.Int {
+	n, _ := new(big.Int).SetString(s, 16)
+	return n
+}
+
+// checksumFor returns the checksum of the data without checksum
+func checksumFor(dataLength int) *checksum {
+	if dataLength > maxShortData {
+		return longChecksum
+	}
+	return shortChecksum
+}
+
+// polymod computes the residue of the values
+func (c *checksum) polymod(values []byte) *big.Int {
+	shift := uint(5 * (c.length - 1))
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), shift), big.NewInt(1))
+	residue := new(big.Int).Set(initialResidue)
+	top := new(big.Int)
+	for _, v := range values {
+		top.Rsh(residue, shift)
+		residue.And(residue, mask)
+		residue.Lsh(residue, 5)
+		residue.Xor(residue, big.NewInt(int64(v)))
+		for i, g := range c.generator {
+			if top.Bit(i) == 1 {
+				residue.Xor(residue, g)
+			}
+		}
+	}
+	return residue
+}
+
+// verify returns true if the values end with a valid checksum
+func (c *checksum) verify(values []byte) bool {
+	return c.polymod(values).Cmp(c.constant) == 0
+}
+
+// create computes the checksum characters of the values
+func (c *checksum) create(values []byte) []byte {
+	padded := append(append([]byte{}, values...), make([]byte, c.length)...)
+	residue := c.polymod(padded)
+	residue.Xor(residue, c.constant)
+	result := make([]byte, c.length)
+	for i := range result {
+		result[i] = byte(new(big.Int).Rsh(residue, uint(5*(c.length-1-i))).Uint64() & 31)
+	}
+	return result
+}
@@ -0,0 +1,392 @@
+// Package codex32 implements the codex32 encoding of the BIP32 master seeds (BIP93), a bech32
+// string with a BCH checksum for paper and steel backups, which can be split in threshold shares
+// by Shamir's secret sharing over GF(32) and recovered by hand.
+//
+// The BIP93 spec can be found at
+// https://github.com/bitcoin/bips/blob/master/bip-0093.mediawiki
+package codex32
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// hrp is the human-readable part of the codex32 strings
+	hrp = "ms"
+
+	// SecretIndex is the share index of the secret
+	SecretIndex = 's'
+
+	// charset is the bech32 character set
+	charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+	// shareIndexes are the share indexes of the generated shares, in order
+	shareIndexes = "acdefghjklmnpqrtuvwxyz023456789"
+
+	// headerSize is the size of the threshold, identifier and share index
+	headerSize = 6
+
+	// maxShortData is the longest data without checksum of the short checksum
+	maxShortData = 80
+
+	// MinSecretSize and MaxSecretSize are the sizes of the master seeds
+	MinSecretSize = 16
+	MaxSecretSize = 64
+
+	// MaxThreshold is the highest threshold of the shares
+	MaxThreshold = 9
+)
+
+var (
+	// ErrInvalidString is returned when the string isn't a codex32 string
+	ErrInvalidString = errors.New("invalid codex32 string")
+
+	// ErrInvalidChecksum is returned when the string checksum doesn't match
+	ErrInvalidChecksum = errors.New("invalid codex32 checksum")
+
+	// ErrInvalidThreshold is returned when the threshold isn't 0 or 2 to 9
+	ErrInvalidThreshold = errors.New("invalid codex32 threshold")
+
+	// ErrInvalidShareIndex is returned for the unshared secrets with a share index other than s
+	ErrInvalidShareIndex = errors.New("invalid codex32 share index")
+
+	// ErrInvalidIdentifier is returned when the identifier isn't 4 bech32 characters
+	ErrInvalidIdentifier = errors.New("invalid codex32 identifier")
+
+	// ErrInvalidSecretSize is returned when the secret doesn't have 16 to 64 bytes
+	ErrInvalidSecretSize = errors.New("invalid codex32 secret size")
+
+	// ErrMismatchedShares is returned when the shares have different thresholds,
+	// identifiers or lengths, or duplicated indexes
+	ErrMismatchedShares = errors.New("mismatched codex32 shares")
+
+	// ErrNotEnoughShares is returned when there are less shares than the threshold
+	ErrNotEnoughShares = errors.New("not enough codex32 shares")
+)
+
+// Share is the codex32 string of the secret or of a share
+type Share struct {
+	Threshold int    // 0 for an unshared secret, or 2 to 9
+	ID        string // identifier of the secret, 4 bech32 characters
+	Index     byte   // share index, SecretIndex for the secret
+	values    []byte // 5 bits values of the data part, with the checksum
+}
+
+// New encodes the payload, the secret or a share value, as a codex32 string
+// It returns the share and an error if occurs
+func New(threshold int, id string, index byte, payload []byte) (*Share, error) {
+	if err := validateHeader(threshold, id, index); err != nil {
+		return nil, err
+	}
+	if len(payload) < MinSecretSize || len(payload) > MaxSecretSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidSecretSize, len(payload))
+	}
+	header := fmt.Sprintf("%d%s%c", threshold, strings.ToLower(id), index)
+	values := make([]byte, 0, headerSize+(len(payload)*8+4)/5+longChecksum.length)
+	for i := 0; i < headerSize; i++ {
+		values = append(values, byte(strings.IndexByte(charset, header[i])))
+	}
+	values = append(values, toValues(payload)...)
+	values = append(values, checksumFor(len(values)).create(values)...)
+	return &Share{Threshold: threshold, ID: strings.ToLower(id), Index: index, values: values}, nil
+}
+
+// Parse decodes the codex32 string, in lower or upper case
+// It returns the share and an error if the string or its checksum is invalid
+func Parse(s string) (*Share, error) {
+	lower := strings.ToLower(s)
+	if lower != s && strings.ToUpper(s) != s {
+		return nil, fmt.Errorf("%w: mixed case", ErrInvalidString)
+	}
+	if !strings.HasPrefix(lower, hrp+"1") {
+		return nil, fmt.Errorf("%w: missing %s1 prefix", ErrInvalidString, hrp)
+	}
+	data := lower[len(hrp)+1:]
+	values := make([]byte, len(data))
+	for i := range data {
+		v := strings.IndexByte(charset, data[i])
+		if v < 0 {
+			return nil, fmt.Errorf("%w: invalid character %q", ErrInvalidString, data[i])
+		}
+		values[i] = byte(v)
+	}
+
+	var c *checksum
+	switch {
+	case len(values) >= maxShortData+1+longChecksum.length:
+		c = longChecksum
+	case len(values) <= maxShortData+shortChecksum.length:
+		c = shortChecksum
+	default:
+		return nil, fmt.Errorf("%w: invalid length %d", ErrInvalidString, len(s))
+	}
+	if len(values) < headerSize+c.length {
+		return nil, fmt.Errorf("%w: invalid length %d", ErrInvalidString, len(s))
+	}
+	if !c.verify(values) {
+		return nil, ErrInvalidChecksum
+	}
+
+	threshold := int(data[0] - '0')
+	if data[0] < '0' || data[0] > '9' {
+		threshold = -1
+	}
+	share := &Share{Threshold: threshold, ID: data[1:5], Index: data[5], values: values}
+	if err := validateHeader(share.Threshold, share.ID, share.Index); err != nil {
+		return nil, err
+	}
+	payloadBits := (len(values) - headerSize - c.length) * 5
+	if payloadBits%8 > 4 || payloadBits/8 < MinSecretSize || payloadBits/8 > MaxSecretSize {
+		return nil, fmt.Errorf("%w: %d payload bits", ErrInvalidSecretSize, payloadBits)
+	}
+	return share, nil
+}
+
+// String returns the lower case codex32 string
+func (s *Share) String() string {
+	var b strings.Builder
+	b.WriteString(hrp + "1")
+	for _, v := range s.values {
+		b.WriteByte(charset[v])
+	}
+	return b.String()
+}
+
+// Payload returns the secret or share value, without the padding bits
+func (s *Share) Payload() []byte {
+	payload := s.values[headerSize : len(s.values)-s.checksum().length]
+	return fromValues(payload)
+}
+
+// checksum returns the checksum of the share length
+func (s *Share) checksum() *checksum {
+	if len(s.values) > maxShortData+shortChecksum.length {
+		return longChecksum
+	}
+	return shortChecksum
+}
+
+// Split splits the secret in count shares, with threshold shares needed to recover it.
+// The first threshold-1 shares are read from the random reader, like crypto/rand.Reader,
+// and the other shares are interpolated from them and the secret.
+// It returns the shares and an error if occurs
+func Split(random io.Reader, secret []byte, threshold int, id string, count int) ([]*Share, error) {
+	if threshold < 2 || threshold > MaxThreshold {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidThreshold, threshold)
+	}
+	if count < threshold || count > len(shareIndexes) {
+		return nil, fmt.Errorf("%w: %d shares of threshold %d", ErrNotEnoughShares, count, threshold)
+	}
+	secretShare, err := New(threshold, id, SecretIndex, secret)
+	if err != nil {
+		return nil, err
+	}
+	defer secretShare.Wipe()
+
+	base := []*Share{secretShare}
+	payload := make([]byte, len(secret))
+	defer wipe(payload)
+	for i := 0; i < threshold-1; i++ {
+		if _, err := io.ReadFull(random, payload); err != nil {
+			return nil, err
+		}
+		share, err := New(threshold, id, shareIndexes[i], payload)
+		if err != nil {
+			return nil, err
+		}
+		base = append(base, share)
+	}
+
+	shares := append([]*Share{}, base[1:]...)
+	for i := threshold - 1; i < count; i++ {
+		share, err := Interpolate(base, shareIndexes[i])
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+	return shares, nil
+}
+
+// Combine recovers the secret from threshold shares, or from the secret string
+// It returns the secret and an error if occurs
+func Combine(shares []*Share) ([]byte, error) {
+	secret, err := Interpolate(shares, SecretIndex)
+	if err != nil {
+		return nil, err
+	}
+	defer secret.Wipe()
+	return secret.Payload(), nil
+}
+
+// Interpolate derives the share of the index from threshold shares by the Lagrange
+// interpolation of each character, the checksum of the derived share is valid
+// It returns the share and an error if occurs
+func Interpolate(shares []*Share, index byte) (*Share, error) {
+	if len(shares) == 0 {
+		return nil, ErrNotEnoughShares
+	}
+	target := strings.IndexByte(charset, index)
+	if target < 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidShareIndex, index)
+	}
+	first := shares[0]
+	seen := make(map[byte]bool, len(shares))
+	for _, share := range shares {
+		if share.Threshold != first.Threshold || share.ID != first.ID || len(share.values) != len(first.values) || seen[share.Index] {
+			return nil, ErrMismatchedShares
+		}
+		seen[share.Index] = true
+		if share.Index == index {
+			return share.copy(), nil
+		}
+	}
+	if first.Threshold == 0 {
+		return nil, fmt.Errorf("%w: the secret isn't shared", ErrInvalidShareIndex)
+	}
+	if len(shares) < first.Threshold {
+		return nil, fmt.Errorf("%w: %d of %d", ErrNotEnoughShares, len(shares), first.Threshold)
+	}
+	shares = shares[:first.Threshold]
+
+	result := &Share{Threshold: first.Threshold, ID: first.ID, Index: index, values: make([]byte, len(first.values))}
+	for i, weight := range lagrange(shares, byte(target)) {
+		for k, v := range shares[i].values {
+			result.values[k] ^= gfMul(weight, v)
+		}
+	}
+	return result, nil
+}
+
+// Wipe zeroes the share
+func (s *Share) Wipe() {
+	wipe(s.values)
+}
+
+// copy returns a copy of the share
+func (s *Share) copy() *Share {
+	c := *s
+	c.values = append([]byte{}, s.values...)
+	return &c
+}
+
+// lagrange computes the Lagrange weights of the shares at the target index,
+// the product of (target - x_j) / (x_i - x_j) for every other share j
+func lagrange(shares []*Share, target byte) []byte {
+	indexes := make([]byte, len(shares))
+	for i, share := range shares {
+		indexes[i] = byte(strings.IndexByte(charset, share.Index))
+	}
+	weights := make([]byte, len(shares))
+	for i, xi := range indexes {
+		num, den := byte(1), byte(1)
+		for j, xj := range indexes {
+			if i != j {
+				num = gfMul(num, target^xj)
+				den = gfMul(den, xi^xj)
+			}
+		}
+		weights[i] = gfMul(num, gfInv(den))
+	}
+	return weights
+}
+
+// gfMul multiplies in GF(32), modulo x^5 + x^3 + 1
+func gfMul(a, b byte) byte {
+	var r byte
+	for i := 0; i < 5; i++ {
+		if b>>uint(i)&1 == 1 {
+			r ^= a
+		}
+		a <<= 1
+		if a&32 != 0 {
+			a ^= 41
+		}
+	}
+	return r
+}
+
+// gfInv returns the multiplicative inverse in GF(32), a^30
+func gfInv(a byte) byte {
+	r := byte(1)
+	for i := 0; i < 30; i++ {
+		r = gfMul(r, a)
+	}
+	return r
+}
+
+// validateHeader checks the threshold, identifier and share index
+func validateHeader(threshold int, id string, index byte) error {
+	if threshold != 0 && (threshold < 2 || threshold > MaxThreshold) {
+		return fmt.Errorf("%w: %d", ErrInvalidThreshold, threshold)
+	}
+	if len(id) != 4 {
+		return fmt.Errorf("%w: %q", ErrInvalidIdentifier, id)
+	}
+	for i := range id {
+		if strings.IndexByte(charset, strings.ToLower(id)[i]) < 0 {
+			return fmt.Errorf("%w: %q", ErrInvalidIdentifier, id)
+		}
+	}
+	if strings.IndexByte(charset, index) < 0 || (threshold == 0 && index != SecretIndex) {
+		return fmt.Errorf("%w: %q", ErrInvalidShareIndex, index)
+	}
+	return nil
+}
+
+// toValues converts the bytes to 5 bits values, padding the last value with zeros
+func toValues(data []byte) []byte {
+	values := make([]byte, 0, (len(data)*8+4)/5)
+	acc, bits := 0, uint(0)
+	for _, b := range data {
+		acc = acc<<8 | int(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			values = append(values, byte(acc>>bits&31))
+		}
+		acc &= 1<<bits - 1
+	}
+	if bits > 0 {
+		values = append(values, byte(acc<<(5-bits)&31))
+	}
+	return values
+}
+
+// fromValues converts the 5 bits values to bytes, discarding the padding bits
+func fromValues(values []byte) []byte {
+	data := make([]byte, 0, len(values)*5/8)
+	acc, bits := 0, uint(0)
+	for _, v := range values {
+		acc = acc<<5 | int(v)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			data = append(data, byte(acc>>bits))
+		}
+		acc &= 1<<bits - 1
+	}
+	return data
+}
+
+// wipe zeroes the buffer
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// FingerprintID returns the identifier of the first 20 bits of the BIP32 master key fingerprint
+func FingerprintID(fingerprint []byte) string {
+	var b strings.Builder
+	for _, v := range toValues(fingerprint) {
+		if b.Len() == 4 {
+			break
+		}
+		b.WriteByte(charset[v])
+	}
+	return b.String()
+}
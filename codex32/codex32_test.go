@@ -0,0 +1,164 @@
+package codex32
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parse(t *testing.T, s string) *Share {
+	share, err := Parse(s)
+	assert.NoError(t, err)
+	return share
+}
+
+func TestParse(t *testing.T) {
+	// the BIP93 test vectors
+	tests := []struct {
+		s      string
+		secret string
+	}{
+		{s: "ms10testsxxxxxxxxxxxxxxxxxxxxxxxxxx4nzvca9cmczlw", secret: "318c6318c6318c6318c6318c6318c631"},
+		{s: "MS12NAMES6XQGUZTTXKEQNJSJZV4JV3NZ5K3KWGSPHUH6EVW", secret: "d1808e096b35b209ca12132b264662a5"},
+		{s: "ms13cashsllhdmn9m42vcsamx24zrxgs3qqjzqud4m0d6nln", secret: "ffeeddccbbaa99887766554433221100"},
+		{s: "ms10leetsllhdmn9m42vcsamx24zrxgs3qrl7ahwvhw4fnzrhve25gvezzyqqtum9pgv99ycma", secret: "ffeeddccbbaa99887766554433221100ffeeddccbbaa99887766554433221100"},
+		{
+			s:      "MS100C8VSM32ZXFGUHPCHTLUPZRY9X8GF2TVDW0S3JN54KHCE6MUA7LQPZYGSFJD6AN074RXVCEMLH8WU3TK925ACDEFGHJKLMNPQRSTUVWXY06FHPV80UNDVARHRAK",
+			secret: "dc5423251cb87175ff8110c8531d0952d8d73e1194e95b5f19d6f9df7c01111104c9baecdfea8cccc677fb9ddc8aec5553b86e528bcadfdcc201c17c638c47e9",
+		},
+	}
+	for _, tt := range tests {
+		share := parse(t, tt.s)
+		assert.Equal(t, byte(SecretIndex), share.Index)
+		assert.Equal(t, tt.secret, hex.EncodeToString(share.Payload()))
+		assert.Equal(t, strings.ToLower(tt.s), share.String())
+	}
+
+	share := parse(t, "ms10testsxxxxxxxxxxxxxxxxxxxxxxxxxx4nzvca9cmczlw")
+	assert.Equal(t, 0, share.Threshold)
+	assert.Equal(t, "test", share.ID)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		s   string
+		err error
+	}{
+		{s: "ms10testsxxxxxxxxxxxxxxxxxxxxxxxxxx4nzvca9cmczlq", err: ErrInvalidChecksum},
+		{s: "ms10testsxxxxxxxxxxxxxxxxxxxxxxxxxx4nzvcA9cmczlw", err: ErrInvalidString},
+		{s: "ms10testsxxxxxxxxxxxxxxxxxxxxxxxxxx4nzvcb9cmczlw", err: ErrInvalidString},
+		{s: "xs10testsxxxxxxxxxxxxxxxxxxxxxxxxxx4nzvca9cmczlw", err: ErrInvalidString},
+		{s: "ms1", err: ErrInvalidString},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.s)
+		assert.ErrorIs(t, err, tt.err, tt.s)
+	}
+
+	// valid checksums with an invalid header or payload
+	for _, header := range []string{"0testa", "xtests"} {
+		values := []byte{}
+		for i := range header {
+			values = append(values, byte(strings.IndexByte(charset, header[i])))
+		}
+		values = append(values, toValues(bytes.Repeat([]byte{1}, 16))...)
+		s := (&Share{values: append(values, shortChecksum.create(values)...)}).String()
+		_, err := Parse(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestNew(t *testing.T) {
+	secret, _ := hex.DecodeString("ffeeddccbbaa99887766554433221100")
+	share, err := New(3, "cash", SecretIndex, secret)
+	assert.NoError(t, err)
+	assert.Equal(t, "ms13cashsllhdmn9m42vcsamx24zrxgs3qqjzqud4m0d6nln", share.String())
+
+	_, err = New(1, "cash", SecretIndex, secret)
+	assert.ErrorIs(t, err, ErrInvalidThreshold)
+	_, err = New(0, "cash", 'a', secret)
+	assert.ErrorIs(t, err, ErrInvalidShareIndex)
+	_, err = New(0, "cas", SecretIndex, secret)
+	assert.ErrorIs(t, err, ErrInvalidIdentifier)
+	_, err = New(0, "cas1", SecretIndex, secret)
+	assert.ErrorIs(t, err, ErrInvalidIdentifier)
+	_, err = New(0, "cash", SecretIndex, secret[:15])
+	assert.ErrorIs(t, err, ErrInvalidSecretSize)
+}
+
+func TestInterpolate(t *testing.T) {
+	// the BIP93 test vectors, 2 of n
+	a := parse(t, "MS12NAMEA320ZYXWVUTSRQPNMLKJHGFEDCAXRPP870HKKQRM")
+	c := parse(t, "MS12NAMECACDEFGHJKLMNPQRSTUVWXYZ023FTR2GDZMPY6PN")
+	d, err := Interpolate([]*Share{a, c}, 'd')
+	assert.NoError(t, err)
+	assert.Equal(t, "ms12namedll4f8jlh4e5vdvuldlfxu2jhdnlsm97xvenrxeg", d.String())
+	secret, err := Combine([]*Share{c, a})
+	assert.NoError(t, err)
+	assert.Equal(t, "d1808e096b35b209ca12132b264662a5", hex.EncodeToString(secret))
+
+	// 3 of n
+	shares := []*Share{
+		parse(t, "ms13cashsllhdmn9m42vcsamx24zrxgs3qqjzqud4m0d6nln"),
+		parse(t, "ms13casha320zyxwvutsrqpnmlkjhgfedca2a8d0zehn8a0t"),
+		parse(t, "ms13cashcacdefghjklmnpqrstuvwxyz023949xq35my48dr"),
+	}
+	for _, want := range []string{
+		"ms13cashd0wsedstcdcts64cd7wvy4m90lm28w4ffupqs7rm",
+		"ms13casheekgpemxzshcrmqhaydlp6yhms3ws7320xyxsar9",
+		"ms13cashf8jh6sdrkpyrsp5ut94pj8ktehhw2hfvyrj48704",
+	} {
+		share, err := Interpolate(shares, want[8])
+		assert.NoError(t, err)
+		assert.Equal(t, want, share.String())
+	}
+
+	_, err = Combine(shares[1:2])
+	assert.ErrorIs(t, err, ErrNotEnoughShares)
+	_, err = Combine([]*Share{shares[1], shares[1], shares[2]})
+	assert.ErrorIs(t, err, ErrMismatchedShares)
+	_, err = Combine([]*Share{a, shares[1]})
+	assert.ErrorIs(t, err, ErrMismatchedShares)
+	_, err = Interpolate([]*Share{parse(t, "ms10testsxxxxxxxxxxxxxxxxxxxxxxxxxx4nzvca9cmczlw")}, 'a')
+	assert.ErrorIs(t, err, ErrInvalidShareIndex)
+}
+
+func TestSplit(t *testing.T) {
+	secret := bytes.Repeat([]byte{0xab}, 32)
+	shares, err := Split(rand.Reader, secret, 3, "test", 5)
+	assert.NoError(t, err)
+	assert.Len(t, shares, 5)
+	for i, share := range shares {
+		assert.Equal(t, shareIndexes[i], share.Index)
+		parsed := parse(t, strings.ToUpper(share.String()))
+		assert.Equal(t, share, parsed)
+	}
+
+	// any 3 shares recover the secret
+	for _, subset := range [][]int{{0, 1, 2}, {2, 3, 4}, {4, 0, 3}} {
+		selected := []*Share{shares[subset[0]], shares[subset[1]], shares[subset[2]]}
+		got, err := Combine(selected)
+		assert.NoError(t, err)
+		assert.Equal(t, secret, got)
+	}
+	got, err := Combine(shares[:2])
+	assert.Error(t, err)
+	assert.Nil(t, got)
+
+	_, err = Split(rand.Reader, secret, 1, "test", 5)
+	assert.ErrorIs(t, err, ErrInvalidThreshold)
+	_, err = Split(rand.Reader, secret, 3, "test", 2)
+	assert.ErrorIs(t, err, ErrNotEnoughShares)
+	_, err = Split(bytes.NewReader(nil), secret, 3, "test", 5)
+	assert.Error(t, err)
+}
+
+func TestFingerprintID(t *testing.T) {
+	assert.Equal(t, "qqqq", FingerprintID([]byte{0, 0, 0, 0}))
+	// 0xd34db33f, 11010 01101 00110 11011
+	assert.Equal(t, "6dxm", FingerprintID([]byte{0xd3, 0x4d, 0xb3, 0x3f}))
+}
@@ -0,0 +1,48 @@
+package pool_party
+
+import (
+	"testing"
+
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool_Codex32(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Bitcoin, testMnemonic, testPassphrase)
+	want, err := pool.GenerateAddressPool(0, 2)
+	assert.NoError(t, err)
+
+	secret, err := pool.Codex32(0, "", 0)
+	assert.NoError(t, err)
+	assert.Len(t, secret, 1)
+	recovered, err := NewPoolWithCodex32(bip44.Bitcoin, secret...)
+	assert.NoError(t, err)
+	got, err := recovered.GenerateAddressPool(0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	shares, err := pool.Codex32(2, "test", 3)
+	assert.NoError(t, err)
+	assert.Len(t, shares, 3)
+	assert.Equal(t, "ms12test", shares[0][:8])
+	recovered, err = NewPoolWithCodex32(bip44.Bitcoin, shares[2], shares[0])
+	assert.NoError(t, err)
+	got, err = recovered.GenerateAddressPool(0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	_, err = NewPoolWithCodex32(bip44.Bitcoin, shares[1])
+	assert.Error(t, err)
+	_, err = NewPoolWithCodex32(bip44.Bitcoin, "ms1invalid")
+	assert.Error(t, err)
+	_, err = pool.Codex32(1, "test", 3)
+	assert.Error(t, err)
+
+	// the BIP93 secret
+	seedPool, err := NewPoolWithCodex32(bip44.Bitcoin, "ms10testsxxxxxxxxxxxxxxxxxxxxxxxxxx4nzvca9cmczlw")
+	assert.NoError(t, err)
+	secret, err = seedPool.Codex32(0, "test", 0)
+	assert.NoError(t, err)
+	// the same payload, the padding bits are zeros
+	assert.Equal(t, "ms10testsxxxxxxxxxxxxxxxxxxxxxxxxx", secret[0][:34])
+}
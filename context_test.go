@@ -0,0 +1,102 @@
+package pool_party
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/Pantani/pool-party/descriptor"
+	"github.com/stretchr/testify/assert"
+)
+
+// countdownContext is cancelled after its Err method is called n times
+type countdownContext struct {
+	context.Context
+	n int
+}
+
+func (c *countdownContext) Err() error {
+	if c.n <= 0 {
+		return context.Canceled
+	}
+	c.n--
+	return nil
+}
+
+func TestPool_GenerateAddressPoolContext(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Bitcoin, testMnemonic, testPassphrase)
+	want, err := pool.GenerateAddressPool(10, 3)
+	assert.NoError(t, err)
+
+	got, err := pool.GenerateAddressPoolContext(context.Background(), 10, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// partial progress is returned with the context error
+	got, err = pool.GenerateAddressPoolContext(&countdownContext{Context: context.Background(), n: 2}, 10, 1000000)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, want[:2], got)
+
+	got, err = pool.GenerateAddressPoolWithPathContext(&countdownContext{Context: context.Background(), n: 1}, string(bip44.DefaultPathTemplate), 0, 10, 1000000)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, want[:1], got)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	got, err = pool.GenerateAddressPoolWithSchemeContext(ctx, bip44.BIP44, 10, 3)
+	assert.Equal(t, context.Canceled, err)
+	assert.Empty(t, got)
+}
+
+func TestPool_GenerateAddressPoolContextWatchOnly(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Bitcoin, abandonMnemonic, "")
+	desc, err := pool.Descriptor(descriptor.WPKH, 0, false)
+	assert.NoError(t, err)
+	watchOnly, err := NewWatchOnlyPool(bip44.Bitcoin, desc)
+	assert.NoError(t, err)
+	want, err := watchOnly.GenerateAddressPool(0, 3)
+	assert.NoError(t, err)
+
+	got, err := watchOnly.GenerateAddressPoolContext(&countdownContext{Context: context.Background(), n: 3}, 0, 1000000)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, want, got)
+}
+
+func TestMultisigPool_GenerateAddressPoolContext(t *testing.T) {
+	xpubs := make([]string, 0, 2)
+	for _, mnemonic := range []string{testMnemonic, abandonMnemonic} {
+		account, err := bip44.GenerateWallets(bip44.Bitcoin, mnemonic, "", 0, 0)
+		assert.NoError(t, err)
+		xpubs = append(xpubs, account.Key.PublicKey().String())
+	}
+	pool, err := NewMultisigPool(bip44.Bitcoin, P2WSH, 2, xpubs)
+	assert.NoError(t, err)
+	want, err := pool.GenerateAddressPool(5, 3)
+	assert.NoError(t, err)
+
+	got, err := pool.GenerateAddressPoolContext(context.Background(), 5, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// partial progress is returned with the context error
+	got, err = pool.GenerateAddressPoolContext(&countdownContext{Context: context.Background(), n: 2}, 5, 1000)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, want[:2], got)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	got, err = pool.GenerateAddressPoolContext(ctx, 5, 3)
+	assert.Equal(t, context.Canceled, err)
+	assert.Empty(t, got)
+}
+
+func TestPool_GenerateMnemonicContext(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Bitcoin, testMnemonic, testPassphrase)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, pool.GenerateMnemonicContext(ctx, 128, ""))
+	assert.Equal(t, testMnemonic, string(pool.mnemonic))
+
+	assert.NoError(t, pool.GenerateMnemonicContext(context.Background(), 128, ""))
+	assert.NotEqual(t, testMnemonic, string(pool.mnemonic))
+}
@@ -0,0 +1,118 @@
+package pool_party
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/Pantani/errors"
+	"github.com/Pantani/pool-party/bip32"
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/Pantani/pool-party/descriptor"
+)
+
+// NewWatchOnlyPool creates a pool without secrets from an output descriptor with an
+// extended public key, like wpkh([d34db33f/84'/0'/0']xpub.../0/*)#checksum
+// It returns the pool and an error if occurs
+func NewWatchOnlyPool(coin bip44.Coin, desc string) (*Pool, error) {
+	altcoin, ok := bip44.CoinList[coin]
+	if !ok || altcoin.IsEthereum() {
+		return nil, errors.E("descriptors are only supported for UTXO coins", errors.Params{"coin": coin})
+	}
+	d, err := descriptor.Parse(desc)
+	if err != nil {
+		return nil, errors.E(err, "invalid descriptor")
+	}
+	if d.Type != descriptor.PKH && !altcoin.SupportsSegwit() {
+		return nil, errors.E("segwit descriptors are not supported for the coin", errors.Params{"coin": coin, "type": d.Type})
+	}
+	return &Pool{coin: coin, descriptor: d}, nil
+}
+
+// IsWatchOnly returns true if the pool was created from a descriptor, without secrets
+func (p *Pool) IsWatchOnly() bool {
+	return p.descriptor != nil
+}
+
+// Descriptor exports the receive (or change if internal) addresses of the account as an
+// output descriptor with the account extended public key and the key origin, like
+// wpkh([d34db33f/84'/0'/0']xpub.../0/*)#checksum, to import in Bitcoin Core (importdescriptors).
+// The account is derived by the BIP44, BIP84 or BIP86 path for the pkh, wpkh and tr types.
+// Pools with a path template (eg Electrum seeds) export the template addresses, with the
+// pkh type or wpkh for segwit pools, and the change path replacing the last receive component by 1.
+// The nested segwit pools (eg the LND BIP49 pools) can't be exported.
+// Watch-only pools return the imported descriptor, and pools created from an account extended
+// key export it without origin when it matches the account path.
+// It returns the descriptor and an error if occurs
+func (p *Pool) Descriptor(t descriptor.Type, account int, internal bool) (string, error) {
+	if p.descriptor != nil {
+		return p.descriptor.String(), nil
+	}
+	coin, ok := bip44.CoinList[p.coin]
+	if !ok || coin.IsEthereum() {
+		return "", errors.E("descriptors are only supported for UTXO coins", errors.Params{"coin": p.coin})
+	}
+	if t != descriptor.PKH && !coin.SupportsSegwit() {
+		return "", errors.E("segwit descriptors are not supported for the coin", errors.Params{"coin": p.coin, "type": t})
+	}
+	if account < 0 || int64(account) >= int64(bip32.FirstHardenedChild) {
+		return "", errors.E("account out of range", errors.Params{"account": account})
+	}
+	if p.nested {
+		return "", errors.E("nested segwit descriptors are not supported", errors.Params{"coin": p.coin, "type": t})
+	}
+
+	// m/purpose'/cointype'/account'/0/{index}
+	template := bip44.PathTemplate(fmt.Sprintf("m/%d'/{coin}'/{account}'/0/{index}", t.Purpose()))
+	if p.template != "" {
+		if (t == descriptor.WPKH) != p.segwit || t == descriptor.TR {
+			return "", errors.E("descriptor type doesn't match the pool addresses", errors.Params{"type": t, "segwit": p.segwit})
+		}
+		template = p.template
+	}
+	parent, err := template.Parent(coin.CoinType, account)
+	if err != nil {
+		return "", err
+	}
+	// the account path is the hardened part of the parent path, the extended public key
+	// derives the rest (eg the change component)
+	hardened := len(parent)
+	for hardened > 0 && parent[hardened-1] < bip32.FirstHardenedChild {
+		hardened--
+	}
+	path, rest := parent[:hardened], append(bip32.DerivationPath{}, parent[hardened:]...)
+	if internal {
+		if len(rest) == 0 {
+			return "", errors.E("path template has no change component", errors.Params{"template": template})
+		}
+		rest[len(rest)-1] = 1
+	}
+
+	master, err := p.masterKey()
+	if err != nil {
+		return "", err
+	}
+	defer master.Wipe()
+	var origin *bip32.KeyOrigin
+	if master.Depth > 0 {
+		// the master fingerprint of an account extended key is unknown
+		if int(master.Depth) != len(path) || binary.BigEndian.Uint32(master.ChildNumber) != path[len(path)-1] {
+			return "", errors.E("extended key doesn't match the descriptor account path", errors.Params{"depth": master.Depth, "path": path.String()})
+		}
+	} else {
+		keyOrigin := master.Origin(path)
+		origin = &keyOrigin
+	}
+	key, err := p.derivePath(master, path[master.Depth:])
+	if err != nil {
+		return "", err
+	}
+	defer key.Wipe()
+	d := &descriptor.Descriptor{
+		Type:   t,
+		Origin: origin,
+		Key:    key.PublicKey(),
+		Path:   rest,
+		Ranged: true,
+	}
+	return d.String(), nil
+}
@@ -0,0 +1,104 @@
+package descriptor
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// inputCharset is the character set of the descriptors, ordered to have the
+	// most common characters in the first group of 32
+	inputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+		"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+		"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+
+	// checksumCharset is the bech32 character set used to encode the checksum
+	checksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+	// checksumLength is the number of characters of the descriptor checksum
+	checksumLength = 8
+)
+
+// checksumGenerator is the generator of the BCH code used by the descriptor checksum
+var checksumGenerator = [5]uint64{0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd}
+
+// Checksum computes the BIP380 checksum of the descriptor without the checksum
+// It returns the 8 characters checksum and an error if the descriptor has invalid characters
+func Checksum(desc string) (string, error) {
+	c := uint64(1)
+	cls, clsCount := 0, 0
+	for i, ch := range desc {
+		pos := strings.IndexRune(inputCharset, ch)
+		if pos < 0 {
+			return "", fmt.Errorf("%w: invalid character %q at position %d", ErrInvalidDescriptor, ch, i)
+		}
+		// emit a symbol for the position inside the group, for every character
+		c = polymod(c, uint64(pos&31))
+		// accumulate the group numbers
+		cls = cls*3 + pos>>5
+		clsCount++
+		if clsCount == 3 {
+			// emit an extra symbol representing the group numbers, for every 3 characters
+			c = polymod(c, uint64(cls))
+			cls, clsCount = 0, 0
+		}
+	}
+	if clsCount > 0 {
+		c = polymod(c, uint64(cls))
+	}
+	// shift further to determine the checksum
+	for i := 0; i < checksumLength; i++ {
+		c = polymod(c, 0)
+	}
+	// prevent appending zeroes from not affecting the checksum
+	c ^= 1
+
+	result := make([]byte, checksumLength)
+	for i := range result {
+		result[i] = checksumCharset[(c>>(5*(7-i)))&31]
+	}
+	return string(result), nil
+}
+
+// AddChecksum appends the checksum to the descriptor, like pkh(...)#checksum
+// It returns the descriptor with checksum and an error if occurs
+func AddChecksum(desc string) (string, error) {
+	checksum, err := Checksum(desc)
+	if err != nil {
+		return "", err
+	}
+	return desc + "#" + checksum, nil
+}
+
+// SplitChecksum validates and removes the checksum of the descriptor, the checksum is optional
+// It returns the descriptor without checksum and an error if the checksum doesn't match
+func SplitChecksum(desc string) (string, error) {
+	position := strings.LastIndex(desc, "#")
+	if position < 0 {
+		return desc, nil
+	}
+	payload, checksum := desc[:position], desc[position+1:]
+	if len(checksum) != checksumLength {
+		return "", fmt.Errorf("%w: checksum %q must have %d characters", ErrInvalidChecksum, checksum, checksumLength)
+	}
+	want, err := Checksum(payload)
+	if err != nil {
+		return "", err
+	}
+	if checksum != want {
+		return "", fmt.Errorf("%w: got %q, expected %q", ErrInvalidChecksum, checksum, want)
+	}
+	return payload, nil
+}
+
+// polymod processes one symbol of the checksum BCH code
+func polymod(c, value uint64) uint64 {
+	top := c >> 35
+	c = (c&0x7ffffffff)<<5 ^ value
+	for i, generator := range checksumGenerator {
+		if (top>>uint(i))&1 == 1 {
+			c ^= generator
+		}
+	}
+	return c
+}
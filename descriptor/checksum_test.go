@@ -0,0 +1,41 @@
+package descriptor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecksum(t *testing.T) {
+	// BIP380 test vectors
+	checksum, err := Checksum("raw(deadbeef)")
+	assert.NoError(t, err)
+	assert.Equal(t, "89f8spxm", checksum)
+
+	desc, err := AddChecksum("raw(deadbeef)")
+	assert.NoError(t, err)
+	assert.Equal(t, "raw(deadbeef)#89f8spxm", desc)
+
+	for _, valid := range []string{"raw(deadbeef)#89f8spxm", "raw(deadbeef)"} {
+		payload, err := SplitChecksum(valid)
+		assert.NoError(t, err)
+		assert.Equal(t, "raw(deadbeef)", payload)
+	}
+
+	for _, invalid := range []string{
+		"raw(deadbeef)#",          // missing checksum
+		"raw(deadbeef)#89f8spxmx", // too long checksum
+		"raw(deadbeef)#89f8spx",   // too short checksum
+		"raw(deedbeef)#89f8spxm",  // error in payload
+		"raw(deedbeef)##9f8spxm",  // error in checksum
+	} {
+		_, err := SplitChecksum(invalid)
+		assert.True(t, errors.Is(err, ErrInvalidChecksum), "descriptor %q: %v", invalid, err)
+	}
+
+	_, err = SplitChecksum("raw(Ü)#00000000")
+	assert.True(t, errors.Is(err, ErrInvalidDescriptor))
+	_, err = Checksum("raw(Ü)")
+	assert.True(t, errors.Is(err, ErrInvalidDescriptor))
+}
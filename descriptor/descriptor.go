@@ -0,0 +1,253 @@
+// Package descriptor implements the output script descriptors (BIP380) of the
+// single key scripts, pkh (BIP381), wpkh (BIP382) and key path only tr (BIP386),
+// with extended public keys, to export and import watch-only address pools.
+package descriptor
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Pantani/pool-party/bip32"
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/btcsuite/btcutil"
+)
+
+var (
+	// ErrInvalidDescriptor is returned when parsing a malformed descriptor
+	ErrInvalidDescriptor = errors.New("invalid descriptor")
+
+	// ErrInvalidChecksum is returned when the descriptor checksum doesn't match
+	ErrInvalidChecksum = errors.New("invalid descriptor checksum")
+
+	// ErrUnsupportedDescriptor is returned for valid descriptors not supported by the package,
+	// like script descriptors, taproot script trees and non extended keys
+	ErrUnsupportedDescriptor = errors.New("unsupported descriptor")
+
+	// ErrUnsupportedCoin is returned when deriving addresses for a coin without the descriptor script type
+	ErrUnsupportedCoin = errors.New("descriptor script type not supported by the coin")
+
+	// ErrIndexNotRanged is returned when deriving an index other than 0 of a descriptor without wildcard
+	ErrIndexNotRanged = errors.New("descriptor is not ranged")
+
+	// ErrInvalidTaprootTweak is returned when the taproot tweak results in an invalid key
+	ErrInvalidTaprootTweak = errors.New("invalid taproot tweak")
+)
+
+type Type string
+
+const (
+	PKH  Type = "pkh"  // pay to public key hash (BIP44 addresses)
+	WPKH Type = "wpkh" // pay to witness public key hash (BIP84 addresses)
+	TR   Type = "tr"   // pay to taproot key path only (BIP86 addresses)
+)
+
+// Purpose returns the BIP43 purpose of the derivation path for the script type
+func (t Type) Purpose() uint32 {
+	switch t {
+	case WPKH:
+		return 84
+	case TR:
+		return 86
+	default:
+		return 44
+	}
+}
+
+// Descriptor represents a single key output descriptor with an extended public key,
+// like wpkh([d34db33f/84'/0'/0']xpub.../0/*)
+type Descriptor struct {
+	Type   Type
+	Origin *bip32.KeyOrigin     // origin of the extended key, optional
+	Key    *bip32.Key           // extended public key
+	Path   bip32.DerivationPath // unhardened derivation steps after the extended key
+	Ranged bool                 // the path ends with the /* wildcard
+}
+
+// Parse parses the descriptor, validating the checksum if present. Extended private keys
+// are converted to public keys, so the descriptor is always watch-only.
+// It returns the descriptor and an error if occurs
+func Parse(desc string) (*Descriptor, error) {
+	payload, err := SplitChecksum(strings.TrimSpace(desc))
+	if err != nil {
+		return nil, err
+	}
+	open := strings.Index(payload, "(")
+	if open < 0 || !strings.HasSuffix(payload, ")") {
+		return nil, fmt.Errorf("%w: expected script(key)", ErrInvalidDescriptor)
+	}
+	result := &Descriptor{Type: Type(payload[:open])}
+	switch result.Type {
+	case PKH, WPKH, TR:
+	default:
+		return nil, fmt.Errorf("%w: script %q", ErrUnsupportedDescriptor, result.Type)
+	}
+	expression := payload[open+1 : len(payload)-1]
+	if strings.ContainsAny(expression, "(),{}") {
+		return nil, fmt.Errorf("%w: nested scripts and taproot script trees", ErrUnsupportedDescriptor)
+	}
+	if err := result.parseKey(expression); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// parseKey parses the key expression, [origin]xpub/path/*
+func (d *Descriptor) parseKey(expression string) error {
+	if strings.HasPrefix(expression, "[") {
+		end := strings.Index(expression, "]")
+		if end < 0 {
+			return fmt.Errorf("%w: key origin without closing bracket", ErrInvalidDescriptor)
+		}
+		origin, err := bip32.ParseKeyOrigin(expression[1:end])
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidDescriptor, err)
+		}
+		d.Origin = &origin
+		expression = expression[end+1:]
+	}
+
+	components := strings.Split(expression, "/")
+	if _, err := hex.DecodeString(components[0]); err == nil {
+		return fmt.Errorf("%w: only extended keys are supported", ErrUnsupportedDescriptor)
+	}
+	key, err := bip32.B58Deserialize(components[0])
+	if err != nil {
+		return fmt.Errorf("%w: extended key: %v", ErrInvalidDescriptor, err)
+	}
+	d.Key = key.PublicKey()
+
+	steps := components[1:]
+	if len(steps) > 0 && steps[len(steps)-1] == "*" {
+		d.Ranged = true
+		steps = steps[:len(steps)-1]
+	}
+	d.Path = bip32.DerivationPath{}
+	for _, step := range steps {
+		index, err := bip32.ParseChildNumber(step)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidDescriptor, err)
+		}
+		if index >= bip32.FirstHardenedChild {
+			return fmt.Errorf("%w: hardened derivation %q from a public key", ErrUnsupportedDescriptor, step)
+		}
+		d.Path = append(d.Path, index)
+	}
+	return nil
+}
+
+// String implements the stringer interface, encoding the descriptor with checksum
+func (d *Descriptor) String() string {
+	var key strings.Builder
+	if d.Origin != nil {
+		key.WriteString("[" + d.Origin.String() + "]")
+	}
+	key.WriteString(d.Key.String())
+	key.WriteString(strings.TrimPrefix(d.Path.String(), "m"))
+	if d.Ranged {
+		key.WriteString("/*")
+	}
+	desc := fmt.Sprintf("%s(%s)", d.Type, key.String())
+	result, err := AddChecksum(desc)
+	if err != nil {
+		return desc
+	}
+	return result
+}
+
+// DeriveKey derives the public key of the descriptor index
+// It returns the derived key, the key origin from the master key and an error if occurs
+func (d *Descriptor) DeriveKey(index uint32) (*bip32.Key, bip32.KeyOrigin, error) {
+	path := append(bip32.DerivationPath{}, d.Path...)
+	if d.Ranged {
+		path = append(path, index)
+	} else if index != 0 {
+		return nil, bip32.KeyOrigin{}, ErrIndexNotRanged
+	}
+	key, err := d.Key.DerivePath(path)
+	if err != nil {
+		return nil, bip32.KeyOrigin{}, err
+	}
+
+	// without origin, the extended key is the root of the derivation
+	origin := bip32.KeyOrigin{Fingerprint: d.Key.Fingerprint()}
+	if d.Origin != nil {
+		origin.Fingerprint = d.Origin.Fingerprint
+		origin.Path = append(origin.Path, d.Origin.Path...)
+	}
+	origin.Path = append(origin.Path, path...)
+	return key, origin, nil
+}
+
+// Address derives the coin address of the descriptor index
+// It returns the watch-only address, without private key, and an error if occurs
+func (d *Descriptor) Address(coin *bip44.Altcoin, index int) (bip44.Address, error) {
+	if coin == nil || coin.IsEthereum() || (d.Type != PKH && !coin.SupportsSegwit()) {
+		return bip44.Address{}, ErrUnsupportedCoin
+	}
+	if index < 0 || int64(index) >= int64(bip32.FirstHardenedChild) {
+		return bip44.Address{}, fmt.Errorf("%w: index %d out of range", ErrInvalidDescriptor, index)
+	}
+	key, origin, err := d.DeriveKey(uint32(index))
+	if err != nil {
+		return bip44.Address{}, err
+	}
+
+	var address string
+	switch d.Type {
+	case PKH:
+		addr, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(key.Key), coin.Params())
+		if err != nil {
+			return bip44.Address{}, err
+		}
+		address = addr.String()
+	case WPKH:
+		addr, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(key.Key), coin.Params())
+		if err != nil {
+			return bip44.Address{}, err
+		}
+		address = addr.String()
+	case TR:
+		outputKey, err := taprootOutputKey(key.Key)
+		if err != nil {
+			return bip44.Address{}, err
+		}
+		address, err = encodeSegwitV1Address(coin.Bech32HRP, outputKey)
+		if err != nil {
+			return bip44.Address{}, err
+		}
+	default:
+		return bip44.Address{}, fmt.Errorf("%w: script %q", ErrUnsupportedDescriptor, d.Type)
+	}
+	return bip44.Address{
+		Address: address,
+		Pubkey:  hex.EncodeToString(key.Key),
+		Index:   index,
+		Origin:  &origin,
+	}, nil
+}
+
+// Addresses derives the coin addresses of the descriptor from start to start+qty
+// It returns the watch-only addresses and an error if occurs
+func (d *Descriptor) Addresses(coin *bip44.Altcoin, start, qty int) (bip44.Addresses, error) {
+	return d.AddressesContext(context.Background(), coin, start, qty)
+}
+
+// AddressesContext is like Addresses, but stops the derivation when the context is done
+// It returns the addresses derived before the cancellation and the context error
+func (d *Descriptor) AddressesContext(ctx context.Context, coin *bip44.Altcoin, start, qty int) (bip44.Addresses, error) {
+	addresses := make(bip44.Addresses, 0, qty)
+	for i := start; i < start+qty; i++ {
+		if err := ctx.Err(); err != nil {
+			return addresses, err
+		}
+		address, err := d.Address(coin, i)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, nil
+}
@@ -0,0 +1,141 @@
+package descriptor
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	// account xpubs of the "abandon abandon ... about" mnemonic, from the BIP44, BIP84 and BIP86 test vectors
+	bip44Xpub = "xpub6BosfCnifzxcFwrSzQiqu2DBVTshkCXacvNsWGYJVVhhawA7d4R5WSWGFNbi8Aw6ZRc1brxMyWMzG3DSSSSoekkudhUd9yLb6qx39T9nMdj"
+	bip84Xpub = "xpub6CatWdiZiodmUeTDp8LT5or8nmbKNcuyvz7WyksVFkKB4RHwCD3XyuvPEbvqAQY3rAPshWcMLoP2fMFMKHPJ4ZeZXYVUhLv1VMrjPC7PW6V"
+	bip86Xpub = "xpub6BgBgsespWvERF3LHQu6CnqdvfEvtMcQjYrcRzx53QJjSxarj2afYWcLteoGVky7D3UKDP9QyrLprQ3VCECoY49yfdDEHGCtMMj92pReUsQ"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		desc      string
+		addresses []string
+	}{
+		{
+			desc:      "pkh([73c5da0a/44'/0'/0']" + bip44Xpub + "/0/*)",
+			addresses: []string{"1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA", "1Ak8PffB2meyfYnbXZR9EGfLfFZVpzJvQP"},
+		},
+		{
+			desc:      "wpkh([73c5da0a/84'/0'/0']" + bip84Xpub + "/0/*)",
+			addresses: []string{"bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", "bc1qnjg0jd8228aq7egyzacy8cys3knf9xvrerkf9g"},
+		},
+		{
+			desc:      "wpkh([73c5da0a/84h/0h/0h]" + bip84Xpub + "/1/*)",
+			addresses: []string{"bc1q8c6fshw2dlwun7ekn9qwf37cu2rn755upcp6el"},
+		},
+		{
+			desc:      "tr([73c5da0a/86'/0'/0']" + bip86Xpub + "/0/*)",
+			addresses: []string{"bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr", "bc1p4qhjn9zdvkux4e44uhx8tc55attvtyu358kutcqkudyccelu0was9fqzwh"},
+		},
+		{
+			desc:      "tr(" + bip86Xpub + "/1/*)",
+			addresses: []string{"bc1p3qkhfews2uk44qtvauqyr2ttdsw7svhkl9nkm9s9c3x4ax5h60wqwruhk7"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			d, err := Parse(tt.desc)
+			assert.NoError(t, err)
+			assert.True(t, d.Ranged)
+
+			// the encoded descriptor has a valid checksum and is parsed back
+			encoded := d.String()
+			payload, err := SplitChecksum(encoded)
+			assert.NoError(t, err)
+			assert.NotEqual(t, payload, encoded)
+			parsed, err := Parse(encoded)
+			assert.NoError(t, err)
+			assert.Equal(t, d, parsed)
+
+			addresses, err := d.Addresses(bip44.CoinList[bip44.Bitcoin], 0, len(tt.addresses))
+			assert.NoError(t, err)
+			for i, addr := range addresses {
+				assert.Equal(t, tt.addresses[i], addr.Address)
+				assert.Equal(t, i, addr.Index)
+				assert.Empty(t, addr.Privkey)
+			}
+		})
+	}
+}
+
+func TestDescriptor_Origin(t *testing.T) {
+	d, err := Parse("wpkh([73c5da0a/84'/0'/0']" + bip84Xpub + "/0/*)")
+	assert.NoError(t, err)
+	addr, err := d.Address(bip44.CoinList[bip44.Litecoin], 7)
+	assert.NoError(t, err)
+	assert.Equal(t, "73c5da0a/84'/0'/0'/0/7", addr.Origin.String())
+	assert.Contains(t, addr.Address, "ltc1")
+
+	// without origin the extended key is the root of the derivation
+	d, err = Parse("pkh(" + bip44Xpub + "/1/2)")
+	assert.NoError(t, err)
+	assert.False(t, d.Ranged)
+	key, origin, err := d.DeriveKey(0)
+	assert.NoError(t, err)
+	assert.Equal(t, d.Key.Fingerprint(), origin.Fingerprint)
+	assert.Equal(t, hex.EncodeToString(d.Key.Fingerprint())+"/1/2", origin.String())
+	assert.Equal(t, d.Key.Depth+2, key.Depth)
+
+	_, _, err = d.DeriveKey(1)
+	assert.Equal(t, ErrIndexNotRanged, err)
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		desc string
+		err  error
+	}{
+		{desc: "wpkh(" + bip84Xpub + "/0/*)#00000000", err: ErrInvalidChecksum},
+		{desc: "wpkh(" + bip84Xpub + "/0/*", err: ErrInvalidDescriptor},
+		{desc: "wpkh([73c5da0a/84'/0'/0'" + bip84Xpub + "/0/*)", err: ErrInvalidDescriptor},
+		{desc: "wpkh([73c5da/84'/0'/0']" + bip84Xpub + "/0/*)", err: ErrInvalidDescriptor},
+		{desc: "wpkh(" + bip84Xpub[:len(bip84Xpub)-1] + "/0/*)", err: ErrInvalidDescriptor},
+		{desc: "wpkh(" + bip84Xpub + "/0/a)", err: ErrInvalidDescriptor},
+		{desc: "wpkh(" + bip84Xpub + "/0'/*)", err: ErrUnsupportedDescriptor},
+		{desc: "wpkh(" + bip84Xpub + "/0/*')", err: ErrInvalidDescriptor},
+		{desc: "sh(wpkh(" + bip84Xpub + "/0/*))", err: ErrUnsupportedDescriptor},
+		{desc: "tr(" + bip86Xpub + "/0/*,pk(" + bip86Xpub + "))", err: ErrUnsupportedDescriptor},
+		{desc: "wpkh(02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5)", err: ErrUnsupportedDescriptor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := Parse(tt.desc)
+			assert.True(t, errors.Is(err, tt.err), "error %v", err)
+		})
+	}
+
+	d, err := Parse("tr(" + bip86Xpub + "/0/*)")
+	assert.NoError(t, err)
+	_, err = d.Address(bip44.CoinList[bip44.Dogecoin], 0)
+	assert.Equal(t, ErrUnsupportedCoin, err)
+	_, err = d.Address(bip44.CoinList[bip44.Ethereum], 0)
+	assert.Equal(t, ErrUnsupportedCoin, err)
+}
+
+func TestTaprootOutputKey(t *testing.T) {
+	// BIP86 test vector m/86'/0'/0'/0/0
+	internal, _ := hex.DecodeString("03cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115")
+	outputKey, err := taprootOutputKey(internal)
+	assert.NoError(t, err)
+	assert.Equal(t, "a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c", hex.EncodeToString(outputKey))
+
+	// the output key doesn't depend on the internal key y parity
+	internal[0] = 0x02
+	outputKey, err = taprootOutputKey(internal)
+	assert.NoError(t, err)
+	assert.Equal(t, "a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c", hex.EncodeToString(outputKey))
+
+	address, err := encodeSegwitV1Address("bc", outputKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr", address)
+}
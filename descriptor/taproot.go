@@ -0,0 +1,113 @@
+package descriptor
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcutil/bech32"
+)
+
+const (
+	// bech32mConst is the checksum constant of the bech32m encoding (BIP350)
+	bech32mConst = 0x2bc830a3
+
+	// bech32Charset is the character set of the bech32 encodings
+	bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+// taggedHash computes the BIP340 tagged hash, sha256(sha256(tag) || sha256(tag) || msg)
+func taggedHash(tag string, msg ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, m := range msg {
+		h.Write(m)
+	}
+	return h.Sum(nil)
+}
+
+// taprootOutputKey computes the BIP86 output key of a key path only taproot output,
+// tweaking the internal key with the hash of the x-only internal key
+// It returns the 32 bytes x-only output key and an error if occurs
+func taprootOutputKey(pubKey []byte) ([]byte, error) {
+	curve := btcec.S256()
+	internal, err := btcec.ParsePubKey(pubKey, curve)
+	if err != nil {
+		return nil, err
+	}
+	// the internal key is used with the even y coordinate
+	x, y := internal.X, new(big.Int).Set(internal.Y)
+	if y.Bit(0) == 1 {
+		y.Sub(curve.P, y)
+	}
+	xOnly := paddedBytes(x)
+
+	tweak := taggedHash("TapTweak", xOnly)
+	if new(big.Int).SetBytes(tweak).Cmp(curve.N) >= 0 {
+		return nil, ErrInvalidTaprootTweak
+	}
+	tx, ty := curve.ScalarBaseMult(tweak)
+	qx, qy := curve.Add(x, y, tx, ty)
+	if qx.Sign() == 0 && qy.Sign() == 0 {
+		return nil, ErrInvalidTaprootTweak
+	}
+	return paddedBytes(qx), nil
+}
+
+// paddedBytes returns the 32 bytes big endian representation of the field element
+func paddedBytes(n *big.Int) []byte {
+	b := n.Bytes()
+	return append(make([]byte, 32-len(b)), b...)
+}
+
+// encodeSegwitV1Address encodes the segwit version 1 witness program as a bech32m address (BIP350)
+// It returns the address and an error if occurs
+func encodeSegwitV1Address(hrp string, program []byte) (string, error) {
+	converted, err := bech32.ConvertBits(program, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	data := append([]byte{0x01}, converted...)
+
+	values := make([]byte, 0, len(hrp)*2+1+len(data)+6)
+	for _, ch := range []byte(hrp) {
+		values = append(values, ch>>5)
+	}
+	values = append(values, 0)
+	for _, ch := range []byte(hrp) {
+		values = append(values, ch&31)
+	}
+	values = append(values, data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	mod := bech32Polymod(values) ^ bech32mConst
+
+	var result strings.Builder
+	result.WriteString(strings.ToLower(hrp))
+	result.WriteByte('1')
+	for _, value := range data {
+		result.WriteByte(bech32Charset[value])
+	}
+	for i := 0; i < 6; i++ {
+		result.WriteByte(bech32Charset[(mod>>uint(5*(5-i)))&31])
+	}
+	return result.String(), nil
+}
+
+// bech32Polymod computes the BIP173 checksum polymod of the values
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, value := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(value)
+		for i, g := range generator {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= g
+			}
+		}
+	}
+	return chk
+}
@@ -0,0 +1,140 @@
+package pool_party
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/Pantani/pool-party/descriptor"
+	"github.com/stretchr/testify/assert"
+)
+
+const abandonMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestPool_Descriptor(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Bitcoin, abandonMnemonic, "")
+	tests := []struct {
+		t        descriptor.Type
+		internal bool
+		prefix   string
+		address  string
+	}{
+		{t: descriptor.PKH, prefix: "pkh([73c5da0a/44'/0'/0']xpub6BosfCnifzxcFwrSzQiqu2DBVTshkCXacvNsWGYJVVhhawA7d4R5WSWGFNbi8Aw6ZRc1brxMyWMzG3DSSSSoekkudhUd9yLb6qx39T9nMdj/0/*)#", address: "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA"},
+		{t: descriptor.WPKH, prefix: "wpkh([73c5da0a/84'/0'/0']xpub6CatWdiZiodmUeTDp8LT5or8nmbKNcuyvz7WyksVFkKB4RHwCD3XyuvPEbvqAQY3rAPshWcMLoP2fMFMKHPJ4ZeZXYVUhLv1VMrjPC7PW6V/0/*)#", address: "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"},
+		{t: descriptor.WPKH, internal: true, prefix: "wpkh([73c5da0a/84'/0'/0']xpub6CatWdiZiodmUeTDp8LT5or8nmbKNcuyvz7WyksVFkKB4RHwCD3XyuvPEbvqAQY3rAPshWcMLoP2fMFMKHPJ4ZeZXYVUhLv1VMrjPC7PW6V/1/*)#", address: "bc1q8c6fshw2dlwun7ekn9qwf37cu2rn755upcp6el"},
+		{t: descriptor.TR, prefix: "tr([73c5da0a/86'/0'/0']xpub6BgBgsespWvERF3LHQu6CnqdvfEvtMcQjYrcRzx53QJjSxarj2afYWcLteoGVky7D3UKDP9QyrLprQ3VCECoY49yfdDEHGCtMMj92pReUsQ/0/*)#", address: "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			desc, err := pool.Descriptor(tt.t, 0, tt.internal)
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(desc, tt.prefix))
+
+			watchOnly, err := NewWatchOnlyPool(bip44.Bitcoin, desc)
+			assert.NoError(t, err)
+			assert.True(t, watchOnly.IsWatchOnly())
+			addresses, err := watchOnly.GenerateAddressPool(0, 3)
+			assert.NoError(t, err)
+			assert.Len(t, addresses, 3)
+			assert.Equal(t, tt.address, addresses[0].Address)
+
+			exported, err := watchOnly.Descriptor(tt.t, 0, tt.internal)
+			assert.NoError(t, err)
+			assert.Equal(t, desc, exported)
+		})
+	}
+
+	// the watch-only pkh addresses match the pool addresses
+	desc, err := pool.Descriptor(descriptor.PKH, 0, false)
+	assert.NoError(t, err)
+	watchOnly, err := NewWatchOnlyPool(bip44.Bitcoin, desc)
+	assert.NoError(t, err)
+	want, err := pool.GenerateAddressPool(10, 5)
+	assert.NoError(t, err)
+	got, err := watchOnly.GenerateAddressPool(10, 5)
+	assert.NoError(t, err)
+	for i := range want {
+		assert.Equal(t, want[i].Address, got[i].Address)
+		assert.Equal(t, want[i].Pubkey, got[i].Pubkey)
+		assert.Equal(t, want[i].Origin, got[i].Origin)
+	}
+
+	// watch-only pools can't sign
+	_, err = watchOnly.SignMessage(0, []byte("message"))
+	assert.Error(t, err)
+}
+
+func TestPool_DescriptorElectrum(t *testing.T) {
+	tests := []struct {
+		mnemonic string
+		t        descriptor.Type
+		change   string
+		segwit   bool
+	}{
+		{mnemonic: "cycle rocket west magnet parrot shuffle foot correct salt library feed song", t: descriptor.PKH, change: "m/1/{index}"},
+		{mnemonic: "bitter grass shiver impose acquire brush forget axis eager alone wine silver", t: descriptor.WPKH, change: "m/0'/1/{index}", segwit: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mnemonic, func(t *testing.T) {
+			pool, err := NewPoolWithElectrumSeed(bip44.Bitcoin, tt.mnemonic, "")
+			assert.NoError(t, err)
+
+			desc, err := pool.Descriptor(tt.t, 0, false)
+			assert.NoError(t, err)
+			watchOnly, err := NewWatchOnlyPool(bip44.Bitcoin, desc)
+			assert.NoError(t, err)
+			want, err := pool.GenerateAddressPool(0, 3)
+			assert.NoError(t, err)
+			got, err := watchOnly.GenerateAddressPool(0, 3)
+			assert.NoError(t, err)
+			for i := range want {
+				assert.Equal(t, want[i].Address, got[i].Address)
+				assert.Equal(t, want[i].Pubkey, got[i].Pubkey)
+			}
+
+			desc, err = pool.Descriptor(tt.t, 0, true)
+			assert.NoError(t, err)
+			watchOnly, err = NewWatchOnlyPool(bip44.Bitcoin, desc)
+			assert.NoError(t, err)
+			want, err = pool.GenerateAddressPoolWithPath(tt.change, 0, 0, 3)
+			assert.NoError(t, err)
+			if tt.segwit {
+				want, err = want.Segwit(bip44.Bitcoin)
+				assert.NoError(t, err)
+			}
+			got, err = watchOnly.GenerateAddressPool(0, 3)
+			assert.NoError(t, err)
+			for i := range want {
+				assert.Equal(t, want[i].Address, got[i].Address)
+			}
+
+			// the descriptor type must match the pool addresses
+			for _, other := range []descriptor.Type{descriptor.PKH, descriptor.WPKH, descriptor.TR} {
+				if other != tt.t {
+					_, err = pool.Descriptor(other, 0, false)
+					assert.Error(t, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPool_DescriptorInvalid(t *testing.T) {
+	_, err := NewPoolWithSecret(bip44.Ethereum, abandonMnemonic, "").Descriptor(descriptor.WPKH, 0, false)
+	assert.Error(t, err)
+	_, err = NewPoolWithSecret(bip44.Dogecoin, abandonMnemonic, "").Descriptor(descriptor.WPKH, 0, false)
+	assert.Error(t, err)
+	_, err = NewPool(bip44.Bitcoin).Descriptor(descriptor.WPKH, 0, false)
+	assert.Error(t, err)
+	_, err = NewPoolWithSecret(bip44.Bitcoin, abandonMnemonic, "").Descriptor(descriptor.WPKH, -1, false)
+	assert.Error(t, err)
+
+	desc, err := NewPoolWithSecret(bip44.Bitcoin, abandonMnemonic, "").Descriptor(descriptor.TR, 0, false)
+	assert.NoError(t, err)
+	_, err = NewWatchOnlyPool(bip44.Dash, desc)
+	assert.Error(t, err)
+	_, err = NewWatchOnlyPool(bip44.Ethereum, desc)
+	assert.Error(t, err)
+	_, err = NewWatchOnlyPool(bip44.Bitcoin, desc[:len(desc)-1]+"x")
+	assert.Error(t, err)
+}
@@ -0,0 +1,46 @@
+package pool_party
+
+import (
+	"github.com/Pantani/errors"
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/Pantani/pool-party/electrum"
+)
+
+const (
+	// electrumStandardTemplate and electrumSegwitTemplate are the receive paths of the
+	// Electrum standard and segwit wallets
+	electrumStandardTemplate bip44.PathTemplate = "m/0/{index}"
+	electrumSegwitTemplate   bip44.PathTemplate = "m/0'/0/{index}"
+)
+
+// NewPoolWithElectrumSeed creates the pool with an Electrum (2.0+) seed and passphrase, for the
+// wallets migrated from Electrum. Standard seeds generate the P2PKH addresses of m/0/i and
+// segwit seeds the P2WPKH addresses of m/0'/0/i. The two-factor authentication seeds aren't supported.
+// It returns the pool and an error if occurs
+func NewPoolWithElectrumSeed(coin bip44.Coin, mnemonic, passphrase string) (*Pool, error) {
+	altcoin, ok := bip44.CoinList[coin]
+	if !ok || altcoin.IsEthereum() {
+		return nil, errors.E("electrum seeds are only supported for UTXO coins", errors.Params{"coin": coin})
+	}
+	seedType, err := electrum.Type(mnemonic)
+	if err != nil {
+		return nil, errors.E(err, "invalid electrum seed")
+	}
+	if seedType == electrum.Segwit && !altcoin.SupportsSegwit() {
+		return nil, errors.E("segwit electrum seeds are not supported for the coin", errors.Params{"coin": coin})
+	}
+	seed, err := electrum.NewSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, errors.E(err, "invalid electrum seed", errors.Params{"type": seedType})
+	}
+	pool := &Pool{
+		coin:      coin,
+		seedBytes: seed,
+		template:  electrumStandardTemplate,
+	}
+	if seedType == electrum.Segwit {
+		pool.template = electrumSegwitTemplate
+		pool.segwit = true
+	}
+	return pool, nil
+}
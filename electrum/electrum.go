@@ -0,0 +1,119 @@
+// Package electrum implements the versioned seeds of the Electrum wallet (since Electrum 2.0),
+// the mnemonics of any word list whose HMAC-SHA512 has the seed type as prefix.
+//
+// The Electrum seed spec can be found at
+// https://electrum.readthedocs.io/en/latest/seedphrase.html
+package electrum
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// the PBKDF2 parameters of the seed
+	seedIterations = 2048
+	seedSize       = 64
+)
+
+var (
+	// ErrInvalidSeed is returned when the mnemonic doesn't have a known seed version
+	ErrInvalidSeed = errors.New("invalid electrum seed")
+
+	// ErrUnsupportedSeedType is returned for the two-factor authentication seeds,
+	// which need the TrustedCoin cosigner
+	ErrUnsupportedSeedType = errors.New("unsupported electrum seed type")
+
+	// versionKey is the HMAC-SHA512 key of the seed version
+	versionKey = []byte("Seed version")
+
+	// saltPrefix is the PBKDF2 salt prefix of the passphrase
+	saltPrefix = "electrum"
+)
+
+// SeedType is the seed version, the hex prefix of the mnemonic HMAC
+type SeedType string
+
+const (
+	Standard    SeedType = "01"  // P2PKH wallets, m/0/i
+	Segwit      SeedType = "100" // P2WPKH wallets, m/0'/0/i
+	TwoFA       SeedType = "101" // TrustedCoin two-factor wallets
+	TwoFASegwit SeedType = "102" // TrustedCoin two-factor segwit wallets
+)
+
+// seedTypes are the seed types checked by Type
+var seedTypes = []SeedType{Standard, Segwit, TwoFA, TwoFASegwit}
+
+// Type returns the seed type of the mnemonic
+// It returns the seed type and an error if the mnemonic isn't an electrum seed
+func Type(mnemonic string) (SeedType, error) {
+	mac := hmac.New(sha512.New, versionKey)
+	mac.Write([]byte(Normalize(mnemonic)))
+	version := hex.EncodeToString(mac.Sum(nil))
+	for _, t := range seedTypes {
+		if strings.HasPrefix(version, string(t)) {
+			return t, nil
+		}
+	}
+	return "", ErrInvalidSeed
+}
+
+// IsValid returns true if the mnemonic is a standard or segwit electrum seed
+func IsValid(mnemonic string) bool {
+	t, err := Type(mnemonic)
+	return err == nil && (t == Standard || t == Segwit)
+}
+
+// NewSeed creates the 64 bytes BIP32 seed of the standard or segwit mnemonic with the passphrase,
+// the PBKDF2-HMAC-SHA512 of the mnemonic with the "electrum" salt
+// It returns the seed and an error if the mnemonic isn't a supported electrum seed
+func NewSeed(mnemonic, passphrase string) ([]byte, error) {
+	t, err := Type(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	if t != Standard && t != Segwit {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSeedType, t)
+	}
+	salt := []byte(saltPrefix + Normalize(passphrase))
+	return pbkdf2.Key([]byte(Normalize(mnemonic)), salt, seedIterations, seedSize, sha512.New), nil
+}
+
+// Normalize normalizes the mnemonic or passphrase like Electrum, NFKD, lower case, without
+// combining marks (accents), single spaces and without the spaces between CJK characters
+func Normalize(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(norm.NFKD.String(text)) {
+		if norm.NFKD.PropertiesString(string(r)).CCC() == 0 {
+			b.WriteRune(r)
+		}
+	}
+	words := strings.Fields(b.String())
+	if len(words) == 0 {
+		return ""
+	}
+
+	b.Reset()
+	b.WriteString(words[0])
+	for i := 1; i < len(words); i++ {
+		prev := []rune(words[i-1])
+		if !isCJK(prev[len(prev)-1]) || !isCJK([]rune(words[i])[0]) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(words[i])
+	}
+	return b.String()
+}
+
+// isCJK returns true for the chinese, japanese and korean characters
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
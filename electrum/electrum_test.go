@@ -0,0 +1,86 @@
+package electrum
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the seeds of the Electrum tests
+const (
+	standardSeed = "cycle rocket west magnet parrot shuffle foot correct salt library feed song"
+	segwitSeed   = "wild father tree among universe such mobile favorite target dynamic credit identify"
+	twoFASeed    = "kiss live scene rude gate step hip quarter bunker oxygen motor glove"
+)
+
+func TestType(t *testing.T) {
+	tests := []struct {
+		mnemonic string
+		want     SeedType
+	}{
+		{mnemonic: standardSeed, want: Standard},
+		{mnemonic: segwitSeed, want: Segwit},
+		{mnemonic: twoFASeed, want: TwoFA},
+		{mnemonic: "なのか ひろい しなん まなぶ つぶす さがす おしゃれ かわく おいかける けさき かいとう さたん", want: Standard},
+		// the mnemonic is normalized
+		{mnemonic: "  Cycle rocket WEST magnet parrot shuffle foot correct salt library feed  song ", want: Standard},
+	}
+	for _, tt := range tests {
+		got, err := Type(tt.mnemonic)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+
+	_, err := Type("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	assert.Equal(t, ErrInvalidSeed, err)
+	assert.True(t, IsValid(segwitSeed))
+	assert.False(t, IsValid(twoFASeed))
+}
+
+func TestNewSeed(t *testing.T) {
+	tests := []struct {
+		mnemonic   string
+		passphrase string
+		want       string
+	}{
+		{
+			mnemonic: segwitSeed,
+			want:     "aac2a6302e48577ab4b46f23dbae0774e2e62c796f797d0a1b5faeb528301e3064342dafb79069e7c4c6b8c38ae11d7a973bec0d4f70626f8cc5184a8d0b0756",
+		},
+		{
+			mnemonic:   segwitSeed,
+			passphrase: "Did you ever hear the tragedy of Darth Plagueis the Wise?",
+			want:       "4aa29f2aeb0127efb55138ab9e7be83b36750358751906f86c662b21a1ea1370f949e6d1a12fa56d3d93cadda93038c76ac8118597364e46f5156fde6183c82f",
+		},
+		{
+			mnemonic: "なのか ひろい しなん まなぶ つぶす さがす おしゃれ かわく おいかける けさき かいとう さたん",
+			want:     "d3eaf0e44ddae3a5769cb08a26918e8b308258bcb057bb704c6f69713245c0b35cb92c03df9c9ece5eff826091b4e74041e010b701d44d610976ce8bfb66a8ad",
+		},
+	}
+	for _, tt := range tests {
+		seed, err := NewSeed(tt.mnemonic, tt.passphrase)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, hex.EncodeToString(seed))
+	}
+
+	_, err := NewSeed(twoFASeed, "")
+	assert.ErrorIs(t, err, ErrUnsupportedSeedType)
+	_, err = NewSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+	assert.ErrorIs(t, err, ErrInvalidSeed)
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: " Élan  VITAL\tcafé ", want: "elan vital cafe"},
+		{text: "なのか ひろい", want: "なのかひろい"},
+		{text: "ｆｕｌｌ　width", want: "full width"},
+		{text: "", want: ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Normalize(tt.text))
+	}
+}
@@ -0,0 +1,38 @@
+package pool_party
+
+import (
+	"testing"
+
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPoolWithElectrumSeed(t *testing.T) {
+	tests := []struct {
+		mnemonic string
+		want     string
+	}{
+		{mnemonic: "cycle rocket west magnet parrot shuffle foot correct salt library feed song", want: "1NNkttn1YvVGdqBW4PR6zvc3Zx3H5owKRf"},
+		{mnemonic: "bitter grass shiver impose acquire brush forget axis eager alone wine silver", want: "bc1q3g5tmkmlvxryhh843v4dz026avatc0zzr6h3af"},
+	}
+	for _, tt := range tests {
+		pool, err := NewPoolWithElectrumSeed(bip44.Bitcoin, tt.mnemonic, "")
+		assert.NoError(t, err)
+		addresses, err := pool.GenerateAddressPool(0, 2)
+		assert.NoError(t, err)
+		if assert.Len(t, addresses, 2) {
+			assert.Equal(t, tt.want, addresses[0].Address)
+			assert.NotEqual(t, addresses[0].Address, addresses[1].Address)
+		}
+	}
+
+	_, err := NewPoolWithElectrumSeed(bip44.Bitcoin, abandonMnemonic, "")
+	assert.Error(t, err)
+	_, err = NewPoolWithElectrumSeed(bip44.Ethereum, "cycle rocket west magnet parrot shuffle foot correct salt library feed song", "")
+	assert.Error(t, err)
+	_, err = NewPoolWithElectrumSeed(bip44.Dogecoin, "bitter grass shiver impose acquire brush forget axis eager alone wine silver", "")
+	assert.Error(t, err)
+	// two-factor authentication seed
+	_, err = NewPoolWithElectrumSeed(bip44.Bitcoin, "kiss live scene rude gate step hip quarter bunker oxygen motor glove", "")
+	assert.Error(t, err)
+}
@@ -9,25 +9,37 @@ import (
 
 func main() {
 	pool := pool_party.NewPoolWithSecret(bip44.Ethereum, "simple habit juice brush blush derive biology busy clown sister maple recipe", "1234")
-	logger.Info("pool with secret", logger.Params{"params": pool})
+
+	// Save the pool secrets into an encrypted keystore file
+	err := pool.Save("pool.keystore", "my strong password", pool_party.StandardScryptN, pool_party.StandardScryptP)
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	// Load the pool from the encrypted keystore file
+	pool, err = pool_party.LoadPool("pool.keystore", "my strong password")
+	if err != nil {
+		logger.Panic(err)
+	}
+	logger.Info("pool loaded from keystore")
 
 	// OR
 
 	pool = pool_party.NewPool(bip44.Ethereum)
 
 	// Generate new mnemonic with 128 bits
-	err := pool.GenerateMnemonic(128, "")
+	err = pool.GenerateMnemonic(128, "")
 	if err != nil {
 		logger.Panic(err)
 	}
-	logger.Info("mnemonic generated", logger.Params{"params": pool})
+	logger.Info("mnemonic generated")
 
 	// Generate new mnemonic with 256 bits
 	err = pool.GenerateMnemonic(256, "")
 	if err != nil {
 		logger.Panic(err)
 	}
-	logger.Info("mnemonic generated", logger.Params{"params": pool})
+	logger.Info("mnemonic generated")
 
 	// Generate 100 address starting by index 50 (50 - 150)
 	start := 50
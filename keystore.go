@@ -0,0 +1,457 @@
+package pool_party
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"io/ioutil"
+
+	"github.com/Pantani/errors"
+	"github.com/Pantani/pool-party/bip32"
+	"github.com/Pantani/pool-party/bip39"
+	"github.com/Pantani/pool-party/bip44"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// KeystoreVersion is the current version of the encrypted pool file format
+	KeystoreVersion = byte(1)
+
+	// StandardScryptN is the N parameter of Scrypt encryption algorithm, using 256MB
+	// memory and taking approximately 1s CPU time on a modern processor.
+	StandardScryptN = 1 << 18
+
+	// StandardScryptP is the P parameter of Scrypt encryption algorithm, using 256MB
+	// memory and taking approximately 1s CPU time on a modern processor.
+	StandardScryptP = 1
+
+	// LightScryptN is the N parameter of Scrypt encryption algorithm, using 4MB
+	// memory and taking approximately 100ms CPU time on a modern processor.
+	LightScryptN = 1 << 12
+
+	// LightScryptP is the P parameter of Scrypt encryption algorithm, using 4MB
+	// memory and taking approximately 100ms CPU time on a modern processor.
+	LightScryptP = 6
+
+	// MaxScryptN and MaxScryptP are the largest scrypt parameters accepted by the
+	// keystore, bounding the memory and CPU time of a crafted header to 1GB.
+	MaxScryptN = 1 << 20
+	MaxScryptP = 16
+
+	kdfScrypt     = byte(1)
+	scryptR       = 8
+	scryptKeyLen  = 64
+	saltLength    = 32
+	checkLength   = sha256.Size
+	nonceLength   = 12
+	magicLength   = 4
+	headerLength  = magicLength + 1 + 1 + 4 + 4 + 4 + saltLength + checkLength + nonceLength
+	keystorePerms = 0600
+
+	// MaxKeystoreFieldLength is the largest payload field, like the passphrase,
+	// the field lengths are encoded in 2 bytes
+	MaxKeystoreFieldLength = 0xffff
+)
+
+var (
+	// keystoreMagic identifies an encrypted pool file
+	keystoreMagic = []byte("PPKS")
+
+	// ErrInvalidKeystore is returned when the data is not a pool keystore
+	ErrInvalidKeystore = errors.E("invalid pool keystore")
+
+	// ErrUnsupportedKeystoreVersion is returned when the keystore was written by
+	// an unknown version of the format
+	ErrUnsupportedKeystoreVersion = errors.E("unsupported pool keystore version")
+
+	// ErrWrongPassphrase is returned when the keystore can't be decrypted with
+	// the given passphrase
+	ErrWrongPassphrase = errors.E("could not decrypt key with given passphrase")
+
+	// ErrCorruptedKeystore is returned when the keystore ciphertext or header
+	// was modified
+	ErrCorruptedKeystore = errors.E("pool keystore authentication failed")
+
+	// ErrInvalidScryptParams is returned when the scrypt parameters are out of
+	// the keystore limits
+	ErrInvalidScryptParams = errors.E("invalid pool keystore scrypt parameters")
+
+	// ErrKeystoreFieldTooLong is returned when a pool secret is longer than MaxKeystoreFieldLength
+	ErrKeystoreFieldTooLong = errors.E("pool keystore field too long")
+)
+
+// keystoreHeader represents the versioned plaintext header of an encrypted pool
+//
+// The layout is:
+// magic (4) | version (1) | kdf (1) | N (4) | r (4) | p (4) | salt (32) | check (32) | nonce (12)
+// The whole header is authenticated as additional data of the AES-GCM ciphertext.
+type keystoreHeader struct {
+	Version byte
+	KDF     byte
+	N       uint32
+	R       uint32
+	P       uint32
+	Salt    []byte
+	Check   []byte
+	Nonce   []byte
+}
+
+// keystore payload field tags
+const (
+	fieldCoin = byte(iota + 1)
+	fieldMnemonic
+	fieldPassphrase
+	fieldSeed
+	fieldExtendedKey
+	fieldTemplate
+	fieldSegwit
+	fieldNested
+	fieldLegacy
+)
+
+// keystorePayload represents the secrets stored inside the encrypted pool.
+// The secrets are kept in byte slices, so they can be zeroed after use.
+//
+// The payload is encoded as a sequence of fields:
+// tag (1) | length (2) | value
+type keystorePayload struct {
+	Coin        bip44.Coin
+	Mnemonic    []byte
+	Passphrase  []byte
+	Seed        []byte             // raw seed of NewPoolWithSeed
+	ExtendedKey []byte             // serialized xprv of NewPoolWithExtendedKey
+	Template    bip44.PathTemplate // path template of the pool addresses (eg Electrum seeds)
+	Segwit      bool
+	Nested      bool // nested segwit addresses (eg LND BIP49 pools)
+	Legacy      bool // legacy hdkeychain derivation (eg LND pools)
+}
+
+// Encrypt encrypts the pool secrets with the password, using scrypt as KDF
+// and AES-256-GCM as cipher.
+// It returns the encrypted keystore and an error if occurs
+func (p *Pool) Encrypt(password string, scryptN, scryptP int) ([]byte, error) {
+	if !p.hasSecret() {
+		return nil, errors.E("empty mnemonic")
+	}
+	secrets := keystorePayload{
+		Coin:       p.coin,
+		Mnemonic:   p.mnemonic,
+		Passphrase: p.passphrase,
+		Seed:       p.seedBytes,
+		Template:   p.template,
+		Segwit:     p.segwit,
+		Nested:     p.nested,
+		Legacy:     p.legacy,
+	}
+	if p.extendedKey != nil {
+		key, err := p.extendedKey.Serialize()
+		if err != nil {
+			return nil, err
+		}
+		defer bip39.Zero(key)
+		secrets.ExtendedKey = key
+	}
+	payload, err := secrets.serialize()
+	if err != nil {
+		return nil, err
+	}
+	defer bip39.Zero(payload)
+
+	header := &keystoreHeader{
+		Version: KeystoreVersion,
+		KDF:     kdfScrypt,
+		N:       uint32(scryptN),
+		R:       scryptR,
+		P:       uint32(scryptP),
+		Salt:    make([]byte, saltLength),
+		Nonce:   make([]byte, nonceLength),
+	}
+	if err := header.validate(); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(header.Salt); err != nil {
+		return nil, errors.E(err, "reading from crypto/rand failed")
+	}
+	if _, err := rand.Read(header.Nonce); err != nil {
+		return nil, errors.E(err, "reading from crypto/rand failed")
+	}
+
+	derivedKey, err := header.deriveKey(password)
+	if err != nil {
+		return nil, err
+	}
+	defer bip39.Zero(derivedKey)
+	check := sha256.Sum256(derivedKey[32:])
+	header.Check = check[:]
+
+	aead, err := newAEAD(derivedKey[:32])
+	if err != nil {
+		return nil, err
+	}
+	ad := header.serialize()
+	return aead.Seal(ad, header.Nonce, payload, ad), nil
+}
+
+// Save encrypts the pool secrets and writes the keystore to the file path
+// It returns an error if occurs
+func (p *Pool) Save(path, password string, scryptN, scryptP int) error {
+	data, err := p.Encrypt(password, scryptN, scryptP)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, data, keystorePerms); err != nil {
+		return errors.E(err, "error to write the pool keystore", errors.Params{"path": path})
+	}
+	return nil
+}
+
+// DecryptPool decrypts a keystore generated by Pool.Encrypt
+// It returns the decrypted pool and an error if occurs
+func DecryptPool(data []byte, password string) (*Pool, error) {
+	header, err := parseKeystoreHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	derivedKey, err := header.deriveKey(password)
+	if err != nil {
+		return nil, err
+	}
+	defer bip39.Zero(derivedKey)
+	check := sha256.Sum256(derivedKey[32:])
+	if subtle.ConstantTimeCompare(check[:], header.Check) != 1 {
+		return nil, ErrWrongPassphrase
+	}
+
+	aead, err := newAEAD(derivedKey[:32])
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, header.Nonce, data[headerLength:], data[:headerLength])
+	if err != nil {
+		return nil, ErrCorruptedKeystore
+	}
+
+	defer bip39.Zero(plaintext)
+
+	payload, err := parseKeystorePayload(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := bip44.CoinList[payload.Coin]; !ok {
+		return nil, errors.E("Invalid coin", errors.Params{"coin": payload.Coin})
+	}
+	if payload.Template != "" {
+		if err := payload.Template.Validate(); err != nil {
+			return nil, ErrCorruptedKeystore
+		}
+	}
+	var pool *Pool
+	switch {
+	case len(payload.ExtendedKey) > 0:
+		// the deserialized key shares the memory of its input and the plaintext is zeroed on return
+		key, err := bip32.Deserialize(append([]byte{}, payload.ExtendedKey...))
+		if err != nil || !key.IsPrivate {
+			return nil, ErrCorruptedKeystore
+		}
+		pool = &Pool{coin: payload.Coin, extendedKey: key}
+	case len(payload.Seed) > 0:
+		if pool, err = NewPoolWithSeed(payload.Coin, payload.Seed); err != nil {
+			return nil, err
+		}
+	default:
+		pool = NewPoolWithSecretBytes(payload.Coin, payload.Mnemonic, payload.Passphrase)
+	}
+	pool.template = payload.Template
+	pool.segwit = payload.Segwit
+	pool.nested = payload.Nested
+	pool.legacy = payload.Legacy
+	return pool, nil
+}
+
+// LoadPool reads and decrypts a keystore file written by Pool.Save
+// It returns the decrypted pool and an error if occurs
+func LoadPool(path, password string) (*Pool, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.E(err, "error to read the pool keystore", errors.Params{"path": path})
+	}
+	return DecryptPool(data, password)
+}
+
+// deriveKey derives the 64 bytes key from the password using the header KDF parameters.
+// The first half is the AES key and the second half is used for the passphrase check.
+func (h *keystoreHeader) deriveKey(password string) ([]byte, error) {
+	if h.KDF != kdfScrypt {
+		return nil, ErrInvalidKeystore
+	}
+	if err := h.validate(); err != nil {
+		return nil, err
+	}
+	key, err := scrypt.Key([]byte(password), h.Salt, int(h.N), int(h.R), int(h.P), scryptKeyLen)
+	if err != nil {
+		return nil, errors.E(err, "error to derive the keystore key")
+	}
+	return key, nil
+}
+
+// validate checks the scrypt parameters are within the keystore limits, so an
+// untrusted header can't make the key derivation exhaust the memory or CPU
+func (h *keystoreHeader) validate() error {
+	if h.N <= 1 || h.N > MaxScryptN || h.N&(h.N-1) != 0 {
+		return ErrInvalidScryptParams
+	}
+	if h.R != scryptR || h.P < 1 || h.P > MaxScryptP {
+		return ErrInvalidScryptParams
+	}
+	return nil
+}
+
+// serialize encodes the header into the binary file format
+func (h *keystoreHeader) serialize() []byte {
+	buffer := new(bytes.Buffer)
+	buffer.Write(keystoreMagic)
+	buffer.WriteByte(h.Version)
+	buffer.WriteByte(h.KDF)
+	_ = binary.Write(buffer, binary.BigEndian, h.N)
+	_ = binary.Write(buffer, binary.BigEndian, h.R)
+	_ = binary.Write(buffer, binary.BigEndian, h.P)
+	buffer.Write(h.Salt)
+	buffer.Write(h.Check)
+	buffer.Write(h.Nonce)
+	return buffer.Bytes()
+}
+
+// parseKeystoreHeader decodes and validates the header of an encrypted pool
+func parseKeystoreHeader(data []byte) (*keystoreHeader, error) {
+	if len(data) < headerLength || !bytes.Equal(data[:magicLength], keystoreMagic) {
+		return nil, ErrInvalidKeystore
+	}
+	if data[magicLength] != KeystoreVersion {
+		return nil, ErrUnsupportedKeystoreVersion
+	}
+	offset := magicLength + 1
+	header := &keystoreHeader{Version: data[magicLength], KDF: data[offset]}
+	offset++
+	header.N = binary.BigEndian.Uint32(data[offset:])
+	offset += 4
+	header.R = binary.BigEndian.Uint32(data[offset:])
+	offset += 4
+	header.P = binary.BigEndian.Uint32(data[offset:])
+	offset += 4
+	header.Salt = data[offset : offset+saltLength]
+	offset += saltLength
+	header.Check = data[offset : offset+checkLength]
+	offset += checkLength
+	header.Nonce = data[offset : offset+nonceLength]
+	if err := header.validate(); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// serialize encodes the payload fields into a buffer allocated with the exact
+// size, so no partial copy of the secrets is left behind by a growing buffer
+// It returns the payload and an error if a field is longer than MaxKeystoreFieldLength
+func (s *keystorePayload) serialize() ([]byte, error) {
+	fields := []struct {
+		tag   byte
+		value []byte
+	}{
+		{fieldCoin, []byte(s.Coin)},
+		{fieldMnemonic, s.Mnemonic},
+		{fieldPassphrase, s.Passphrase},
+		{fieldSeed, s.Seed},
+		{fieldExtendedKey, s.ExtendedKey},
+		{fieldTemplate, []byte(s.Template)},
+		{fieldSegwit, flagValue(s.Segwit)},
+		{fieldNested, flagValue(s.Nested)},
+		{fieldLegacy, flagValue(s.Legacy)},
+	}
+	size := 0
+	for _, field := range fields {
+		if len(field.value) > MaxKeystoreFieldLength {
+			return nil, ErrKeystoreFieldTooLong
+		}
+		size += 3 + len(field.value)
+	}
+	buffer := make([]byte, 0, size)
+	for _, field := range fields {
+		buffer = append(buffer, field.tag, byte(len(field.value)>>8), byte(len(field.value)))
+		buffer = append(buffer, field.value...)
+	}
+	return buffer, nil
+}
+
+// parseKeystorePayload decodes the payload fields of a decrypted pool.
+// The secret fields share the memory of data.
+func parseKeystorePayload(data []byte) (*keystorePayload, error) {
+	payload := &keystorePayload{}
+	seen := make(map[byte]bool)
+	for len(data) > 0 {
+		if len(data) < 3 {
+			return nil, ErrCorruptedKeystore
+		}
+		tag, length := data[0], int(binary.BigEndian.Uint16(data[1:3]))
+		data = data[3:]
+		if length > len(data) || seen[tag] {
+			return nil, ErrCorruptedKeystore
+		}
+		seen[tag] = true
+		value := data[:length]
+		data = data[length:]
+		switch tag {
+		case fieldCoin:
+			payload.Coin = bip44.Coin(value)
+		case fieldMnemonic:
+			payload.Mnemonic = value
+		case fieldPassphrase:
+			payload.Passphrase = value
+		case fieldSeed:
+			payload.Seed = value
+		case fieldExtendedKey:
+			payload.ExtendedKey = value
+		case fieldTemplate:
+			payload.Template = bip44.PathTemplate(value)
+		case fieldSegwit, fieldNested, fieldLegacy:
+			if length != 1 || value[0] > 1 {
+				return nil, ErrCorruptedKeystore
+			}
+			flag := value[0] == 1
+			switch tag {
+			case fieldSegwit:
+				payload.Segwit = flag
+			case fieldNested:
+				payload.Nested = flag
+			default:
+				payload.Legacy = flag
+			}
+		default:
+			return nil, ErrCorruptedKeystore
+		}
+	}
+	if !seen[fieldCoin] || (payload.Segwit && payload.Nested) {
+		return nil, ErrCorruptedKeystore
+	}
+	return payload, nil
+}
+
+// flagValue encodes the flag field value, 1 if it's set
+func flagValue(flag bool) []byte {
+	if flag {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,223 @@
+package pool_party
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Pantani/pool-party/bip39"
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testMnemonic   = "rent slogan lemon nerve soup annual depend shift olympic similar bounce wait often fury slush fish crazy bring police level economy crush can energy"
+	testPassphrase = "1234"
+	testPassword   = "correct horse battery staple"
+)
+
+func TestPoolEncryptDecrypt(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Bitcoin, testMnemonic, testPassphrase)
+	data, err := pool.Encrypt(testPassword, LightScryptN, LightScryptP)
+	assert.NoError(t, err)
+	assert.Equal(t, keystoreMagic, data[:magicLength])
+	assert.Equal(t, KeystoreVersion, data[magicLength])
+	assert.NotContains(t, string(data), testMnemonic)
+
+	got, err := DecryptPool(data, testPassword)
+	assert.NoError(t, err)
+	assert.Equal(t, pool, got)
+}
+
+func TestPoolSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pool-keystore")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "pool.keystore")
+	pool := NewPoolWithSecret(bip44.Ethereum, testMnemonic, "")
+	assert.NoError(t, pool.Save(path, testPassword, LightScryptN, LightScryptP))
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(keystorePerms), info.Mode().Perm())
+
+	got, err := LoadPool(path, testPassword)
+	assert.NoError(t, err)
+	assert.Equal(t, pool, got)
+}
+
+func TestDecryptPoolWrongPassphrase(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Bitcoin, testMnemonic, testPassphrase)
+	data, err := pool.Encrypt(testPassword, LightScryptN, LightScryptP)
+	assert.NoError(t, err)
+
+	_, err = DecryptPool(data, "wrong password")
+	assert.Equal(t, ErrWrongPassphrase, err)
+}
+
+func TestDecryptPoolTampered(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Bitcoin, testMnemonic, testPassphrase)
+	data, err := pool.Encrypt(testPassword, LightScryptN, LightScryptP)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		offset  int
+		wantErr error
+	}{
+		{name: "tampered ciphertext", offset: headerLength + 1, wantErr: ErrCorruptedKeystore},
+		{name: "tampered tag", offset: len(data) - 1, wantErr: ErrCorruptedKeystore},
+		{name: "tampered nonce", offset: headerLength - 1, wantErr: ErrCorruptedKeystore},
+		{name: "tampered salt", offset: headerLength - nonceLength - checkLength - 1, wantErr: ErrWrongPassphrase},
+		{name: "tampered magic", offset: 0, wantErr: ErrInvalidKeystore},
+		{name: "tampered version", offset: magicLength, wantErr: ErrUnsupportedKeystoreVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := make([]byte, len(data))
+			copy(tampered, data)
+			tampered[tt.offset] ^= 0x01
+			_, err := DecryptPool(tampered, testPassword)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+
+	_, err = DecryptPool(data[:headerLength-1], testPassword)
+	assert.Equal(t, ErrInvalidKeystore, err)
+}
+
+func TestDecryptPoolOversizedScryptParams(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Bitcoin, testMnemonic, testPassphrase)
+	data, err := pool.Encrypt(testPassword, LightScryptN, LightScryptP)
+	assert.NoError(t, err)
+
+	offset := magicLength + 2
+	tests := []struct {
+		name  string
+		field int
+		value uint32
+	}{
+		{name: "oversized N", field: 0, value: 1 << 31},
+		{name: "N not power of two", field: 0, value: LightScryptN + 1},
+		{name: "N too small", field: 0, value: 1},
+		{name: "oversized r", field: 1, value: 1 << 20},
+		{name: "oversized p", field: 2, value: 1 << 30},
+		{name: "zero p", field: 2, value: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := make([]byte, len(data))
+			copy(tampered, data)
+			binary.BigEndian.PutUint32(tampered[offset+tt.field*4:], tt.value)
+			_, err := DecryptPool(tampered, testPassword)
+			assert.Equal(t, ErrInvalidScryptParams, err)
+		})
+	}
+
+	_, err = pool.Encrypt(testPassword, MaxScryptN*2, LightScryptP)
+	assert.Equal(t, ErrInvalidScryptParams, err)
+	_, err = pool.Encrypt(testPassword, LightScryptN, MaxScryptP+1)
+	assert.Equal(t, ErrInvalidScryptParams, err)
+}
+
+func TestParseKeystorePayload(t *testing.T) {
+	payload := &keystorePayload{
+		Coin:       bip44.Bitcoin,
+		Mnemonic:   []byte(testMnemonic),
+		Passphrase: []byte(testPassphrase),
+	}
+	data, err := payload.serialize()
+	assert.NoError(t, err)
+	assert.Equal(t, len(data), cap(data))
+
+	got, err := parseKeystorePayload(data)
+	assert.NoError(t, err)
+	assert.Equal(t, payload.Coin, got.Coin)
+	assert.Equal(t, payload.Mnemonic, got.Mnemonic)
+	assert.Equal(t, payload.Passphrase, got.Passphrase)
+	assert.Empty(t, got.Seed)
+	assert.Empty(t, got.ExtendedKey)
+
+	coin := append([]byte{fieldCoin, 0, byte(len(bip44.Bitcoin))}, bip44.Bitcoin...)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "truncated field", data: data[:len(data)-1]},
+		{name: "truncated tag", data: append(append([]byte{}, data...), fieldSeed)},
+		{name: "duplicated field", data: append(append([]byte{}, data...), data[:3+len(bip44.Bitcoin)]...)},
+		{name: "unknown field", data: append(append([]byte{}, data...), 0xff, 0, 0)},
+		{name: "missing coin", data: data[3+len(bip44.Bitcoin):]},
+		{name: "invalid segwit flag", data: append(append([]byte{}, coin...), fieldSegwit, 0, 1, 2)},
+		{name: "invalid nested flag", data: append(append([]byte{}, coin...), fieldNested, 0, 2, 1, 1)},
+		{name: "invalid legacy flag", data: append(append([]byte{}, coin...), fieldLegacy, 0, 1, 2)},
+		{name: "segwit and nested", data: append(append([]byte{}, coin...), fieldSegwit, 0, 1, 1, fieldNested, 0, 1, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseKeystorePayload(tt.data)
+			assert.Equal(t, ErrCorruptedKeystore, err)
+		})
+	}
+}
+
+func TestPoolEncryptEmptyMnemonic(t *testing.T) {
+	_, err := NewPool(bip44.Bitcoin).Encrypt(testPassword, LightScryptN, LightScryptP)
+	assert.Error(t, err)
+}
+
+func TestPoolEncryptDecryptSeedAndKey(t *testing.T) {
+	seedPool, err := NewPoolWithSeed(bip44.Bitcoin, bip39.NewSeed(testMnemonic, testPassphrase))
+	assert.NoError(t, err)
+	master, err := seedPool.masterKey()
+	assert.NoError(t, err)
+	keyPool, err := NewPoolWithExtendedKey(bip44.Dash, master.B58Serialize())
+	assert.NoError(t, err)
+
+	for _, pool := range []*Pool{seedPool, keyPool} {
+		data, err := pool.Encrypt(testPassword, LightScryptN, LightScryptP)
+		assert.NoError(t, err)
+		got, err := DecryptPool(data, testPassword)
+		assert.NoError(t, err)
+		assert.Equal(t, pool, got)
+	}
+}
+
+func TestPoolEncryptDecryptElectrum(t *testing.T) {
+	for _, mnemonic := range []string{
+		"cycle rocket west magnet parrot shuffle foot correct salt library feed song",
+		"bitter grass shiver impose acquire brush forget axis eager alone wine silver",
+	} {
+		pool, err := NewPoolWithElectrumSeed(bip44.Bitcoin, mnemonic, "")
+		assert.NoError(t, err)
+		data, err := pool.Encrypt(testPassword, LightScryptN, LightScryptP)
+		assert.NoError(t, err)
+		got, err := DecryptPool(data, testPassword)
+		assert.NoError(t, err)
+		assert.Equal(t, pool, got)
+
+		want, err := pool.GenerateAddressPool(0, 3)
+		assert.NoError(t, err)
+		addresses, err := got.GenerateAddressPool(0, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, want, addresses)
+	}
+}
+
+func TestPoolEncryptDecryptFieldLength(t *testing.T) {
+	passphrase := strings.Repeat("x", MaxKeystoreFieldLength)
+	pool := NewPoolWithSecret(bip44.Bitcoin, testMnemonic, passphrase)
+	data, err := pool.Encrypt(testPassword, LightScryptN, LightScryptP)
+	assert.NoError(t, err)
+	got, err := DecryptPool(data, testPassword)
+	assert.NoError(t, err)
+	assert.Equal(t, pool, got)
+
+	pool = NewPoolWithSecret(bip44.Bitcoin, testMnemonic, passphrase+"x")
+	_, err = pool.Encrypt(testPassword, LightScryptN, LightScryptP)
+	assert.Equal(t, ErrKeystoreFieldTooLong, err)
+}
@@ -0,0 +1,179 @@
+package pool_party
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"github.com/Pantani/errors"
+	"github.com/Pantani/pool-party/bip32"
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+)
+
+type MultisigScript string
+
+const (
+	P2SH      MultisigScript = "p2sh"       // legacy pay to script hash
+	P2WSH     MultisigScript = "p2wsh"      // native segwit pay to witness script hash
+	P2SHP2WSH MultisigScript = "p2sh-p2wsh" // nested segwit, P2WSH wrapped in P2SH
+
+	// maxP2SHMultisigKeys is the maximum number of compressed keys of a P2SH multisig,
+	// limited by the 520 bytes of the redeem script
+	maxP2SHMultisigKeys = 15
+
+	// maxWitnessMultisigKeys is the maximum number of keys of OP_CHECKMULTISIG
+	maxWitnessMultisigKeys = 20
+)
+
+// MultisigAddress represents a M-of-N multisig address with the scripts to spend it
+type MultisigAddress struct {
+	Address       string
+	PubKeys       []string // cosigner public keys sorted by BIP67
+	RedeemScript  string   // hex redeem script, for P2SH and P2SH-P2WSH
+	WitnessScript string   // hex witness script, for P2WSH and P2SH-P2WSH
+	Index         int
+}
+
+type MultisigAddresses []MultisigAddress
+
+// MultisigPool generates M-of-N multisig addresses from the cosigners account extended public keys
+type MultisigPool struct {
+	coin      bip44.Coin
+	script    MultisigScript
+	required  int
+	cosigners []*bip32.Key
+}
+
+// NewMultisigPool creates a M-of-N multisig pool from the N cosigners account extended public
+// keys (eg m/48'/0'/0'/2'), the addresses are derived at the receive path xpub/0/i of every cosigner
+// It returns the multisig pool and an error if occurs
+func NewMultisigPool(coin bip44.Coin, script MultisigScript, required int, xpubs []string) (*MultisigPool, error) {
+	altcoin, ok := bip44.CoinList[coin]
+	if !ok || altcoin.IsEthereum() {
+		return nil, errors.E("multisig is only supported for UTXO coins", errors.Params{"coin": coin})
+	}
+	maxKeys := maxWitnessMultisigKeys
+	switch script {
+	case P2SH:
+		maxKeys = maxP2SHMultisigKeys
+	case P2WSH, P2SHP2WSH:
+		if !altcoin.SupportsSegwit() {
+			return nil, errors.E("segwit multisig is not supported for the coin", errors.Params{"coin": coin, "script": script})
+		}
+	default:
+		return nil, errors.E("invalid multisig script", errors.Params{"script": script})
+	}
+	if required < 1 || required > len(xpubs) || len(xpubs) > maxKeys {
+		return nil, errors.E("invalid multisig M-of-N", errors.Params{"m": required, "n": len(xpubs), "max": maxKeys})
+	}
+
+	cosigners := make([]*bip32.Key, 0, len(xpubs))
+	seen := make(map[string]bool)
+	for i, xpub := range xpubs {
+		key, err := bip32.B58Deserialize(xpub)
+		if err != nil {
+			return nil, errors.E(err, "invalid cosigner extended key", errors.Params{"cosigner": i})
+		}
+		key = key.PublicKey()
+		if seen[key.String()] {
+			return nil, errors.E("duplicated cosigner extended key", errors.Params{"cosigner": i})
+		}
+		seen[key.String()] = true
+		cosigners = append(cosigners, key)
+	}
+	return &MultisigPool{coin: coin, script: script, required: required, cosigners: cosigners}, nil
+}
+
+// GenerateAddressPool generates the multisig address pool based in the index and length
+// It returns the generated addresses and an error if occurs
+func (p *MultisigPool) GenerateAddressPool(start, length int) (MultisigAddresses, error) {
+	return p.GenerateAddressPoolContext(context.Background(), start, length)
+}
+
+// GenerateAddressPoolContext is like GenerateAddressPool, but the context is checked
+// between indexes to stop the generation of large pools.
+// It returns the generated addresses, or the addresses generated before the cancellation
+// with the context error, and an error if occurs
+func (p *MultisigPool) GenerateAddressPoolContext(ctx context.Context, start, length int) (MultisigAddresses, error) {
+	if start < 0 || length < 0 || int64(start)+int64(length) > int64(bip32.FirstHardenedChild) {
+		return nil, errors.E("index out of range", errors.Params{"start": start, "length": length})
+	}
+	addresses := make(MultisigAddresses, 0, length)
+	for i := start; i < start+length; i++ {
+		if err := ctx.Err(); err != nil {
+			return addresses, err
+		}
+		pubKeys := make([][]byte, 0, len(p.cosigners))
+		for j, cosigner := range p.cosigners {
+			key, err := cosigner.DerivePath(bip32.DerivationPath{0, uint32(i)})
+			if err != nil {
+				return nil, errors.E(err, "error to derive the cosigner key", errors.Params{"cosigner": j, "index": i})
+			}
+			pubKeys = append(pubKeys, key.Key)
+		}
+		address, err := newMultisigAddress(bip44.CoinList[p.coin], p.script, p.required, pubKeys, i)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, nil
+}
+
+// newMultisigAddress creates the multisig address of the public keys, sorted by BIP67
+// It returns the multisig address and an error if occurs
+func newMultisigAddress(coin *bip44.Altcoin, script MultisigScript, required int, pubKeys [][]byte, index int) (MultisigAddress, error) {
+	net := coin.Params()
+	sorted := make([][]byte, len(pubKeys))
+	copy(sorted, pubKeys)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i], sorted[j]) < 0
+	})
+
+	result := MultisigAddress{Index: index, PubKeys: make([]string, 0, len(sorted))}
+	keys := make([]*btcutil.AddressPubKey, 0, len(sorted))
+	for _, pubKey := range sorted {
+		key, err := btcutil.NewAddressPubKey(pubKey, net)
+		if err != nil {
+			return MultisigAddress{}, errors.E(err, "invalid cosigner public key", errors.Params{"index": index})
+		}
+		keys = append(keys, key)
+		result.PubKeys = append(result.PubKeys, hex.EncodeToString(pubKey))
+	}
+	multisig, err := txscript.MultiSigScript(keys, required)
+	if err != nil {
+		return MultisigAddress{}, errors.E(err, "error to create the multisig script", errors.Params{"index": index})
+	}
+
+	var address btcutil.Address
+	switch script {
+	case P2SH:
+		result.RedeemScript = hex.EncodeToString(multisig)
+		address, err = btcutil.NewAddressScriptHash(multisig, net)
+	case P2WSH:
+		result.WitnessScript = hex.EncodeToString(multisig)
+		scriptHash := sha256.Sum256(multisig)
+		address, err = btcutil.NewAddressWitnessScriptHash(scriptHash[:], net)
+	case P2SHP2WSH:
+		result.WitnessScript = hex.EncodeToString(multisig)
+		scriptHash := sha256.Sum256(multisig)
+		var redeemScript []byte
+		redeemScript, err = txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(scriptHash[:]).Script()
+		if err != nil {
+			return MultisigAddress{}, errors.E(err, "error to create the redeem script", errors.Params{"index": index})
+		}
+		result.RedeemScript = hex.EncodeToString(redeemScript)
+		address, err = btcutil.NewAddressScriptHash(redeemScript, net)
+	default:
+		return MultisigAddress{}, errors.E("invalid multisig script", errors.Params{"script": script})
+	}
+	if err != nil {
+		return MultisigAddress{}, errors.E(err, "error to create the multisig address", errors.Params{"index": index})
+	}
+	result.Address = address.EncodeAddress()
+	return result, nil
+}
@@ -0,0 +1,114 @@
+package pool_party
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/btcsuite/btcutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_newMultisigAddress(t *testing.T) {
+	// BIP67 test vector 1
+	pubKeys := make([][]byte, 2)
+	pubKeys[0], _ = hex.DecodeString("02ff12471208c14bd580709cb2358d98975247d8765f92bc25eab3b2763ed605f8")
+	pubKeys[1], _ = hex.DecodeString("02fe6f0a5a297eb38c391581c4413e084773ea23954d93f7753db7dc0adc188b2f")
+	multisig := "522102fe6f0a5a297eb38c391581c4413e084773ea23954d93f7753db7dc0adc188b2f2102ff12471208c14bd580709cb2358d98975247d8765f92bc25eab3b2763ed605f852ae"
+	coin := bip44.CoinList[bip44.Bitcoin]
+
+	got, err := newMultisigAddress(coin, P2SH, 2, pubKeys, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "39bgKC7RFbpoCRbtD5KEdkYKtNyhpsNa3Z", got.Address)
+	assert.Equal(t, multisig, got.RedeemScript)
+	assert.Empty(t, got.WitnessScript)
+	assert.Equal(t, []string{hex.EncodeToString(pubKeys[1]), hex.EncodeToString(pubKeys[0])}, got.PubKeys)
+
+	got, err = newMultisigAddress(coin, P2SHP2WSH, 2, pubKeys, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "3BBLivaThSP3C31jzmQJiMWBM7BLndaWfh", got.Address)
+	assert.Equal(t, multisig, got.WitnessScript)
+	assert.Equal(t, "0020b4dcb2eee00b7d71c86c08054f0a40b28e6f85572bd79d314accdfb8f30b9f77", got.RedeemScript)
+
+	got, err = newMultisigAddress(coin, P2WSH, 2, pubKeys, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, multisig, got.WitnessScript)
+	assert.Empty(t, got.RedeemScript)
+	address, err := btcutil.DecodeAddress(got.Address, coin.Params())
+	assert.NoError(t, err)
+	script, _ := hex.DecodeString(multisig)
+	scriptHash := sha256.Sum256(script)
+	assert.Equal(t, scriptHash[:], address.ScriptAddress())
+}
+
+func TestMultisigPool_GenerateAddressPool(t *testing.T) {
+	mnemonics := []string{
+		testMnemonic,
+		abandonMnemonic,
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+	}
+	xpubs := make([]string, 0, len(mnemonics))
+	cosigners := make([]bip44.Addresses, 0, len(mnemonics))
+	for _, mnemonic := range mnemonics {
+		account, err := bip44.GenerateWallets(bip44.Bitcoin, mnemonic, "", 0, 3)
+		assert.NoError(t, err)
+		xpubs = append(xpubs, account.Key.PublicKey().String())
+		cosigners = append(cosigners, account.Addresses)
+	}
+
+	for _, script := range []MultisigScript{P2SH, P2WSH, P2SHP2WSH} {
+		t.Run(string(script), func(t *testing.T) {
+			pool, err := NewMultisigPool(bip44.Bitcoin, script, 2, xpubs)
+			assert.NoError(t, err)
+			addresses, err := pool.GenerateAddressPool(1, 2)
+			assert.NoError(t, err)
+			assert.Len(t, addresses, 2)
+			for _, addr := range addresses {
+				// every cosigner key at the same index
+				for _, cosigner := range cosigners {
+					assert.Contains(t, addr.PubKeys, cosigner[addr.Index].Pubkey)
+				}
+				assert.True(t, addr.PubKeys[0] < addr.PubKeys[1] && addr.PubKeys[1] < addr.PubKeys[2])
+			}
+
+			// the cosigners order doesn't change the addresses
+			reversed, err := NewMultisigPool(bip44.Bitcoin, script, 2, []string{xpubs[2], xpubs[1], xpubs[0]})
+			assert.NoError(t, err)
+			got, err := reversed.GenerateAddressPool(1, 2)
+			assert.NoError(t, err)
+			assert.Equal(t, addresses, got)
+		})
+	}
+}
+
+func TestNewMultisigPoolInvalid(t *testing.T) {
+	account, err := bip44.GenerateWallets(bip44.Bitcoin, testMnemonic, "", 0, 1)
+	assert.NoError(t, err)
+	xpub := account.Key.PublicKey().String()
+	other, err := bip44.GenerateWallets(bip44.Bitcoin, abandonMnemonic, "", 0, 1)
+	assert.NoError(t, err)
+	xpubs := []string{xpub, other.Key.PublicKey().String()}
+
+	_, err = NewMultisigPool(bip44.Bitcoin, P2SH, 3, xpubs)
+	assert.Error(t, err)
+	_, err = NewMultisigPool(bip44.Bitcoin, P2SH, 0, xpubs)
+	assert.Error(t, err)
+	_, err = NewMultisigPool(bip44.Bitcoin, P2SH, 1, []string{xpub, xpub})
+	assert.Error(t, err)
+	_, err = NewMultisigPool(bip44.Bitcoin, P2SH, 1, []string{xpub, "xpub"})
+	assert.Error(t, err)
+	_, err = NewMultisigPool(bip44.Bitcoin, "p2tr", 1, xpubs)
+	assert.Error(t, err)
+	_, err = NewMultisigPool(bip44.Dogecoin, P2WSH, 1, xpubs)
+	assert.Error(t, err)
+	_, err = NewMultisigPool(bip44.Ethereum, P2SH, 1, xpubs)
+	assert.Error(t, err)
+
+	// the private extended keys are converted to public keys
+	pool, err := NewMultisigPool(bip44.Dogecoin, P2SH, 1, []string{account.Key.String(), xpubs[1]})
+	assert.NoError(t, err)
+	addresses, err := pool.GenerateAddressPool(0, 1)
+	assert.NoError(t, err)
+	assert.Contains(t, "9A", addresses[0].Address[:1])
+}
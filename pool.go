@@ -1,15 +1,30 @@
 package pool_party
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+
 	"github.com/Pantani/errors"
+	"github.com/Pantani/pool-party/bip32"
 	"github.com/Pantani/pool-party/bip39"
 	"github.com/Pantani/pool-party/bip44"
+	"github.com/Pantani/pool-party/descriptor"
 )
 
 type Pool struct {
-	coin       bip44.Coin
-	mnemonic   string
-	passphrase string
+	coin        bip44.Coin
+	mnemonic    []byte
+	passphrase  []byte
+	seedBytes   []byte                 // pools created from a raw bip39 seed
+	extendedKey *bip32.Key             // pools created from a master or account xprv
+	descriptor  *descriptor.Descriptor // watch-only pools
+	entropy     io.Reader              // entropy source of the generated mnemonics, crypto/rand if nil
+	template    bip44.PathTemplate     // path template of the address pool, bip44.DefaultPathTemplate if empty
+	segwit      bool                   // the address pool has native segwit (P2WPKH) addresses
+	nested      bool                   // the address pool has nested segwit (P2SH-P2WPKH) addresses
+	legacy      bool                   // the keys are derived like hdkeychain of btcutil v1.0.2 (eg LND wallets)
 }
 
 func NewPool(coin bip44.Coin) *Pool {
@@ -19,37 +34,473 @@ func NewPool(coin bip44.Coin) *Pool {
 }
 
 func NewPoolWithSecret(coin bip44.Coin, mnemonic, passphrase string) *Pool {
+	return &Pool{
+		coin:       coin,
+		mnemonic:   []byte(mnemonic),
+		passphrase: []byte(passphrase),
+	}
+}
+
+// NewPoolWithSecretBytes creates the pool with a copy of the mnemonic and passphrase,
+// so the caller can zero its buffers
+func NewPoolWithSecretBytes(coin bip44.Coin, mnemonic, passphrase []byte) *Pool {
+	return &Pool{
+		coin:       coin,
+		mnemonic:   append([]byte{}, mnemonic...),
+		passphrase: append([]byte{}, passphrase...),
+	}
+}
+
+// NewPoolWithSeed creates the pool with a copy of the raw bip39 seed (16 to 64 bytes),
+// for systems storing the seed instead of the mnemonic
+// It returns the pool and an error if occurs
+func NewPoolWithSeed(coin bip44.Coin, seed []byte) (*Pool, error) {
+	if _, ok := bip44.CoinList[coin]; !ok {
+		return nil, errors.E("Invalid coin", errors.Params{"coin": coin})
+	}
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, errors.E("invalid seed length", errors.Params{"length": len(seed)})
+	}
+	return &Pool{
+		coin:      coin,
+		seedBytes: append([]byte{}, seed...),
+	}, nil
+}
+
+// NewPoolWithEntropy creates the pool with the mnemonic of the hex encoded bip39 entropy
+// It returns the pool and an error if occurs
+func NewPoolWithEntropy(coin bip44.Coin, entropyHex, passphrase string) (*Pool, error) {
+	if _, ok := bip44.CoinList[coin]; !ok {
+		return nil, errors.E("Invalid coin", errors.Params{"coin": coin})
+	}
+	entropy, err := hex.DecodeString(entropyHex)
+	if err != nil {
+		return nil, errors.E(err, "invalid entropy hex")
+	}
+	defer bip39.Zero(entropy)
+	mnemonic, err := bip39.NewMnemonicBytes(entropy)
+	if err != nil {
+		return nil, errors.E(err, "invalid entropy", errors.Params{"bits": len(entropy) * 8})
+	}
 	return &Pool{
 		coin:       coin,
 		mnemonic:   mnemonic,
-		passphrase: passphrase,
+		passphrase: []byte(passphrase),
+	}, nil
+}
+
+// NewPoolWithExtendedKey creates the pool with a serialized master or account extended private key.
+// An account key replaces the derivation path components up to its depth, like m/44'/cointype'/0'
+// for the BIP44 addresses, and is the root of the address key origins.
+// It returns the pool and an error if occurs
+func NewPoolWithExtendedKey(coin bip44.Coin, xprv string) (*Pool, error) {
+	if _, ok := bip44.CoinList[coin]; !ok {
+		return nil, errors.E("Invalid coin", errors.Params{"coin": coin})
 	}
+	key, err := bip32.B58Deserialize(xprv)
+	if err != nil {
+		return nil, errors.E(err, "invalid extended key")
+	}
+	if !key.IsPrivate {
+		return nil, errors.E("extended key is not private, use a watch-only pool")
+	}
+	return &Pool{
+		coin:        coin,
+		extendedKey: key,
+	}, nil
 }
 
 // GenerateMnemonic generates a new mnemonic key based in the bit size
 // It returns an error if occurs
 func (p *Pool) GenerateMnemonic(bitSize int, passphrase string) error {
-	// NewEntropy will create random entropy bytes
-	// Return non-zero first byte, unless all random zeros occurs.
-	entropy, err := bip39.NewEntropy(bitSize)
+	return p.GenerateMnemonicContext(context.Background(), bitSize, passphrase)
+}
+
+// GenerateMnemonicContext is like GenerateMnemonic, but the pool secrets are kept
+// if the context is done before the mnemonic is generated
+// It returns the context error if it's done or an error if occurs
+func (p *Pool) GenerateMnemonicContext(ctx context.Context, bitSize int, passphrase string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// NewEntropyFromReader will read the random entropy bytes from the pool entropy source
+	entropy, err := bip39.NewEntropyFromReader(p.entropySource(), bitSize)
 	if err != nil {
 		return err
 	}
+	return p.setMnemonic(ctx, entropy, passphrase)
+}
+
+// GenerateMnemonicWithUserEntropy generates a new mnemonic key based in the bit size, mixing the
+// entropy source randomness with the user entropy (eg bip39.DiceEntropy or bip39.CardEntropy),
+// XORing or hashing them by the mix mode
+// It returns the bits of entropy contributed by the user and an error if occurs
+func (p *Pool) GenerateMnemonicWithUserEntropy(bitSize int, passphrase string, user *bip39.UserEntropy, mode bip39.MixMode) (int, error) {
+	entropy, bits, err := bip39.MixEntropy(p.entropySource(), bitSize, user, mode)
+	if err != nil {
+		return 0, err
+	}
+	if err := p.setMnemonic(context.Background(), entropy, passphrase); err != nil {
+		return 0, err
+	}
+	return bits, nil
+}
+
+// SetEntropySource sets the entropy source of the generated mnemonics, like a hardware RNG,
+// or crypto/rand if nil
+func (p *Pool) SetEntropySource(r io.Reader) {
+	p.entropy = r
+}
+
+// entropySource returns the pool entropy source or crypto/rand
+func (p *Pool) entropySource() io.Reader {
+	if p.entropy == nil {
+		return rand.Reader
+	}
+	return p.entropy
+}
+
+// setMnemonic replaces the pool secrets by the mnemonic of the entropy, zeroing the entropy
+// It returns the context error if it's done or an error if occurs
+func (p *Pool) setMnemonic(ctx context.Context, entropy []byte, passphrase string) error {
+	defer bip39.Zero(entropy)
+
 	// generate (english) seed words based on the entropy
-	p.mnemonic, err = bip39.NewMnemonic(entropy)
-	p.passphrase = passphrase
-	return err
+	mnemonic, err := bip39.NewMnemonicBytes(entropy)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		bip39.Zero(mnemonic)
+		return err
+	}
+	p.Wipe()
+	p.mnemonic = mnemonic
+	p.passphrase = []byte(passphrase)
+	return nil
 }
 
 // GenerateAddressPool generates the address pool based in the index and length
 // It returns the generated addresses and an error if occurs
 func (p *Pool) GenerateAddressPool(start, length int) (bip44.Addresses, error) {
+	return p.GenerateAddressPoolContext(context.Background(), start, length)
+}
+
+// GenerateAddressPoolContext is like GenerateAddressPool, but the context is checked
+// between indexes to stop the generation of large pools.
+// It returns the generated addresses, or the addresses generated before the cancellation
+// with the context error, and an error if occurs
+func (p *Pool) GenerateAddressPoolContext(ctx context.Context, start, length int) (bip44.Addresses, error) {
+	if p.descriptor != nil {
+		addresses, err := p.descriptor.AddressesContext(ctx, bip44.CoinList[p.coin], start, length)
+		if err != nil {
+			if err == ctx.Err() {
+				return addresses, err
+			}
+			return nil, errors.E(err, "error to derive descriptor addresses", errors.Params{"coin": p.coin, "start": start, "length": length})
+		}
+		return addresses, nil
+	}
+	addresses, err := p.generateWallets(ctx, p.pathTemplate(), 0, start, length)
+	if err != nil && err != ctx.Err() {
+		return nil, errors.E(err, "error to generate bip44 wallets", errors.Params{"coin": p.coin, "start": start, "length": length})
+	}
+	encoded, eerr := p.encodeAddresses(p.coin, addresses)
+	if eerr != nil {
+		return nil, eerr
+	}
+	return encoded, err
+}
+
+// GenerateAddressPoolWithPath generates the address pool based in the index and length,
+// derived by the path template (eg m/44'/60'/{account}'/0/{index})
+// It returns the generated addresses and an error if occurs
+func (p *Pool) GenerateAddressPoolWithPath(template string, account, start, length int) (bip44.Addresses, error) {
+	return p.GenerateAddressPoolWithPathContext(context.Background(), template, account, start, length)
+}
+
+// GenerateAddressPoolWithPathContext is like GenerateAddressPoolWithPath, but the context
+// is checked between indexes.
+// It returns the generated addresses, or the addresses generated before the cancellation
+// with the context error, and an error if occurs
+func (p *Pool) GenerateAddressPoolWithPathContext(ctx context.Context, template string, account, start, length int) (bip44.Addresses, error) {
+	addresses, err := p.generateWallets(ctx, bip44.PathTemplate(template), account, start, length)
+	if err != nil {
+		if err == ctx.Err() {
+			return addresses, err
+		}
+		return nil, errors.E(err, "error to generate wallets", errors.Params{"coin": p.coin, "template": template, "account": account, "start": start, "length": length})
+	}
+	return addresses, nil
+}
+
+// GenerateAddressPoolWithScheme generates the address pool based in the index and length,
+// derived by the path of a wallet software (eg bip44.MetaMask or bip44.LedgerLive)
+// It returns the generated addresses and an error if occurs
+func (p *Pool) GenerateAddressPoolWithScheme(scheme bip44.Scheme, start, length int) (bip44.Addresses, error) {
+	return p.GenerateAddressPoolWithSchemeContext(context.Background(), scheme, start, length)
+}
+
+// GenerateAddressPoolWithSchemeContext is like GenerateAddressPoolWithScheme, but the context
+// is checked between indexes.
+// It returns the generated addresses, or the addresses generated before the cancellation
+// with the context error, and an error if occurs
+func (p *Pool) GenerateAddressPoolWithSchemeContext(ctx context.Context, scheme bip44.Scheme, start, length int) (bip44.Addresses, error) {
+	template, err := scheme.Template(p.coin)
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := p.generateWallets(ctx, template, 0, start, length)
+	if err != nil {
+		if err == ctx.Err() {
+			return addresses, err
+		}
+		return nil, errors.E(err, "error to generate wallets", errors.Params{"coin": p.coin, "scheme": scheme, "start": start, "length": length})
+	}
+	return addresses, nil
+}
+
+// GenerateMultiCoinAddressPool generates the BIP44 address pool of each coin from the pool
+// secrets, computing the bip39 seed once. If no coin is passed every supported coin is derived.
+// Pools with a path template (eg Electrum seeds) derive the template addresses of each coin,
+// and segwit pools only support the segwit coins, the default coins are the segwit ones.
+// It returns the generated addresses by coin and an error if occurs
+func (p *Pool) GenerateMultiCoinAddressPool(coins []bip44.Coin, start, length int) (map[bip44.Coin]bip44.Addresses, error) {
+	if p.descriptor != nil {
+		return nil, errors.E("watch-only pool has no private keys", errors.Params{"coin": p.coin})
+	}
+	if len(coins) == 0 {
+		for _, coin := range bip44.Coins() {
+			if !p.isSegwit() || bip44.CoinList[coin].SupportsSegwit() {
+				coins = append(coins, coin)
+			}
+		}
+	}
+	if p.isSegwit() {
+		for _, coin := range coins {
+			if altcoin, ok := bip44.CoinList[coin]; ok && !altcoin.SupportsSegwit() {
+				return nil, errors.E("segwit addresses are not supported for the coin", errors.Params{"coin": coin})
+			}
+		}
+	}
+	master, err := p.masterKey()
+	if err != nil {
+		return nil, err
+	}
+	defer master.Wipe()
+	result := make(map[bip44.Coin]bip44.Addresses, len(coins))
+	for _, coin := range coins {
+		account, err := p.generateWalletsFromKey(context.Background(), coin, master, p.pathTemplate(), 0, start, length)
+		if err != nil {
+			return nil, errors.E(err, "error to generate bip44 wallets", errors.Params{"coin": coin, "start": start, "length": length})
+		}
+		addresses := account.Addresses
+		account.Addresses = nil
+		account.Wipe()
+		if addresses, err = p.encodeAddresses(coin, addresses); err != nil {
+			return nil, err
+		}
+		result[coin] = addresses
+	}
+	return result, nil
+}
+
+// GenerateLegacyAddressPool generates the address pool based in the index and length, deriving the
+// keys like hdkeychain of btcutil v1.0.2, used by pool-party before the bip32 package (see
+// bip44.GenerateLegacyWalletsFromKeyContext). It's only meant to recover the addresses issued by it.
+// It returns the generated addresses and an error if occurs
+func (p *Pool) GenerateLegacyAddressPool(start, length int) (bip44.Addresses, error) {
+	if p.descriptor != nil {
+		return nil, errors.E("watch-only pool has no private keys", errors.Params{"coin": p.coin})
+	}
+	master, err := p.masterKey()
+	if err != nil {
+		return nil, err
+	}
+	defer master.Wipe()
+	account, err := bip44.GenerateLegacyWalletsFromKeyContext(context.Background(), p.coin, master, p.pathTemplate(), 0, start, length)
+	if err != nil {
+		return nil, errors.E(err, "error to generate legacy bip44 wallets", errors.Params{"coin": p.coin, "start": start, "length": length})
+	}
+	addresses := account.Addresses
+	account.Addresses = nil
+	account.Wipe()
+	return p.encodeAddresses(p.coin, addresses)
+}
+
+// HasLegacyAddresses checks if the pool addresses from start to start+length differ from the
+// addresses issued by pool-party before the bip32 package (see GenerateLegacyAddressPool)
+// It returns true if the addresses differ and an error if occurs
+func (p *Pool) HasLegacyAddresses(start, length int) (bool, error) {
+	if p.descriptor != nil || p.legacy {
+		return false, nil
+	}
+	master, err := p.masterKey()
+	if err != nil {
+		return false, err
+	}
+	defer master.Wipe()
+	return bip44.HasLegacyDerivation(p.coin, master, p.pathTemplate(), 0, start, length)
+}
+
+// Wipe zeroes the pool mnemonic, passphrase, seed and extended key, the pool can't generate addresses after
+func (p *Pool) Wipe() {
+	bip39.Zero(p.mnemonic)
+	bip39.Zero(p.passphrase)
+	bip39.Zero(p.seedBytes)
+	if p.extendedKey != nil {
+		p.extendedKey.Wipe()
+	}
+	p.mnemonic = nil
+	p.passphrase = nil
+	p.seedBytes = nil
+	p.extendedKey = nil
+}
+
+// Close wipes the pool secrets, implementing io.Closer
+// It returns an error if occurs
+func (p *Pool) Close() error {
+	p.Wipe()
+	return nil
+}
+
+// hasSecret returns true if the pool has a mnemonic, seed or extended private key
+func (p *Pool) hasSecret() bool {
+	return len(p.mnemonic) > 0 || len(p.seedBytes) > 0 || p.extendedKey != nil
+}
+
+// seed creates the bip39 seed from the pool mnemonic and passphrase, or copies the
+// raw seed, the caller must zero it
+// It returns the seed and an error if occurs
+func (p *Pool) seed() ([]byte, error) {
+	if len(p.seedBytes) > 0 {
+		return append([]byte{}, p.seedBytes...), nil
+	}
 	if len(p.mnemonic) == 0 {
 		return nil, errors.E("empty mnemonic")
 	}
-	account, err := bip44.GenerateWallets(p.coin, p.mnemonic, p.passphrase, start, length)
+	return bip39.NewSeedBytes(p.mnemonic, p.passphrase)
+}
+
+// masterKey creates the bip32 root key of the pool, or copies the pool extended key
+// (which may be an account key), the caller must wipe it
+// It returns the master key and an error if occurs
+func (p *Pool) masterKey() (*bip32.Key, error) {
+	if p.extendedKey != nil {
+		return p.extendedKey.Copy(), nil
+	}
+	seed, err := p.seed()
 	if err != nil {
-		return nil, errors.E(err, "error to generate bip44 wallets", errors.Params{"coin": p.coin, "start": start, "length": length})
+		return nil, err
+	}
+	defer bip39.Zero(seed)
+	return bip44.NewMasterKeyFromSeed(seed)
+}
+
+// pathTemplate returns the path template of the address pool
+func (p *Pool) pathTemplate() bip44.PathTemplate {
+	if p.template == "" {
+		return bip44.DefaultPathTemplate
+	}
+	return p.template
+}
+
+// generateWalletsFromKey derives the account of the coin from the master key, by the legacy
+// derivation for the legacy pools
+// It returns the account and an error if occurs
+func (p *Pool) generateWalletsFromKey(ctx context.Context, coin bip44.Coin, master *bip32.Key, template bip44.PathTemplate, account, start, length int) (*bip44.Account, error) {
+	if p.legacy {
+		return bip44.GenerateLegacyWalletsFromKeyContext(ctx, coin, master, template, account, start, length)
+	}
+	return bip44.GenerateWalletsFromKeyContext(ctx, coin, master, template, account, start, length)
+}
+
+// derivePath derives the descendant key of the path, by the legacy derivation for the legacy pools
+// It returns the derived key and an error if occurs
+func (p *Pool) derivePath(key *bip32.Key, path bip32.DerivationPath) (*bip32.Key, error) {
+	if p.legacy {
+		return key.DeriveLegacyPath(path)
+	}
+	return key.DerivePath(path)
+}
+
+// isSegwit returns true if the pool addresses are native or nested segwit addresses
+func (p *Pool) isSegwit() bool {
+	return p.segwit || p.nested
+}
+
+// encodeAddresses converts the addresses to the native or nested segwit addresses of the
+// segwit pools
+// It returns the addresses and an error if the coin doesn't support segwit
+func (p *Pool) encodeAddresses(coin bip44.Coin, addresses bip44.Addresses) (bip44.Addresses, error) {
+	switch {
+	case p.segwit:
+		return addresses.Segwit(coin)
+	case p.nested:
+		return addresses.NestedSegwit(coin)
+	}
+	return addresses, nil
+}
+
+// generateWallets generates the addresses derived by the path template, wiping
+// the master key and the account keys after
+// It returns the generated addresses, the partial addresses if the context is done, and an error if occurs
+func (p *Pool) generateWallets(ctx context.Context, template bip44.PathTemplate, account, start, length int) (bip44.Addresses, error) {
+	master, err := p.masterKey()
+	if err != nil {
+		return nil, err
+	}
+	defer master.Wipe()
+	result, err := p.generateWalletsFromKey(ctx, p.coin, master, template, account, start, length)
+	if result == nil {
+		return nil, err
+	}
+	addresses := result.Addresses
+	result.Addresses = nil
+	result.Wipe()
+	return addresses, err
+}
+
+// address generates the address of the index, the caller must zero its private key
+// It returns the generated address and an error if occurs
+func (p *Pool) address(index int) (*bip44.Address, error) {
+	if p.descriptor != nil {
+		return nil, errors.E("watch-only pool has no private keys", errors.Params{"coin": p.coin})
+	}
+	addresses, err := p.GenerateAddressPool(index, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(addresses) != 1 {
+		return nil, errors.E("error to generate the address", errors.Params{"coin": p.coin, "index": index})
+	}
+	return &addresses[0], nil
+}
+
+// addressWithPath generates the address of the index derived by the path template,
+// like the addresses of GenerateAddressPoolWithPath, the caller must zero its private key
+// It returns the generated address and an error if occurs
+func (p *Pool) addressWithPath(template bip44.PathTemplate, account, index int) (*bip44.Address, error) {
+	if p.descriptor != nil {
+		return nil, errors.E("watch-only pool has no private keys", errors.Params{"coin": p.coin})
+	}
+	addresses, err := p.generateWallets(context.Background(), template, account, index, 1)
+	if err != nil {
+		return nil, errors.E(err, "error to generate wallets", errors.Params{"coin": p.coin, "template": template, "account": account, "index": index})
+	}
+	if len(addresses) != 1 {
+		return nil, errors.E("error to generate the address", errors.Params{"coin": p.coin, "template": template, "index": index})
+	}
+	return &addresses[0], nil
+}
+
+// addressWithScheme generates the address of the index derived by the wallet derivation
+// scheme, like the addresses of GenerateAddressPoolWithScheme, the caller must zero its private key
+// It returns the generated address and an error if occurs
+func (p *Pool) addressWithScheme(scheme bip44.Scheme, index int) (*bip44.Address, error) {
+	template, err := scheme.Template(p.coin)
+	if err != nil {
+		return nil, err
 	}
-	return account.Addresses, nil
+	return p.addressWithPath(template, 0, index)
 }
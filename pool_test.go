@@ -0,0 +1,280 @@
+package pool_party
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Pantani/pool-party/bip32"
+	"github.com/Pantani/pool-party/bip39"
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/Pantani/pool-party/descriptor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool_GenerateAddressPoolWithPath(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Ethereum, testMnemonic, testPassphrase)
+	want, err := pool.GenerateAddressPool(5, 3)
+	assert.NoError(t, err)
+
+	got, err := pool.GenerateAddressPoolWithPath(string(bip44.DefaultPathTemplate), 0, 5, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	got, err = pool.GenerateAddressPoolWithPath("m/44'/60'/{account}'/0/{index}", 1, 5, 3)
+	assert.NoError(t, err)
+	assert.Len(t, got, 3)
+	assert.NotEqual(t, want[0].Address, got[0].Address)
+
+	_, err = pool.GenerateAddressPoolWithPath("m/44'/60'/0'/0", 0, 0, 1)
+	assert.Error(t, err)
+
+	_, err = NewPool(bip44.Ethereum).GenerateAddressPoolWithPath(string(bip44.DefaultPathTemplate), 0, 0, 1)
+	assert.Error(t, err)
+}
+
+func TestPool_GenerateAddressPoolWithScheme(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Energi, testMnemonic, testPassphrase)
+	got, err := pool.GenerateAddressPoolWithScheme(bip44.LedgerLive, 0, 2)
+	assert.NoError(t, err)
+	want, err := pool.GenerateAddressPoolWithPath("m/44'/60'/{index}'/0/0", 0, 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	_, err = NewPoolWithSecret(bip44.Dash, testMnemonic, "").GenerateAddressPoolWithScheme(bip44.LegacyMEW, 0, 1)
+	assert.Error(t, err)
+}
+
+func TestPool_Close(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Bitcoin, testMnemonic, testPassphrase)
+	mnemonic, passphrase := pool.mnemonic, pool.passphrase
+	_, err := pool.GenerateAddressPool(0, 1)
+	assert.NoError(t, err)
+
+	assert.NoError(t, pool.Close())
+	assert.Equal(t, make([]byte, len(testMnemonic)), mnemonic)
+	assert.Equal(t, make([]byte, len(testPassphrase)), passphrase)
+	_, err = pool.GenerateAddressPool(0, 1)
+	assert.Error(t, err)
+	_, err = pool.Encrypt("password", LightScryptN, LightScryptP)
+	assert.Error(t, err)
+}
+
+func TestPool_GenerateMnemonicWipe(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Bitcoin, testMnemonic, testPassphrase)
+	previous := pool.mnemonic
+	assert.NoError(t, pool.GenerateMnemonic(256, ""))
+	assert.Equal(t, make([]byte, len(testMnemonic)), previous)
+	assert.Len(t, strings.Fields(string(pool.mnemonic)), 24)
+
+	_, err := pool.GenerateAddressPool(0, 1)
+	assert.NoError(t, err)
+	pool.Wipe()
+	assert.Nil(t, pool.mnemonic)
+}
+
+func TestNewPoolWithSecretBytes(t *testing.T) {
+	mnemonic, passphrase := []byte(testMnemonic), []byte(testPassphrase)
+	pool := NewPoolWithSecretBytes(bip44.Ethereum, mnemonic, passphrase)
+
+	// the caller buffers are not retained by the pool
+	bip39.Zero(mnemonic)
+	bip39.Zero(passphrase)
+	got, err := pool.GenerateAddressPool(0, 2)
+	assert.NoError(t, err)
+	want, err := NewPoolWithSecret(bip44.Ethereum, testMnemonic, testPassphrase).GenerateAddressPool(0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestPool_GenerateMultiCoinAddressPool(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Bitcoin, testMnemonic, testPassphrase)
+	got, err := pool.GenerateMultiCoinAddressPool([]bip44.Coin{bip44.Ethereum, bip44.Litecoin}, 5, 2)
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+	for _, coin := range []bip44.Coin{bip44.Ethereum, bip44.Litecoin} {
+		want, err := NewPoolWithSecret(coin, testMnemonic, testPassphrase).GenerateAddressPool(5, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got[coin])
+	}
+
+	all, err := pool.GenerateMultiCoinAddressPool(nil, 0, 1)
+	assert.NoError(t, err)
+	assert.Len(t, all, len(bip44.CoinList))
+
+	_, err = pool.GenerateMultiCoinAddressPool([]bip44.Coin{"Monero"}, 0, 1)
+	assert.Error(t, err)
+	_, err = NewPool(bip44.Bitcoin).GenerateMultiCoinAddressPool(nil, 0, 1)
+	assert.Error(t, err)
+}
+
+func TestPool_GenerateMultiCoinAddressPoolElectrum(t *testing.T) {
+	for _, mnemonic := range []string{
+		"cycle rocket west magnet parrot shuffle foot correct salt library feed song",
+		"bitter grass shiver impose acquire brush forget axis eager alone wine silver",
+	} {
+		pool, err := NewPoolWithElectrumSeed(bip44.Bitcoin, mnemonic, "")
+		assert.NoError(t, err)
+		want, err := pool.GenerateAddressPool(2, 3)
+		assert.NoError(t, err)
+		got, err := pool.GenerateMultiCoinAddressPool([]bip44.Coin{bip44.Bitcoin}, 2, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got[bip44.Bitcoin])
+	}
+
+	// segwit pools only derive the segwit coins
+	pool, err := NewPoolWithElectrumSeed(bip44.Bitcoin, "bitter grass shiver impose acquire brush forget axis eager alone wine silver", "")
+	assert.NoError(t, err)
+	all, err := pool.GenerateMultiCoinAddressPool(nil, 0, 1)
+	assert.NoError(t, err)
+	for coin := range all {
+		assert.True(t, bip44.CoinList[coin].SupportsSegwit())
+	}
+	assert.Contains(t, all, bip44.Litecoin)
+	_, err = pool.GenerateMultiCoinAddressPool([]bip44.Coin{bip44.Dogecoin}, 0, 1)
+	assert.Error(t, err)
+}
+
+func TestPool_GenerateLegacyAddressPool(t *testing.T) {
+	// the m/44'/0' private key of the mnemonic has a leading zero byte
+	pool := NewPoolWithSecret(bip44.Bitcoin, "course join coast burst come actor quantum arctic crystal famous ethics walnut", "")
+	legacy, err := pool.GenerateLegacyAddressPool(0, 3)
+	assert.NoError(t, err)
+	addresses, err := pool.GenerateAddressPool(0, 3)
+	assert.NoError(t, err)
+	assert.Len(t, legacy, 3)
+	for i := range legacy {
+		assert.NotEqual(t, addresses[i].Address, legacy[i].Address)
+	}
+	changed, err := pool.HasLegacyAddresses(0, 3)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	pool = NewPoolWithSecret(bip44.Bitcoin, testMnemonic, "")
+	legacy, err = pool.GenerateLegacyAddressPool(0, 3)
+	assert.NoError(t, err)
+	addresses, err = pool.GenerateAddressPool(0, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, addresses, legacy)
+	changed, err = pool.HasLegacyAddresses(0, 3)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	_, err = NewPool(bip44.Bitcoin).GenerateLegacyAddressPool(0, 1)
+	assert.Error(t, err)
+}
+
+func TestNewPoolWithSeed(t *testing.T) {
+	seed := bip39.NewSeed(testMnemonic, testPassphrase)
+	pool, err := NewPoolWithSeed(bip44.Litecoin, seed)
+	assert.NoError(t, err)
+	bip39.Zero(seed)
+
+	got, err := pool.GenerateAddressPool(0, 3)
+	assert.NoError(t, err)
+	want, err := NewPoolWithSecret(bip44.Litecoin, testMnemonic, testPassphrase).GenerateAddressPool(0, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	_, err = NewPoolWithSeed(bip44.Litecoin, make([]byte, 15))
+	assert.Error(t, err)
+	_, err = NewPoolWithSeed("Monero", make([]byte, 64))
+	assert.Error(t, err)
+}
+
+func TestNewPoolWithEntropy(t *testing.T) {
+	pool, err := NewPoolWithEntropy(bip44.Bitcoin, "00000000000000000000000000000000", "")
+	assert.NoError(t, err)
+	assert.Equal(t, abandonMnemonic, string(pool.mnemonic))
+
+	for _, entropy := range []string{"0000", "zz000000000000000000000000000000", "000000000000000000000000000000000"} {
+		_, err = NewPoolWithEntropy(bip44.Bitcoin, entropy, "")
+		assert.Error(t, err, entropy)
+	}
+}
+
+func TestNewPoolWithExtendedKey(t *testing.T) {
+	seedPool := NewPoolWithSecret(bip44.Bitcoin, abandonMnemonic, "")
+	master, err := seedPool.masterKey()
+	assert.NoError(t, err)
+	account, err := master.DerivePath(bip32.DerivationPath{44 + bip32.FirstHardenedChild, bip32.FirstHardenedChild, bip32.FirstHardenedChild})
+	assert.NoError(t, err)
+
+	want, err := seedPool.GenerateAddressPool(0, 3)
+	assert.NoError(t, err)
+	wantDesc, err := seedPool.Descriptor(descriptor.PKH, 0, false)
+	assert.NoError(t, err)
+
+	// master key pools work like the mnemonic pools
+	pool, err := NewPoolWithExtendedKey(bip44.Bitcoin, master.B58Serialize())
+	assert.NoError(t, err)
+	got, err := pool.GenerateAddressPool(0, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+	desc, err := pool.Descriptor(descriptor.PKH, 0, false)
+	assert.NoError(t, err)
+	assert.Equal(t, wantDesc, desc)
+
+	// account key pools derive the same addresses, the account key is the origin root
+	pool, err = NewPoolWithExtendedKey(bip44.Bitcoin, account.B58Serialize())
+	assert.NoError(t, err)
+	got, err = pool.GenerateAddressPool(0, 3)
+	assert.NoError(t, err)
+	assert.Len(t, got, 3)
+	for i := range got {
+		assert.Equal(t, want[i].Address, got[i].Address)
+		assert.Equal(t, want[i].Privkey, got[i].Privkey)
+		assert.Equal(t, "m/0/"+strconv.Itoa(i), got[i].Origin.Path.String())
+		assert.Equal(t, account.Fingerprint(), got[i].Origin.Fingerprint)
+	}
+	desc, err = pool.Descriptor(descriptor.PKH, 0, false)
+	assert.NoError(t, err)
+	wantDesc, err = descriptor.AddChecksum("pkh(" + account.PublicKey().B58Serialize() + "/0/*)")
+	assert.NoError(t, err)
+	assert.Equal(t, wantDesc, desc)
+
+	_, err = pool.Descriptor(descriptor.PKH, 1, false)
+	assert.Error(t, err)
+	_, err = pool.GenerateAddressPoolWithPath("m/44'/0'/1'/0/{index}", 0, 0, 1)
+	assert.Error(t, err)
+
+	_, err = NewPoolWithExtendedKey(bip44.Bitcoin, account.PublicKey().B58Serialize())
+	assert.Error(t, err)
+	_, err = NewPoolWithExtendedKey(bip44.Bitcoin, "xprv")
+	assert.Error(t, err)
+}
+
+func TestPool_SetEntropySource(t *testing.T) {
+	pool := NewPool(bip44.Bitcoin)
+	pool.SetEntropySource(bytes.NewReader(make([]byte, 16)))
+	assert.NoError(t, pool.GenerateMnemonic(128, ""))
+	assert.Equal(t, abandonMnemonic, string(pool.mnemonic))
+
+	// the exhausted source fails and keeps the pool secrets
+	assert.Error(t, pool.GenerateMnemonic(128, ""))
+	assert.Equal(t, abandonMnemonic, string(pool.mnemonic))
+
+	pool.SetEntropySource(nil)
+	assert.NoError(t, pool.GenerateMnemonic(128, ""))
+	assert.NotEqual(t, abandonMnemonic, string(pool.mnemonic))
+}
+
+func TestPool_GenerateMnemonicWithUserEntropy(t *testing.T) {
+	dice, err := bip39.DiceEntropy(strings.Repeat("3615242", 15), 6)
+	assert.NoError(t, err)
+
+	pool := NewPool(bip44.Bitcoin)
+	pool.SetEntropySource(bytes.NewReader(make([]byte, 32)))
+	bits, err := pool.GenerateMnemonicWithUserEntropy(256, "", dice, bip39.MixXOR)
+	assert.NoError(t, err)
+	assert.Equal(t, 256, bits) // 105 rolls of a six sided die add 271 bits, capped to the entropy size
+	digest := sha256.Sum256(dice.Data)
+	want, err := bip39.NewMnemonic(digest[:])
+	assert.NoError(t, err)
+	assert.Equal(t, want, string(pool.mnemonic))
+
+	_, err = pool.GenerateMnemonicWithUserEntropy(256, "", dice, bip39.MixHash)
+	assert.Error(t, err)
+}
@@ -0,0 +1,193 @@
+package pool_party
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+
+	"github.com/Pantani/errors"
+	"github.com/Pantani/pool-party/bip32"
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/psbt"
+)
+
+// SignPSBT signs the inputs of a base64 BIP174 PSBT owned by the pool.
+// The inputs are matched to the pool keys through the bip32_derivation fields with the
+// master fingerprint of the pool seed, the key is derived from the seed by the PSBT path.
+// Pools of an account extended key also match the derivations whose path goes through
+// the account key, as its master fingerprint is unknown. The derivations with a public
+// key that doesn't match the pool key are skipped.
+// P2PKH and P2SH inputs are supported for every UTXO coin, and P2WPKH, P2SH-P2WPKH and
+// P2WSH inputs for the coins with segwit activated.
+// It returns the updated base64 PSBT, the number of signatures added and an error if occurs
+func (p *Pool) SignPSBT(packet string) (string, int, error) {
+	coin, ok := bip44.CoinList[p.coin]
+	if !ok || coin.IsEthereum() {
+		return "", 0, errors.E("PSBT signing is only supported for UTXO coins", errors.Params{"coin": p.coin})
+	}
+	if !p.hasSecret() {
+		return "", 0, errors.E("empty mnemonic")
+	}
+	pkt, err := psbt.NewFromRawBytes(strings.NewReader(packet), true)
+	if err != nil {
+		return "", 0, errors.E(err, "invalid PSBT")
+	}
+	master, err := p.masterKey()
+	if err != nil {
+		return "", 0, err
+	}
+	defer master.Wipe()
+	fingerprint := bip44.Fingerprint(master)
+	updater, err := psbt.NewUpdater(pkt)
+	if err != nil {
+		return "", 0, errors.E(err, "invalid PSBT")
+	}
+
+	sigHashes := txscript.NewTxSigHashes(pkt.UnsignedTx)
+	signed := 0
+	for i := range pkt.Inputs {
+		for _, derivation := range pkt.Inputs[i].Bip32Derivation {
+			path, ok := psbtDerivationPath(master, fingerprint, derivation.MasterKeyFingerprint, derivation.Bip32Path)
+			if !ok {
+				continue
+			}
+			key, err := p.derivePath(master, path)
+			if err != nil {
+				return "", 0, err
+			}
+			privk, _ := btcec.PrivKeyFromBytes(btcec.S256(), key.Key)
+			key.Wipe()
+			added, err := signPSBTDerivation(coin, pkt, updater, sigHashes, i, privk, derivation.PubKey)
+			bip44.WipePrivateKey(privk)
+			if err != nil {
+				return "", 0, err
+			}
+			if added {
+				signed++
+			}
+		}
+	}
+
+	result, err := pkt.B64Encode()
+	if err != nil {
+		return "", 0, errors.E(err, "error to encode the PSBT")
+	}
+	return result, signed, nil
+}
+
+// psbtDerivationPath returns the path of the derivation relative to the pool key: the
+// whole path if the fingerprint is the pool key one, or the path after the account key
+// depth if the derivation goes through the account key
+// It returns the relative path and false if the derivation isn't from the pool key
+func psbtDerivationPath(key *bip32.Key, fingerprint, masterFingerprint uint32, path []uint32) ([]uint32, bool) {
+	if masterFingerprint == fingerprint {
+		return path, true
+	}
+	depth := int(key.Depth)
+	if depth == 0 || len(path) <= depth || path[depth-1] != binary.BigEndian.Uint32(key.ChildNumber) {
+		return nil, false
+	}
+	return path[depth:], true
+}
+
+// signPSBTDerivation signs the input with the private key of the derivation public key
+// It returns true if the signature was added, false if the public key is not the key
+// of the private key, and an error if occurs
+func signPSBTDerivation(coin *bip44.Altcoin, pkt *psbt.Packet, updater *psbt.Updater, sigHashes *txscript.TxSigHashes, i int, privk *btcec.PrivateKey, pubKey []byte) (bool, error) {
+	pubk := privk.PubKey().SerializeCompressed()
+	if !bytes.Equal(pubk, pubKey) {
+		return false, nil
+	}
+	if hasPartialSig(pkt.Inputs[i], pubk) {
+		return false, nil
+	}
+	sig, err := signPSBTInput(coin, pkt, sigHashes, i, privk)
+	if err != nil {
+		return false, err
+	}
+	outcome, err := updater.Sign(i, sig, pubk, nil, nil)
+	if err != nil {
+		return false, errors.E(err, "error to add the PSBT signature", errors.Params{"input": i})
+	}
+	return outcome == psbt.SignSuccesful, nil
+}
+
+// signPSBTInput creates the input signature (DER + sighash type) for the script type of the spent output
+func signPSBTInput(coin *bip44.Altcoin, pkt *psbt.Packet, sigHashes *txscript.TxSigHashes, i int, privk *btcec.PrivateKey) ([]byte, error) {
+	input := pkt.Inputs[i]
+	txOut, err := psbtInputUtxo(pkt, i)
+	if err != nil {
+		return nil, err
+	}
+	hashType := txscript.SigHashAll
+	if input.SighashType != 0 {
+		hashType = input.SighashType
+	}
+
+	script := txOut.PkScript
+	if txscript.IsPayToScriptHash(script) {
+		if input.RedeemScript == nil {
+			return nil, errors.E("missing redeem script for P2SH input", errors.Params{"input": i})
+		}
+		if !bytes.Equal(script[2:22], btcutil.Hash160(input.RedeemScript)) {
+			return nil, errors.E("redeem script doesn't match the P2SH output", errors.Params{"input": i})
+		}
+		script = input.RedeemScript
+	}
+
+	switch {
+	case txscript.IsPayToWitnessPubKeyHash(script), txscript.IsPayToWitnessScriptHash(script):
+		if !coin.SupportsSegwit() {
+			return nil, errors.E("segwit inputs are not supported for the coin", errors.Params{"coin": coin.Name, "input": i})
+		}
+		if txscript.IsPayToWitnessScriptHash(script) {
+			if input.WitnessScript == nil {
+				return nil, errors.E("missing witness script for P2WSH input", errors.Params{"input": i})
+			}
+			script = input.WitnessScript
+		}
+		sig, err := txscript.RawTxInWitnessSignature(pkt.UnsignedTx, sigHashes, i, txOut.Value, script, hashType, privk)
+		if err != nil {
+			return nil, errors.E(err, "error to sign the witness input", errors.Params{"input": i})
+		}
+		return sig, nil
+	case txscript.GetScriptClass(script) == txscript.PubKeyHashTy, input.RedeemScript != nil:
+		sig, err := txscript.RawTxInSignature(pkt.UnsignedTx, i, script, hashType, privk)
+		if err != nil {
+			return nil, errors.E(err, "error to sign the input", errors.Params{"input": i})
+		}
+		return sig, nil
+	default:
+		return nil, errors.E("unsupported PSBT input script", errors.Params{"input": i, "class": txscript.GetScriptClass(script).String()})
+	}
+}
+
+// psbtInputUtxo returns the output spent by the PSBT input
+func psbtInputUtxo(pkt *psbt.Packet, i int) (*wire.TxOut, error) {
+	input := pkt.Inputs[i]
+	if input.WitnessUtxo != nil {
+		return input.WitnessUtxo, nil
+	}
+	if input.NonWitnessUtxo != nil {
+		outPoint := pkt.UnsignedTx.TxIn[i].PreviousOutPoint
+		if input.NonWitnessUtxo.TxHash() != outPoint.Hash || int(outPoint.Index) >= len(input.NonWitnessUtxo.TxOut) {
+			return nil, errors.E("non witness utxo doesn't match the input outpoint", errors.Params{"input": i})
+		}
+		return input.NonWitnessUtxo.TxOut[outPoint.Index], nil
+	}
+	return nil, errors.E("missing utxo for PSBT input", errors.Params{"input": i})
+}
+
+// hasPartialSig returns true if the input is already signed by the public key
+func hasPartialSig(input psbt.PInput, pubk []byte) bool {
+	for _, sig := range input.PartialSigs {
+		if bytes.Equal(sig.PubKey, pubk) {
+			return true
+		}
+	}
+	return false
+}
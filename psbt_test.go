@@ -0,0 +1,219 @@
+package pool_party
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Pantani/pool-party/bip32"
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/psbt"
+	"github.com/stretchr/testify/assert"
+)
+
+type psbtTestInput struct {
+	pkScript     []byte
+	redeemScript []byte
+	witness      bool
+	index        uint32
+}
+
+// newTestPSBT creates a PSBT spending one output per input of a fake funding transaction
+func newTestPSBT(t *testing.T, coin bip44.Coin, fingerprint uint32, inputs []psbtTestInput, pubKeys [][]byte) (*psbt.Packet, *wire.MsgTx) {
+	prevTx := wire.NewMsgTx(wire.TxVersion)
+	prevTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{0x01}}, nil, nil))
+	for _, in := range inputs {
+		prevTx.AddTxOut(wire.NewTxOut(100000, in.pkScript))
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	for i := range inputs {
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, uint32(i)), nil, nil))
+		tx.TxIn[i].PreviousOutPoint.Hash = prevTx.TxHash()
+	}
+	tx.AddTxOut(wire.NewTxOut(90000*int64(len(inputs)), []byte{txscript.OP_TRUE}))
+
+	pkt, err := psbt.NewFromUnsignedTx(tx)
+	assert.NoError(t, err)
+	updater, err := psbt.NewUpdater(pkt)
+	assert.NoError(t, err)
+	coinType := uint32(bip44.CoinList[coin].CoinType)
+	for i, in := range inputs {
+		if in.witness {
+			assert.NoError(t, updater.AddInWitnessUtxo(prevTx.TxOut[i], i))
+		} else {
+			assert.NoError(t, updater.AddInNonWitnessUtxo(prevTx, i))
+		}
+		if in.redeemScript != nil {
+			assert.NoError(t, updater.AddInRedeemScript(in.redeemScript, i))
+		}
+		path := []uint32{44 + bip32.FirstHardenedChild, coinType + bip32.FirstHardenedChild, bip32.FirstHardenedChild, 0, in.index}
+		assert.NoError(t, updater.AddInBip32Derivation(fingerprint, path, pubKeys[i], i))
+	}
+	return pkt, prevTx
+}
+
+func TestPool_SignPSBT(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Bitcoin, testMnemonic, testPassphrase)
+	master, err := bip44.NewMasterKey(testMnemonic, testPassphrase)
+	assert.NoError(t, err)
+	fingerprint := bip44.Fingerprint(master)
+
+	pubKeys := make([][]byte, 3)
+	for i := range pubKeys {
+		key, err := master.DerivePath(bip32.DerivationPath{44 + bip32.FirstHardenedChild, bip32.FirstHardenedChild, bip32.FirstHardenedChild, 0, uint32(i)})
+		assert.NoError(t, err)
+		pubKeys[i] = key.PublicKey().Key
+	}
+	p2pkh, err := txscript.NewScriptBuilder().AddOp(txscript.OP_DUP).AddOp(txscript.OP_HASH160).
+		AddData(btcutil.Hash160(pubKeys[0])).AddOp(txscript.OP_EQUALVERIFY).AddOp(txscript.OP_CHECKSIG).Script()
+	assert.NoError(t, err)
+	p2wpkh, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(btcutil.Hash160(pubKeys[1])).Script()
+	assert.NoError(t, err)
+	redeemScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(btcutil.Hash160(pubKeys[2])).Script()
+	assert.NoError(t, err)
+	p2sh, err := txscript.NewScriptBuilder().AddOp(txscript.OP_HASH160).AddData(btcutil.Hash160(redeemScript)).AddOp(txscript.OP_EQUAL).Script()
+	assert.NoError(t, err)
+
+	inputs := []psbtTestInput{
+		{pkScript: p2pkh, index: 0},
+		{pkScript: p2wpkh, witness: true, index: 1},
+		{pkScript: p2sh, redeemScript: redeemScript, witness: true, index: 2},
+	}
+	pkt, prevTx := newTestPSBT(t, bip44.Bitcoin, fingerprint, inputs, pubKeys)
+	packet, err := pkt.B64Encode()
+	assert.NoError(t, err)
+
+	result, signed, err := pool.SignPSBT(packet)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, signed)
+
+	// signing twice doesn't duplicate the signatures
+	_, signed, err = pool.SignPSBT(result)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, signed)
+
+	signedPkt, err := psbt.NewFromRawBytes(bytes.NewReader([]byte(result)), true)
+	assert.NoError(t, err)
+	assert.NoError(t, psbt.MaybeFinalizeAll(signedPkt))
+	assert.True(t, signedPkt.IsComplete())
+	tx, err := psbt.Extract(signedPkt)
+	assert.NoError(t, err)
+
+	sigHashes := txscript.NewTxSigHashes(tx)
+	for i := range tx.TxIn {
+		prevOut := prevTx.TxOut[i]
+		vm, err := txscript.NewEngine(prevOut.PkScript, tx, i, txscript.StandardVerifyFlags, nil, sigHashes, prevOut.Value)
+		assert.NoError(t, err)
+		assert.NoError(t, vm.Execute(), "input %d", i)
+	}
+}
+
+func TestPool_SignPSBTPubKeyMismatch(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Bitcoin, testMnemonic, testPassphrase)
+	master, err := bip44.NewMasterKey(testMnemonic, testPassphrase)
+	assert.NoError(t, err)
+	fingerprint := bip44.Fingerprint(master)
+	key, err := master.DerivePath(bip32.DerivationPath{44 + bip32.FirstHardenedChild, bip32.FirstHardenedChild, bip32.FirstHardenedChild, 0, 1})
+	assert.NoError(t, err)
+	pubk := key.PublicKey().Key
+	foreign := append([]byte{0x02}, bytes.Repeat([]byte{0x01}, 32)...)
+
+	p2wpkh, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(btcutil.Hash160(pubk)).Script()
+	assert.NoError(t, err)
+	inputs := []psbtTestInput{
+		{pkScript: p2wpkh, witness: true, index: 0},
+		{pkScript: p2wpkh, witness: true, index: 1},
+	}
+	pkt, _ := newTestPSBT(t, bip44.Bitcoin, fingerprint, inputs, [][]byte{foreign, pubk})
+	packet, err := pkt.B64Encode()
+	assert.NoError(t, err)
+
+	result, signed, err := pool.SignPSBT(packet)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, signed)
+
+	signedPkt, err := psbt.NewFromRawBytes(bytes.NewReader([]byte(result)), true)
+	assert.NoError(t, err)
+	assert.Empty(t, signedPkt.Inputs[0].PartialSigs)
+	assert.Len(t, signedPkt.Inputs[1].PartialSigs, 1)
+}
+
+func TestPool_SignPSBTAccountKey(t *testing.T) {
+	master, err := bip44.NewMasterKey(testMnemonic, testPassphrase)
+	assert.NoError(t, err)
+	fingerprint := bip44.Fingerprint(master)
+	coinType := uint32(bip44.CoinList[bip44.Litecoin].CoinType) + bip32.FirstHardenedChild
+	account, err := master.DerivePath(bip32.DerivationPath{44 + bip32.FirstHardenedChild, coinType, bip32.FirstHardenedChild})
+	assert.NoError(t, err)
+	otherAccount, err := master.DerivePath(bip32.DerivationPath{44 + bip32.FirstHardenedChild, coinType, 1 + bip32.FirstHardenedChild})
+	assert.NoError(t, err)
+	key, err := account.DerivePath(bip32.DerivationPath{0, 0})
+	assert.NoError(t, err)
+	pubk := key.PublicKey().Key
+
+	p2wpkh, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(btcutil.Hash160(pubk)).Script()
+	assert.NoError(t, err)
+	pkt, _ := newTestPSBT(t, bip44.Litecoin, fingerprint, []psbtTestInput{{pkScript: p2wpkh, witness: true}}, [][]byte{pubk})
+	packet, err := pkt.B64Encode()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		key    *bip32.Key
+		signed int
+	}{
+		{name: "account key", key: account, signed: 1},
+		{name: "other account key", key: otherAccount, signed: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool, err := NewPoolWithExtendedKey(bip44.Litecoin, tt.key.B58Serialize())
+			assert.NoError(t, err)
+			_, signed, err := pool.SignPSBT(packet)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.signed, signed)
+		})
+	}
+}
+
+func TestPool_SignPSBTForeignFingerprint(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Litecoin, testMnemonic, testPassphrase)
+	p2wpkh, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(make([]byte, 20)).Script()
+	assert.NoError(t, err)
+	pubKey := append([]byte{0x02}, bytes.Repeat([]byte{0x01}, 32)...)
+
+	pkt, _ := newTestPSBT(t, bip44.Litecoin, 0xdeadbeef, []psbtTestInput{{pkScript: p2wpkh, witness: true}}, [][]byte{pubKey})
+	packet, err := pkt.B64Encode()
+	assert.NoError(t, err)
+
+	result, signed, err := pool.SignPSBT(packet)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, signed)
+	assert.Equal(t, packet, result)
+}
+
+func TestPool_SignPSBTSegwitNotSupported(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Dogecoin, testMnemonic, "")
+	master, err := bip44.NewMasterKey(testMnemonic, "")
+	assert.NoError(t, err)
+	fingerprint := bip44.Fingerprint(master)
+	key, err := master.DerivePath(bip32.DerivationPath{44 + bip32.FirstHardenedChild, 3 + bip32.FirstHardenedChild, bip32.FirstHardenedChild, 0, 0})
+	assert.NoError(t, err)
+	pubk := key.PublicKey().Key
+
+	p2wpkh, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(btcutil.Hash160(pubk)).Script()
+	assert.NoError(t, err)
+	pkt, _ := newTestPSBT(t, bip44.Dogecoin, fingerprint, []psbtTestInput{{pkScript: p2wpkh, witness: true}}, [][]byte{pubk})
+	packet, err := pkt.B64Encode()
+	assert.NoError(t, err)
+
+	_, _, err = pool.SignPSBT(packet)
+	assert.Error(t, err)
+
+	_, _, err = NewPoolWithSecret(bip44.Ethereum, testMnemonic, "").SignPSBT(packet)
+	assert.Error(t, err)
+}
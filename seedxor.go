@@ -0,0 +1,40 @@
+package pool_party
+
+import (
+	"github.com/Pantani/errors"
+	"github.com/Pantani/pool-party/bip39"
+	"github.com/Pantani/pool-party/bip44"
+)
+
+// SeedXOR splits the pool mnemonic in parts valid mnemonics (Coldcard Seed XOR), every part is
+// needed to combine them back. The random parts are read from the pool entropy source, and the
+// passphrase isn't part of the mnemonics.
+// It returns the mnemonics of the parts and an error if occurs
+func (p *Pool) SeedXOR(parts int) ([]string, error) {
+	if len(p.mnemonic) == 0 {
+		return nil, errors.E("pool has no mnemonic", errors.Params{"coin": p.coin})
+	}
+	split, err := bip39.SplitMnemonicXORBytes(p.entropySource(), p.mnemonic, parts)
+	if err != nil {
+		return nil, errors.E(err, "error to split the mnemonic", errors.Params{"parts": parts})
+	}
+	result := make([]string, 0, len(split))
+	for _, part := range split {
+		result = append(result, string(part))
+		bip39.Zero(part)
+	}
+	return result, nil
+}
+
+// NewPoolWithSeedXOR creates the pool with the mnemonic combined from the Seed XOR parts
+// It returns the pool and an error if occurs
+func NewPoolWithSeedXOR(coin bip44.Coin, passphrase string, parts ...string) (*Pool, error) {
+	if _, ok := bip44.CoinList[coin]; !ok {
+		return nil, errors.E("Invalid coin", errors.Params{"coin": coin})
+	}
+	mnemonic, err := bip39.CombineMnemonicXOR(parts)
+	if err != nil {
+		return nil, errors.E(err, "error to combine the seed xor parts", errors.Params{"parts": len(parts)})
+	}
+	return NewPoolWithSecret(coin, mnemonic, passphrase), nil
+}
@@ -0,0 +1,39 @@
+package pool_party
+
+import (
+	"testing"
+
+	"github.com/Pantani/pool-party/bip39"
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool_SeedXOR(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Bitcoin, testMnemonic, testPassphrase)
+	want, err := pool.GenerateAddressPool(0, 2)
+	assert.NoError(t, err)
+
+	parts, err := pool.SeedXOR(3)
+	assert.NoError(t, err)
+	assert.Len(t, parts, 3)
+	for _, part := range parts {
+		assert.True(t, bip39.IsMnemonicValid(part))
+	}
+
+	recovered, err := NewPoolWithSeedXOR(bip44.Bitcoin, testPassphrase, parts...)
+	assert.NoError(t, err)
+	got, err := recovered.GenerateAddressPool(0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	_, err = pool.SeedXOR(1)
+	assert.Error(t, err)
+	_, err = NewPoolWithSeedXOR(bip44.Bitcoin, testPassphrase, parts[0])
+	assert.Error(t, err)
+	_, err = NewPoolWithSeedXOR("Invalid", testPassphrase, parts...)
+	assert.Error(t, err)
+	seedPool, err := NewPoolWithSeed(bip44.Bitcoin, make([]byte, 32))
+	assert.NoError(t, err)
+	_, err = seedPool.SeedXOR(2)
+	assert.Error(t, err)
+}
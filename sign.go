@@ -0,0 +1,109 @@
+package pool_party
+
+import (
+	"github.com/Pantani/errors"
+	"github.com/Pantani/pool-party/bip39"
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// SignMessage signs the message with the key of the address index, using the
+// Bitcoin signmessage format or the EIP-191 personal_sign for Ethereum based coins
+// It returns the signature and an error if occurs
+func (p *Pool) SignMessage(index int, message []byte) (string, error) {
+	addr, err := p.address(index)
+	if err != nil {
+		return "", err
+	}
+	defer bip39.Zero(addr.Privkey)
+	return addr.SignMessage(p.coin, message)
+}
+
+// SignMessageWithPath is like SignMessage, but signs with the key of the address
+// derived by the path template, like the addresses of GenerateAddressPoolWithPath
+// It returns the signature and an error if occurs
+func (p *Pool) SignMessageWithPath(template string, account, index int, message []byte) (string, error) {
+	addr, err := p.addressWithPath(bip44.PathTemplate(template), account, index)
+	if err != nil {
+		return "", err
+	}
+	defer bip39.Zero(addr.Privkey)
+	return addr.SignMessage(p.coin, message)
+}
+
+// SignMessageWithScheme is like SignMessage, but signs with the key of the address derived
+// by the wallet derivation scheme, like the addresses of GenerateAddressPoolWithScheme
+// It returns the signature and an error if occurs
+func (p *Pool) SignMessageWithScheme(scheme bip44.Scheme, index int, message []byte) (string, error) {
+	addr, err := p.addressWithScheme(scheme, index)
+	if err != nil {
+		return "", err
+	}
+	defer bip39.Zero(addr.Privkey)
+	return addr.SignMessage(p.coin, message)
+}
+
+// SignTypedData signs the EIP-712 typed data with the key of the address index
+// It returns the signature and an error if occurs
+func (p *Pool) SignTypedData(index int, typedData apitypes.TypedData) (string, error) {
+	if err := p.checkEthereum("typed data signing"); err != nil {
+		return "", err
+	}
+	addr, err := p.address(index)
+	if err != nil {
+		return "", err
+	}
+	defer bip39.Zero(addr.Privkey)
+	return addr.SignTypedData(typedData)
+}
+
+// SignTypedDataWithPath is like SignTypedData, but signs with the key of the address
+// derived by the path template, like the addresses of GenerateAddressPoolWithPath
+// It returns the signature and an error if occurs
+func (p *Pool) SignTypedDataWithPath(template string, account, index int, typedData apitypes.TypedData) (string, error) {
+	if err := p.checkEthereum("typed data signing"); err != nil {
+		return "", err
+	}
+	addr, err := p.addressWithPath(bip44.PathTemplate(template), account, index)
+	if err != nil {
+		return "", err
+	}
+	defer bip39.Zero(addr.Privkey)
+	return addr.SignTypedData(typedData)
+}
+
+// SignTypedDataWithScheme is like SignTypedData, but signs with the key of the address derived
+// by the wallet derivation scheme (eg bip44.LedgerLive), like the addresses of GenerateAddressPoolWithScheme
+// It returns the signature and an error if occurs
+func (p *Pool) SignTypedDataWithScheme(scheme bip44.Scheme, index int, typedData apitypes.TypedData) (string, error) {
+	if err := p.checkEthereum("typed data signing"); err != nil {
+		return "", err
+	}
+	addr, err := p.addressWithScheme(scheme, index)
+	if err != nil {
+		return "", err
+	}
+	defer bip39.Zero(addr.Privkey)
+	return addr.SignTypedData(typedData)
+}
+
+// VerifyMessage verifies if the message signed by SignMessage was signed by the address
+// It returns true if the recovered signer matches the address and an error if occurs
+func (p *Pool) VerifyMessage(address string, message []byte, signature string) (bool, error) {
+	return bip44.VerifyMessage(p.coin, address, message, signature)
+}
+
+// VerifyTypedData verifies if the typed data signed by SignTypedData was signed by the address
+// It returns true if the recovered signer matches the address and an error if occurs
+func (p *Pool) VerifyTypedData(address string, typedData apitypes.TypedData, signature string) (bool, error) {
+	return bip44.VerifyTypedData(address, typedData, signature)
+}
+
+// checkEthereum checks if the pool coin is an Ethereum based coin
+// It returns an error if the operation is not supported by the coin
+func (p *Pool) checkEthereum(operation string) error {
+	if !bip44.CoinList[p.coin].IsEthereum() {
+		return errors.E(operation+" is only supported for Ethereum based coins", errors.Params{"coin": p.coin})
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package pool_party
+
+import (
+	"testing"
+
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool_SignMessage(t *testing.T) {
+	for _, coin := range []bip44.Coin{bip44.Bitcoin, bip44.Ethereum} {
+		pool := NewPoolWithSecret(coin, testMnemonic, testPassphrase)
+		addresses, err := pool.GenerateAddressPool(3, 1)
+		assert.NoError(t, err)
+
+		sig, err := pool.SignMessage(3, []byte("deposit address ownership"))
+		assert.NoError(t, err)
+
+		ok, err := pool.VerifyMessage(addresses[0].Address, []byte("deposit address ownership"), sig)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+}
+
+func TestPool_SignTypedDataNotEthereum(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Litecoin, testMnemonic, testPassphrase)
+	_, err := pool.SignTypedData(0, apitypes.TypedData{})
+	assert.Error(t, err)
+}
+
+func TestPool_SignMessageElectrumSegwit(t *testing.T) {
+	pool, err := NewPoolWithElectrumSeed(bip44.Bitcoin, "bitter grass shiver impose acquire brush forget axis eager alone wine silver", "")
+	assert.NoError(t, err)
+	addresses, err := pool.GenerateAddressPool(0, 2)
+	assert.NoError(t, err)
+
+	sig, err := pool.SignMessage(0, []byte("deposit address ownership"))
+	assert.NoError(t, err)
+
+	ok, err := pool.VerifyMessage(addresses[0].Address, []byte("deposit address ownership"), sig)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = pool.VerifyMessage(addresses[1].Address, []byte("deposit address ownership"), sig)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestPool_SignMessageWithScheme(t *testing.T) {
+	testTypedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {{Name: "name", Type: "string"}, {Name: "chainId", Type: "uint256"}},
+			"Mail":         {{Name: "contents", Type: "string"}},
+		},
+		PrimaryType: "Mail",
+		Domain:      apitypes.TypedDataDomain{Name: "Ether Mail", ChainId: math.NewHexOrDecimal256(1)},
+		Message:     apitypes.TypedDataMessage{"contents": "Hello, Bob!"},
+	}
+	pool := NewPoolWithSecret(bip44.Ethereum, testMnemonic, testPassphrase)
+	for _, scheme := range []bip44.Scheme{bip44.LedgerLive, bip44.LegacyMEW} {
+		addresses, err := pool.GenerateAddressPoolWithScheme(scheme, 2, 1)
+		assert.NoError(t, err)
+
+		sig, err := pool.SignMessageWithScheme(scheme, 2, []byte("deposit address ownership"))
+		assert.NoError(t, err)
+		ok, err := pool.VerifyMessage(addresses[0].Address, []byte("deposit address ownership"), sig)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		sig, err = pool.SignTypedDataWithScheme(scheme, 2, testTypedData)
+		assert.NoError(t, err)
+		ok, err = pool.VerifyTypedData(addresses[0].Address, testTypedData, sig)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+
+	btc := NewPoolWithSecret(bip44.Bitcoin, testMnemonic, testPassphrase)
+	addresses, err := btc.GenerateAddressPoolWithPath("m/44'/0'/{account}'/1/{index}", 1, 4, 1)
+	assert.NoError(t, err)
+	sig, err := btc.SignMessageWithPath("m/44'/0'/{account}'/1/{index}", 1, 4, []byte("change address ownership"))
+	assert.NoError(t, err)
+	ok, err := btc.VerifyMessage(addresses[0].Address, []byte("change address ownership"), sig)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = btc.SignMessageWithScheme(bip44.LedgerLive, 0, []byte("message"))
+	assert.Error(t, err)
+	_, err = btc.SignTypedDataWithPath("m/44'/0'/{account}'/0/{index}", 0, 0, apitypes.TypedData{})
+	assert.Error(t, err)
+}
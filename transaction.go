@@ -0,0 +1,55 @@
+package pool_party
+
+import (
+	"math/big"
+
+	"github.com/Pantani/pool-party/bip39"
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// SignEthereumTx signs the transaction with the key of the address index.
+// The private key is derived internally and never leaves the package.
+// Legacy, access list (EIP-2930) and dynamic fee (EIP-1559) transactions are supported.
+// It returns the signed transaction and an error if occurs
+func (p *Pool) SignEthereumTx(index int, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if err := p.checkEthereum("transaction signing"); err != nil {
+		return nil, err
+	}
+	addr, err := p.address(index)
+	if err != nil {
+		return nil, err
+	}
+	defer bip39.Zero(addr.Privkey)
+	return addr.SignEthereumTx(tx, chainID)
+}
+
+// SignEthereumTxWithPath is like SignEthereumTx, but signs with the key of the address
+// derived by the path template, like the addresses of GenerateAddressPoolWithPath
+// It returns the signed transaction and an error if occurs
+func (p *Pool) SignEthereumTxWithPath(template string, account, index int, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if err := p.checkEthereum("transaction signing"); err != nil {
+		return nil, err
+	}
+	addr, err := p.addressWithPath(bip44.PathTemplate(template), account, index)
+	if err != nil {
+		return nil, err
+	}
+	defer bip39.Zero(addr.Privkey)
+	return addr.SignEthereumTx(tx, chainID)
+}
+
+// SignEthereumTxWithScheme is like SignEthereumTx, but signs with the key of the address derived
+// by the wallet derivation scheme (eg bip44.LedgerLive), like the addresses of GenerateAddressPoolWithScheme
+// It returns the signed transaction and an error if occurs
+func (p *Pool) SignEthereumTxWithScheme(scheme bip44.Scheme, index int, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if err := p.checkEthereum("transaction signing"); err != nil {
+		return nil, err
+	}
+	addr, err := p.addressWithScheme(scheme, index)
+	if err != nil {
+		return nil, err
+	}
+	defer bip39.Zero(addr.Privkey)
+	return addr.SignEthereumTx(tx, chainID)
+}
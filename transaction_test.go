@@ -0,0 +1,61 @@
+package pool_party
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Pantani/pool-party/bip44"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool_SignEthereumTx(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Energi, testMnemonic, testPassphrase)
+	addresses, err := pool.GenerateAddressPool(7, 1)
+	assert.NoError(t, err)
+
+	chainID := big.NewInt(39797)
+	to := common.HexToAddress("0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB")
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: chainID, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(2e9), Gas: 21000, To: &to, Value: big.NewInt(1)})
+
+	signed, err := pool.SignEthereumTx(7, tx, chainID)
+	assert.NoError(t, err)
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	assert.NoError(t, err)
+	assert.Equal(t, common.HexToAddress(addresses[0].Address), sender)
+
+	_, err = NewPoolWithSecret(bip44.Dash, testMnemonic, "").SignEthereumTx(7, tx, chainID)
+	assert.Error(t, err)
+}
+
+func TestPool_SignEthereumTxWithScheme(t *testing.T) {
+	pool := NewPoolWithSecret(bip44.Ethereum, testMnemonic, testPassphrase)
+	chainID := big.NewInt(1)
+	to := common.HexToAddress("0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB")
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: chainID, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(2e9), Gas: 21000, To: &to, Value: big.NewInt(1)})
+	signer := types.LatestSignerForChainID(chainID)
+
+	for _, scheme := range []bip44.Scheme{bip44.LedgerLive, bip44.LegacyMEW} {
+		addresses, err := pool.GenerateAddressPoolWithScheme(scheme, 3, 1)
+		assert.NoError(t, err)
+		signed, err := pool.SignEthereumTxWithScheme(scheme, 3, tx, chainID)
+		assert.NoError(t, err)
+		sender, err := types.Sender(signer, signed)
+		assert.NoError(t, err)
+		assert.Equal(t, common.HexToAddress(addresses[0].Address), sender)
+	}
+
+	addresses, err := pool.GenerateAddressPoolWithPath("m/44'/60'/{account}'/0/{index}", 2, 5, 1)
+	assert.NoError(t, err)
+	signed, err := pool.SignEthereumTxWithPath("m/44'/60'/{account}'/0/{index}", 2, 5, tx, chainID)
+	assert.NoError(t, err)
+	sender, err := types.Sender(signer, signed)
+	assert.NoError(t, err)
+	assert.Equal(t, common.HexToAddress(addresses[0].Address), sender)
+
+	_, err = pool.SignEthereumTxWithScheme("Unknown", 3, tx, chainID)
+	assert.Error(t, err)
+	_, err = NewPoolWithSecret(bip44.Bitcoin, testMnemonic, "").SignEthereumTxWithScheme(bip44.LedgerLive, 3, tx, chainID)
+	assert.Error(t, err)
+}